@@ -0,0 +1,145 @@
+package distrlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrLockNotAcquired is returned by Do in try mode if the partition is already locked
+var ErrLockNotAcquired = errors.New("lock not acquired")
+
+// ErrInvalidKeepAliveInterval is returned by Do if the keep alive interval is not positive
+var ErrInvalidKeepAliveInterval = errors.New("keep alive interval should be positive")
+
+type DoOptions struct {
+	// TryLock if true, Do returns ErrLockNotAcquired instead of waiting if the partition is already locked
+	TryLock bool
+
+	// KeepAliveInterval time between two lock refreshes while the function is running
+	KeepAliveInterval *time.Duration
+}
+
+// WithTryLock Specifies that Do should not wait if the partition is already locked but return ErrLockNotAcquired
+func WithTryLock() func(options *DoOptions) {
+	return func(options *DoOptions) {
+		options.TryLock = true
+	}
+}
+
+// WithKeepAliveInterval Specifies the time between two lock refreshes while the function is running.
+// Default value is a third of the lock timeout. Do returns ErrInvalidKeepAliveInterval if the interval is not positive.
+func WithKeepAliveInterval(keepAliveInterval time.Duration) func(options *DoOptions) {
+	return func(options *DoOptions) {
+		options.KeepAliveInterval = &keepAliveInterval
+	}
+}
+
+// Do locks the partition and runs fn while the lock is held.
+// The lock is refreshed in the background. If the lock is lost, the context passed to fn is cancelled and the cause of the loss is returned.
+// The lock is released once fn returns or panics. Errors of fn, the loss of the lock and the release are combined.
+func (h *RepositoryLockHandler) Do(ctx context.Context, partition types.AttributeValue, fn func(ctx context.Context, lock *Lock) error, optFns ...func(options *DoOptions)) (err error) {
+	options := DoOptions{}
+	for _, optFn := range optFns {
+		optFn(&options)
+	}
+
+	keepAliveInterval := h.Timeout / 3
+	if options.KeepAliveInterval != nil {
+		keepAliveInterval = *options.KeepAliveInterval
+	}
+
+	if keepAliveInterval <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidKeepAliveInterval, keepAliveInterval)
+	}
+
+	lock, err := h.acquire(ctx, partition, options.TryLock)
+	if err != nil {
+		return err
+	}
+
+	lockCtx, cancelFn := context.WithCancel(ctx)
+
+	var lostErr error
+
+	keepAliveDone := make(chan struct{})
+
+	go func() {
+		defer close(keepAliveDone)
+
+		lostErr = keepAlive(lockCtx, lock, keepAliveInterval, h.Timeout)
+		if lostErr != nil {
+			cancelFn()
+		}
+	}()
+
+	defer func() {
+		cancelFn()
+		<-keepAliveDone
+
+		releaseErr := lock.Release(context.WithoutCancel(ctx))
+		if lostErr != nil && errors.Is(releaseErr, ErrLockTakenOver) {
+			// Already reported by the loss of the lock
+			releaseErr = nil
+		}
+
+		if r := recover(); r != nil {
+			panic(r)
+		}
+
+		err = errors.Join(err, lostErr, releaseErr)
+	}()
+
+	return fn(lockCtx, lock)
+}
+
+func (h *RepositoryLockHandler) acquire(ctx context.Context, partition types.AttributeValue, tryLock bool) (*Lock, error) {
+	if !tryLock {
+		return h.Lock(ctx, partition)
+	}
+
+	lock, success, err := h.TryLock(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	if !success {
+		return nil, ErrLockNotAcquired
+	}
+
+	return lock, nil
+}
+
+// keepAlive refreshes the lock every interval until the context is Done. Nil is returned if the context is Done.
+// If the lock is lost, the refresh error is returned. Refresh errors other than ErrLockUpdate are retried until the lease expires.
+func keepAlive(ctx context.Context, lock *Lock, interval time.Duration, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastRenewal := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := lock.Refresh(ctx)
+			if err == nil {
+				lastRenewal = time.Now()
+
+				continue
+			}
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if errors.Is(err, ErrLockUpdate) || time.Since(lastRenewal) >= timeout {
+				return err
+			}
+		}
+	}
+}
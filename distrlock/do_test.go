@@ -0,0 +1,284 @@
+package distrlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func TestLock_Do_Success(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	dynamodbClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Millisecond*30), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	err := handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+		require.Equal(t, "UniqueID", lock.LockId())
+
+		// Wait for some refreshes
+		time.Sleep(time.Millisecond * 40)
+
+		return ctx.Err()
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(dynamodbClient.Calls), 3)
+}
+
+func TestLock_Do_TryLock_NotAcquired(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(ctx, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	called := false
+
+	// When
+	err := handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+		called = true
+
+		return nil
+	}, WithTryLock())
+
+	// Then
+	require.ErrorIs(t, err, ErrLockNotAcquired)
+	require.False(t, called)
+}
+
+func TestLock_Do_InvalidKeepAliveInterval(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	called := false
+
+	// When
+	err := handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+		called = true
+
+		return nil
+	}, WithKeepAliveInterval(0))
+
+	// Then
+	require.ErrorIs(t, err, ErrInvalidKeepAliveInterval)
+	require.False(t, called)
+}
+
+func TestLock_Do_LockLost(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	dynamodbClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		"pkName":            pk,
+		attributeNameLockId: &types.AttributeValueMemberS{Value: "OtherLock"},
+	}}).Once()
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		"pkName":            pk,
+		attributeNameLockId: &types.AttributeValueMemberS{Value: "OtherLock"},
+	}}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	fnErr := errors.New("work cancelled")
+
+	// When
+	err := handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+		<-ctx.Done()
+
+		return fnErr
+	}, WithKeepAliveInterval(time.Millisecond*10))
+
+	// Then
+	require.ErrorIs(t, err, fnErr)
+	require.ErrorIs(t, err, ErrLockTakenOver)
+}
+
+func TestLock_Do_ReleaseOnPanic(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(ctx, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	// When
+	doFn := func() {
+		_ = handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+			panic("boom")
+		})
+	}
+
+	// Then
+	require.PanicsWithValue(t, "boom", doFn)
+}
+
+// lockStore simulates the lock item of a single partition, so conditions are evaluated against the lockId of the latest refresh
+type lockStore struct {
+	mutex  sync.Mutex
+	lockId string
+}
+
+func (s *lockStore) expect(t *testing.T, dynamodbClient *mocks.DynamodbClient) {
+	t.Helper()
+
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.lockId = input.Item[attributeNameLockId].(*types.AttributeValueMemberS).Value
+
+		return &dynamodb.PutItemOutput{}, nil
+	}).Once()
+
+	dynamodbClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if input.ExpressionAttributeValues[":lockId"].(*types.AttributeValueMemberS).Value != s.lockId {
+			return nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{attributeNameLockId: &types.AttributeValueMemberS{Value: s.lockId}}}
+		}
+
+		s.lockId = input.ExpressionAttributeValues[":a0"].(*types.AttributeValueMemberS).Value
+
+		return &dynamodb.UpdateItemOutput{}, nil
+	})
+
+	dynamodbClient.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		for _, item := range input.TransactItems {
+			values := map[string]types.AttributeValue{}
+			if item.ConditionCheck != nil {
+				values = item.ConditionCheck.ExpressionAttributeValues
+			} else if item.Update != nil {
+				values = item.Update.ExpressionAttributeValues
+			}
+
+			if values[":lockId"].(*types.AttributeValueMemberS).Value != s.lockId {
+				return nil, &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}}}
+			}
+
+			if newLockId, found := values[":newLockId"]; found {
+				s.lockId = newLockId.(*types.AttributeValueMemberS).Value
+			}
+		}
+
+		return &dynamodb.TransactWriteItemsOutput{}, nil
+	})
+
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+}
+
+func incrementingIdGenerator(t *testing.T) func(options *Options) {
+	t.Helper()
+
+	var counter atomic.Int64
+
+	idGenerator := mocks.NewIdGenerator(t)
+	idGenerator.EXPECT().ID().RunAndReturn(func() string {
+		return fmt.Sprintf("Lock%d", counter.Add(1))
+	})
+
+	return func(options *Options) {
+		options.IdGenerator = idGenerator
+	}
+}
+
+func TestLock_Do_TransactionConditionAcrossKeepAlive(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	(&lockStore{}).expect(t, dynamodbClient)
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Second), incrementingIdGenerator(t))
+
+	// When
+	err := handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+		return lock.WithTransactionCondition(func(condition types.TransactWriteItem) error {
+			// The keep-alive ticks while the write is prepared
+			time.Sleep(time.Millisecond * 30)
+
+			_, writeErr := dynamodbClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{condition}})
+
+			return TransactionLockError(writeErr, 0)
+		})
+	}, WithKeepAliveInterval(time.Millisecond*5))
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestLock_Do_TransactionWithRefreshAcrossKeepAlive(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	(&lockStore{}).expect(t, dynamodbClient)
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Second), incrementingIdGenerator(t))
+
+	// When
+	err := handler.Do(ctx, pk, func(ctx context.Context, lock *Lock) error {
+		for i := 0; i < 3; i++ {
+			lockItem, callback := lock.TransactionWithRefresh()
+
+			// The keep-alive ticks while the write is prepared
+			time.Sleep(time.Millisecond * 15)
+
+			_, writeErr := callback(dynamodbClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{lockItem}}))
+			if writeErr = TransactionLockError(writeErr, 0); writeErr != nil {
+				return writeErr
+			}
+		}
+
+		return nil
+	}, WithKeepAliveInterval(time.Millisecond*5))
+
+	// Then
+	require.NoError(t, err)
+}
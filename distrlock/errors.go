@@ -1,10 +1,25 @@
 package distrlock
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
 
 var ErrTimeout = errors.New("timeout")
 var ErrLockUpdate = errors.New("lock update error")
 
+// ErrTableConfiguration is returned if the lock table does not match the configuration of the RepositoryLockHandler
+var ErrTableConfiguration = errors.New("invalid lock table configuration")
+
+// ErrLockNotHeld is returned if the lock does not exist anymore, e.g. because it was already released. Wraps ErrLockUpdate.
+var ErrLockNotHeld = fmt.Errorf("%w: lock not held", ErrLockUpdate)
+
+// ErrLockTakenOver is returned if the lock is held by another holder, e.g. because it was not refreshed within its timeout. Wraps ErrLockUpdate.
+var ErrLockTakenOver = fmt.Errorf("%w: lock taken over", ErrLockUpdate)
+
 type ErrDistrLock struct {
 	Msg string
 	Err error
@@ -24,3 +39,21 @@ func (e *ErrDistrLock) Error() string {
 func (e *ErrDistrLock) Unwrap() error {
 	return e.Err
 }
+
+// TransactionLockError decodes the error of a TransactWriteItems call that contains lock conditions at the given item indexes.
+// If the transaction was cancelled because one of the lock conditions failed, an error wrapping ErrLockTakenOver and err is returned.
+// Otherwise, err is returned unchanged.
+func TransactionLockError(err error, lockIndexes ...int) error {
+	var transactionCanceledException *types.TransactionCanceledException
+	if !errors.As(err, &transactionCanceledException) {
+		return err
+	}
+
+	for _, i := range lockIndexes {
+		if i < len(transactionCanceledException.CancellationReasons) && aws.ToString(transactionCanceledException.CancellationReasons[i].Code) == cancellationReasonConditionalCheckFailed {
+			return fmt.Errorf("%w: %w", ErrLockTakenOver, err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,56 @@
+package distrlock
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionLockError(t *testing.T) {
+	cancelled := func(codes ...string) error {
+		reasons := make([]types.CancellationReason, 0, len(codes))
+		for _, code := range codes {
+			reasons = append(reasons, types.CancellationReason{Code: aws.String(code)})
+		}
+
+		return fmt.Errorf("operation error: %w", &types.TransactionCanceledException{CancellationReasons: reasons})
+	}
+
+	t.Run("lock condition failed", func(t *testing.T) {
+		// When
+		err := TransactionLockError(cancelled("None", "ConditionalCheckFailed"), 1)
+
+		// Then
+		require.ErrorIs(t, err, ErrLockTakenOver)
+
+		var transactionCanceledException *types.TransactionCanceledException
+		require.ErrorAs(t, err, &transactionCanceledException)
+	})
+
+	t.Run("other condition failed", func(t *testing.T) {
+		// Given
+		original := cancelled("ConditionalCheckFailed", "None")
+
+		// When
+		err := TransactionLockError(original, 1)
+
+		// Then
+		require.Equal(t, original, err)
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		// Given
+		original := errors.New("boom")
+
+		// When
+		err := TransactionLockError(original, 0)
+
+		// Then
+		require.Equal(t, original, err)
+		require.NoError(t, TransactionLockError(nil, 0))
+	})
+}
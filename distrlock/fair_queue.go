@@ -0,0 +1,316 @@
+package distrlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const ticketPrefix = "##TICKET##"
+const ticketCounterSortKey = "##TICKET_COUNTER##"
+const attributeNameTicketExpiresAt = "ticketExpiresAt"
+const attributeNameTicketCounter = "ticketCounter"
+
+// ticket represents the position of a waiter in the queue of a partition
+type ticket struct {
+	sortKey   string
+	expiresAt time.Time
+}
+
+func (h *RepositoryLockHandler) fairLock(ctx context.Context, partition types.AttributeValue) (*Lock, error) {
+	err := h.validateFairQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := h.enqueueTicket(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		// Best effort: an abandoned ticket will expire after the ticket timeout
+		_ = h.removeTicket(context.WithoutCancel(ctx), partition, t)
+	}()
+
+	attempt := newLockAttempt(h, partition)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, attempt.waiter.timeout()
+		default:
+			isHead, found, headErr := h.isQueueHead(ctx, partition, t)
+			if headErr != nil {
+				return nil, headErr
+			}
+
+			if isHead {
+				lock, lockErr := attempt.try(ctx)
+				if lockErr != nil {
+					return nil, lockErr
+				}
+
+				if lock != nil {
+					return lock, nil
+				}
+			}
+
+			if found {
+				found, err = h.refreshTicket(ctx, partition, t)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if !found {
+				// Our ticket expired and was removed by another waiter
+				t, err = h.enqueueTicket(ctx, partition)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			attempt.waiter.wait(ctx)
+		}
+	}
+}
+
+func (h *RepositoryLockHandler) fairTryLock(ctx context.Context, partition types.AttributeValue) (*Lock, bool, error) {
+	err := h.validateFairQueue()
+	if err != nil {
+		return nil, false, err
+	}
+
+	isHead, _, err := h.isQueueHead(ctx, partition, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !isHead {
+		return nil, false, nil
+	}
+
+	return h.lock(ctx, partition, "", nil)
+}
+
+func (h *RepositoryLockHandler) validateFairQueue() error {
+	if !h.hasSortKey() {
+		return NewDistrLockError("fair queueing requires a table with a sort key", nil)
+	}
+
+	if _, ok := h.SortKeyValue.(*types.AttributeValueMemberS); !ok {
+		return NewDistrLockError(fmt.Sprintf("fair queueing requires a sort key of type AttributeValueMemberS but was %T", h.SortKeyValue), nil)
+	}
+
+	return nil
+}
+
+// enqueueTicket adds a ticket at the end of the queue.
+// The position of the ticket is taken from an atomic counter in the partition, so the arrival order does not depend on the clocks of the waiters.
+func (h *RepositoryLockHandler) enqueueTicket(ctx context.Context, partition types.AttributeValue) (*ticket, error) {
+	position, err := h.nextTicketPosition(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ticket{
+		sortKey:   fmt.Sprintf("%s%020d#%s", ticketPrefix, position, h.IdGenerator.ID()),
+		expiresAt: time.Now().Add(h.TicketTimeout),
+	}
+
+	_, err = h.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                &h.TableName,
+		Item:                     h.ticketItem(partition, t),
+		ConditionExpression:      aws.String("attribute_not_exists(#SK)"),
+		ExpressionAttributeNames: map[string]string{"#SK": *h.SortKeyName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// nextTicketPosition increments the ticket counter of the partition and returns the new value
+func (h *RepositoryLockHandler) nextTicketPosition(ctx context.Context, partition types.AttributeValue) (int64, error) {
+	output, err := h.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &h.TableName,
+		Key:                       h.ticketKey(partition, ticketCounterSortKey),
+		UpdateExpression:          aws.String("ADD #Counter :one"),
+		ExpressionAttributeNames:  map[string]string{"#Counter": attributeNameTicketCounter},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return parseNumberAttribute(output.Attributes, attributeNameTicketCounter)
+}
+
+// refreshTicket extends the expiration of a ticket if more than half of the ticket timeout has passed.
+// The ticket is only updated if it still exists. False is returned if the ticket was removed, so it should be enqueued again.
+func (h *RepositoryLockHandler) refreshTicket(ctx context.Context, partition types.AttributeValue, t *ticket) (bool, error) {
+	if time.Until(t.expiresAt) > h.TicketTimeout/2 {
+		return true, nil
+	}
+
+	expiresAt := time.Now().Add(h.TicketTimeout)
+
+	attributes := map[string]types.AttributeValue{attributeNameTicketExpiresAt: &types.AttributeValueMemberS{Value: expiresAt.UTC().Format(time.RFC3339Nano)}}
+	h.expirationAttribute(attributes)
+
+	expressionAttributeNames := map[string]string{"#SK": *h.SortKeyName}
+	expressionAttributeValues := map[string]types.AttributeValue{}
+
+	_, err := h.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &h.TableName,
+		Key:                       h.ticketKey(partition, t.sortKey),
+		UpdateExpression:          aws.String("SET " + strings.Join(setOperations(attributes, expressionAttributeNames, expressionAttributeValues), ", ")),
+		ConditionExpression:       aws.String("attribute_exists(#SK)"),
+		ExpressionAttributeNames:  expressionAttributeNames,
+		ExpressionAttributeValues: expressionAttributeValues,
+	})
+	if err != nil {
+		var conditionalCheckFailedException *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailedException) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	t.expiresAt = expiresAt
+
+	return true, nil
+}
+
+func (h *RepositoryLockHandler) removeTicket(ctx context.Context, partition types.AttributeValue, t *ticket) error {
+	_, err := h.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: &h.TableName,
+		Key:       h.ticketKey(partition, t.sortKey),
+	})
+
+	return err
+}
+
+// isQueueHead checks if no other active ticket is queued before the given ticket. Abandoned tickets are removed from the queue.
+// If t is nil, isQueueHead checks if no active ticket is queued. The second return value is false if t is not found in the queue.
+func (h *RepositoryLockHandler) isQueueHead(ctx context.Context, partition types.AttributeValue, t *ticket) (bool, bool, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                 &h.TableName,
+		KeyConditionExpression:    aws.String("#PK = :pk AND begins_with(#SK, :ticketPrefix)"),
+		ExpressionAttributeNames:  map[string]string{"#PK": h.PartitionKeyName, "#SK": *h.SortKeyName},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": partition, ":ticketPrefix": &types.AttributeValueMemberS{Value: ticketPrefix}},
+		ConsistentRead:            aws.Bool(true),
+	}
+
+	// The queue is read until our own ticket, so a removed ticket is detected even if an earlier ticket is still active
+	queuedBefore := false
+
+	for {
+		output, err := h.Client.Query(ctx, input)
+		if err != nil {
+			return false, false, err
+		}
+
+		for _, item := range output.Items {
+			sortKey, ok := item[*h.SortKeyName].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+
+			if t != nil && sortKey.Value == t.sortKey {
+				return !queuedBefore, true, nil
+			}
+
+			if t != nil && sortKey.Value > t.sortKey {
+				return false, false, nil
+			}
+
+			if queuedBefore {
+				continue
+			}
+
+			expiresAt, parseErr := parseTimeAttribute(item, attributeNameTicketExpiresAt)
+			if parseErr != nil {
+				return false, false, parseErr
+			}
+
+			if h.ticketAbandoned(expiresAt) {
+				err = h.removeExpiredTicket(ctx, partition, sortKey.Value, item[attributeNameTicketExpiresAt])
+				if err != nil {
+					return false, false, err
+				}
+
+				continue
+			}
+
+			if t == nil {
+				return false, true, nil
+			}
+
+			queuedBefore = true
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return t == nil, t == nil, nil
+		}
+
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// ticketAbandoned checks if a ticket is no longer refreshed by its waiter.
+// The expiration of a ticket is set with the clock of its waiter. To tolerate clock skew between waiters, a ticket is only considered abandoned once it expired for more than the ticket timeout.
+func (h *RepositoryLockHandler) ticketAbandoned(expiresAt *time.Time) bool {
+	return expiresAt == nil || expiresAt.Add(h.TicketTimeout).Before(time.Now())
+}
+
+func (h *RepositoryLockHandler) removeExpiredTicket(ctx context.Context, partition types.AttributeValue, sortKey string, expiresAt types.AttributeValue) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: &h.TableName,
+		Key:       h.ticketKey(partition, sortKey),
+	}
+
+	if expiresAt != nil {
+		// Only remove the ticket if it was not refreshed in the meantime
+		input.ConditionExpression = aws.String("#ExpiresAt = :expiresAt")
+		input.ExpressionAttributeNames = map[string]string{"#ExpiresAt": attributeNameTicketExpiresAt}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{":expiresAt": expiresAt}
+	}
+
+	_, err := h.Client.DeleteItem(ctx, input)
+	if err != nil {
+		var conditionalCheckFailedException *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailedException) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (h *RepositoryLockHandler) ticketKey(partition types.AttributeValue, sortKey string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		h.PartitionKeyName: partition,
+		*h.SortKeyName:     &types.AttributeValueMemberS{Value: sortKey},
+	}
+}
+
+func (h *RepositoryLockHandler) ticketItem(partition types.AttributeValue, t *ticket) map[string]types.AttributeValue {
+	item := h.ticketKey(partition, t.sortKey)
+	item[attributeNameTicketExpiresAt] = &types.AttributeValueMemberS{Value: t.expiresAt.UTC().Format(time.RFC3339Nano)}
+	h.expirationAttribute(item)
+
+	return item
+}
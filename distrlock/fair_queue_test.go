@@ -0,0 +1,329 @@
+package distrlock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func isTicketPut(input *dynamodb.PutItemInput) bool {
+	sk, ok := input.Item["SK"].(*types.AttributeValueMemberS)
+
+	return ok && strings.HasPrefix(sk.Value, ticketPrefix)
+}
+
+func isLockPut(input *dynamodb.PutItemInput) bool {
+	return !isTicketPut(input)
+}
+
+func ticketQueryItem(pk types.AttributeValue, sortKey string, expiresAt time.Time) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK":                         pk,
+		"SK":                         &types.AttributeValueMemberS{Value: sortKey},
+		attributeNameTicketExpiresAt: &types.AttributeValueMemberS{Value: expiresAt.UTC().Format(time.RFC3339Nano)},
+	}
+}
+
+func expectTicketCounter(dynamodbClient *mocks.DynamodbClient, ctx context.Context, tableName string, pk types.AttributeValue, position string) {
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &tableName,
+		Key:                       map[string]types.AttributeValue{"PK": pk, "SK": &types.AttributeValueMemberS{Value: ticketCounterSortKey}},
+		UpdateExpression:          aws.String("ADD #Counter :one"),
+		ExpressionAttributeNames:  map[string]string{"#Counter": attributeNameTicketCounter},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	}).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]types.AttributeValue{attributeNameTicketCounter: &types.AttributeValueMemberN{Value: position}}}, nil).Once()
+}
+
+func TestLock_Lock_FairQueue_InArrivalOrder(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+	otherTicket := ticketPrefix + "00000000000000000001#other"
+
+	var ownTicket map[string]types.AttributeValue
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	expectTicketCounter(dynamodbClient, ctx, tableName, pk, "2")
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isTicketPut)).Run(func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) {
+		ownTicket = params.Item
+	}).Return(nil, nil)
+
+	queryCalls := 0
+	dynamodbClient.EXPECT().Query(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+		queryCalls++
+
+		require.Equal(t, aws.String("#PK = :pk AND begins_with(#SK, :ticketPrefix)"), input.KeyConditionExpression)
+
+		own := ticketQueryItem(pk, ownTicket["SK"].(*types.AttributeValueMemberS).Value, time.Now().Add(time.Minute))
+
+		if queryCalls == 1 {
+			return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{ticketQueryItem(pk, otherTicket, time.Now().Add(time.Minute)), own}}, nil
+		}
+
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{own}}, nil
+	}).Times(2)
+
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isLockPut)).Return(nil, nil).Once()
+
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return input.Key["SK"].(*types.AttributeValueMemberS).Value == ownTicket["SK"].(*types.AttributeValueMemberS).Value
+	})).Return(nil, nil).Once()
+
+	handler := New(dynamodbClient, tableName, "PK", WithSortKey("SK"), WithFairQueue(time.Minute),
+		WithRefreshInterval(time.Millisecond*10), WithRefreshVariance(0), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	require.Equal(t, "UniqueID", lock.LockId())
+	require.Equal(t, 2, queryCalls)
+	require.Equal(t, &types.AttributeValueMemberS{Value: ticketPrefix + "00000000000000000002#UniqueID"}, ownTicket["SK"])
+}
+
+func TestLock_Lock_FairQueue_RemoveExpiredTickets(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+	expiredTicket := ticketQueryItem(pk, ticketPrefix+"00000000000000000001#other", time.Now().Add(-3*time.Minute))
+
+	var ownTicket map[string]types.AttributeValue
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	expectTicketCounter(dynamodbClient, ctx, tableName, pk, "2")
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isTicketPut)).Run(func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) {
+		ownTicket = params.Item
+	}).Return(nil, nil).Once()
+
+	dynamodbClient.EXPECT().Query(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{expiredTicket, ownTicket}}, nil
+	}).Once()
+
+	dynamodbClient.EXPECT().DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 &tableName,
+		Key:                       map[string]types.AttributeValue{"PK": pk, "SK": expiredTicket["SK"]},
+		ConditionExpression:       aws.String("#ExpiresAt = :expiresAt"),
+		ExpressionAttributeNames:  map[string]string{"#ExpiresAt": attributeNameTicketExpiresAt},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":expiresAt": expiredTicket[attributeNameTicketExpiresAt]},
+	}).Return(nil, nil).Once()
+
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isLockPut)).Return(nil, nil).Once()
+
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return input.ConditionExpression == nil
+	})).Return(nil, nil).Once()
+
+	handler := New(dynamodbClient, tableName, "PK", WithSortKey("SK"), WithFairQueue(time.Minute), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+}
+
+func TestLock_Lock_FairQueue_RemovedTicketBehindActiveTicket(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+	otherTicket := ticketQueryItem(pk, ticketPrefix+"00000000000000000001#other", time.Now().Add(time.Minute))
+
+	var tickets []map[string]types.AttributeValue
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	expectTicketCounter(dynamodbClient, ctx, tableName, pk, "2")
+	expectTicketCounter(dynamodbClient, ctx, tableName, pk, "3")
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isTicketPut)).Run(func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) {
+		tickets = append(tickets, params.Item)
+	}).Return(nil, nil).Twice()
+
+	queryCalls := 0
+	dynamodbClient.EXPECT().Query(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+		queryCalls++
+
+		if queryCalls == 1 {
+			// Our ticket was removed, while the earlier ticket is still active
+			return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{otherTicket}}, nil
+		}
+
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{tickets[len(tickets)-1]}}, nil
+	}).Times(2)
+
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isLockPut)).Return(nil, nil).Once()
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	handler := New(dynamodbClient, tableName, "PK", WithSortKey("SK"), WithFairQueue(time.Minute),
+		WithRefreshInterval(time.Millisecond*10), WithRefreshVariance(0), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	require.Len(t, tickets, 2)
+	require.Equal(t, &types.AttributeValueMemberS{Value: ticketPrefix + "00000000000000000003#UniqueID"}, tickets[1]["SK"])
+}
+
+func TestRepositoryLockHandler_RefreshTicket(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+	sortKey := ticketPrefix + "00000000000000000001#UniqueID"
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == tableName &&
+			input.Key["SK"].(*types.AttributeValueMemberS).Value == sortKey &&
+			*input.UpdateExpression == "SET #A0 = :a0" &&
+			input.ExpressionAttributeNames["#A0"] == attributeNameTicketExpiresAt &&
+			*input.ConditionExpression == "attribute_exists(#SK)"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, "PK", WithSortKey("SK"), WithFairQueue(time.Minute))
+
+	queued := &ticket{sortKey: sortKey, expiresAt: time.Now()}
+
+	// When
+	found, err := handler.refreshTicket(ctx, pk, queued)
+
+	// Then
+	require.NoError(t, err)
+	require.True(t, found)
+	require.WithinDuration(t, time.Now().Add(time.Minute), queued.expiresAt, time.Second)
+}
+
+func TestRepositoryLockHandler_RefreshTicket_Removed(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	handler := New(dynamodbClient, "tableName", "PK", WithSortKey("SK"), WithFairQueue(time.Minute))
+
+	// When
+	found, err := handler.refreshTicket(ctx, &types.AttributeValueMemberS{Value: "PK"}, &ticket{sortKey: ticketPrefix + "00000000000000000001#UniqueID", expiresAt: time.Now()})
+
+	// Then
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestLock_TryLock_FairQueue_WaitersQueued(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().Query(ctx, mock.Anything).Return(&dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+		ticketQueryItem(pk, ticketPrefix+"00000000000000000001#other", time.Now().Add(time.Minute)),
+	}}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "PK", WithSortKey("SK"), WithFairQueue(time.Minute))
+
+	// When
+	lock, success, err := handler.TryLock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.False(t, success)
+	require.Nil(t, lock)
+}
+
+func TestLock_TryLock_FairQueue_ToleratesClockSkew(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	// The ticket expired according to the local clock, but less than the ticket timeout ago
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().Query(ctx, mock.Anything).Return(&dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+		ticketQueryItem(pk, ticketPrefix+"00000000000000000001#other", time.Now().Add(-30*time.Second)),
+	}}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "PK", WithSortKey("SK"), WithFairQueue(time.Minute))
+
+	// When
+	lock, success, err := handler.TryLock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.False(t, success)
+	require.Nil(t, lock)
+}
+
+func TestLock_Lock_FairQueue_TicketWithTTL(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	var ownTicket map[string]types.AttributeValue
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	expectTicketCounter(dynamodbClient, ctx, tableName, pk, "1")
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isTicketPut)).Run(func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) {
+		ownTicket = params.Item
+	}).Return(nil, nil).Once()
+
+	dynamodbClient.EXPECT().Query(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, input *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{ownTicket}}, nil
+	}).Once()
+
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(isLockPut)).Return(nil, nil).Once()
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	handler := New(dynamodbClient, tableName, "PK", WithSortKey("SK"), WithFairQueue(time.Minute), WithTTL("expiresAt", time.Hour), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	_, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+
+	expiresAt, err := parseNumberAttribute(ownTicket, "expiresAt")
+	require.NoError(t, err)
+	require.InDelta(t, time.Now().Add(time.Hour).Unix(), expiresAt, 5)
+}
+
+func TestLock_Lock_FairQueue_RequiresSortKey(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+
+	handler := New(dynamodbClient, "tableName", "PK", WithFairQueue(time.Minute))
+
+	// When
+	lock, err := handler.Lock(ctx, &types.AttributeValueMemberS{Value: "PK"})
+
+	// Then
+	var distrLockErr *ErrDistrLock
+	require.ErrorAs(t, err, &distrLockErr)
+	require.Nil(t, lock)
+}
@@ -0,0 +1,137 @@
+package distrlock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GuardedWrite contains the expressions to check a lock and to extend its lease within a write to the lock item itself, see Lock.GuardTransactWrite
+type GuardedWrite struct {
+	// ConditionExpression is true while the lock is held. It should be combined with the condition of the write using AND
+	ConditionExpression string
+
+	// ConditionAttributeNames and ConditionAttributeValues are the expression attribute names and values used by the ConditionExpression
+	ConditionAttributeNames  map[string]string
+	ConditionAttributeValues map[string]types.AttributeValue
+
+	// SetOperations extend the lease of the lock. They should be added to the SET clause of an update of the lock item
+	SetOperations []string
+
+	// SetAttributeNames and SetAttributeValues are the expression attribute names and values used by the SetOperations
+	SetAttributeNames  map[string]string
+	SetAttributeValues map[string]types.AttributeValue
+
+	// Attributes of the lock item after the lease is extended, which should be included in a put of the lock item.
+	// Nil for reentrant locks, as the hold count is unknown and the lock item can not be replaced.
+	Attributes map[string]types.AttributeValue
+}
+
+// GuardTransactWrite returns the expressions to execute a write in a TransactWriteItems call only while the lock is held, and to extend the lease in the same write.
+// This saves the extra transaction item of TransactionWithRefresh, but is only possible if the write targets the lock item itself. If not, false is returned and the lock is unchanged.
+// key should contain at least the key attributes of the written item, e.g. the item of a put. index is the position of the write in the transaction.
+// The write should return ALL_OLD on condition check failure, so a lost lock can be told apart from a failed condition of the write itself.
+//
+// The callback should be called with the result of the TransactWriteItems call. It updates the lock and returns an error wrapping ErrLockTakenOver if the lock was lost.
+// The lock is not refreshed by Refresh until the callback is called.
+func (l *Lock) GuardTransactWrite(tableName string, key map[string]types.AttributeValue, index int) (*GuardedWrite, func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), bool) {
+	if !l.isItem(tableName, key) {
+		return nil, nil, false
+	}
+
+	generatedId := l.repository.IdGenerator.ID()
+
+	l.mutex.Lock()
+	conditionExpression, expressionAttributeNames, expressionAttributeValues := l.condition()
+	previousLease := l.lease
+	holderAttributeName, holderValue := l.holder()
+	l.beginWrite()
+	l.mutex.Unlock()
+
+	attributes := map[string]types.AttributeValue{attributeNameLockId: &types.AttributeValueMemberS{Value: generatedId}}
+	newLease := l.repository.ownerAttributes(attributes, previousLease)
+	l.repository.expirationAttribute(attributes)
+
+	guardedWrite := &GuardedWrite{
+		ConditionExpression:      conditionExpression,
+		ConditionAttributeNames:  expressionAttributeNames,
+		ConditionAttributeValues: expressionAttributeValues,
+		SetAttributeNames:        map[string]string{},
+		SetAttributeValues:       map[string]types.AttributeValue{},
+	}
+
+	guardedWrite.SetOperations = setOperations(attributes, guardedWrite.SetAttributeNames, guardedWrite.SetAttributeValues)
+
+	if l.ownerId == "" {
+		attributes[attributeNameTimeout] = &types.AttributeValueMemberN{Value: strconv.FormatInt(l.repository.Timeout.Nanoseconds(), 10)}
+		guardedWrite.Attributes = attributes
+	}
+
+	var callbackOnce sync.Once
+
+	return guardedWrite, func(output *dynamodb.TransactWriteItemsOutput, err error) (*dynamodb.TransactWriteItemsOutput, error) {
+		callbackOnce.Do(func() {
+			if err == nil {
+				l.mutex.Lock()
+				l.lockId = generatedId
+				l.lease = newLease
+				l.mutex.Unlock()
+			}
+
+			l.endWrite()
+		})
+
+		if lockLost(err, index, holderAttributeName, holderValue) {
+			return output, fmt.Errorf("%w: %w", ErrLockTakenOver, err)
+		}
+
+		return output, err
+	}, true
+}
+
+// isItem returns true if the key identifies the lock item in the given table
+func (l *Lock) isItem(tableName string, key map[string]types.AttributeValue) bool {
+	if tableName != l.repository.TableName {
+		return false
+	}
+
+	for attributeName, value := range l.key() {
+		if !reflect.DeepEqual(key[attributeName], value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// holder returns the attribute name and value that identify the holder of the lock. The mutex must be held.
+func (l *Lock) holder() (string, string) {
+	if l.ownerId != "" {
+		return attributeNameOwnerId, l.ownerId
+	}
+
+	return attributeNameLockId, l.lockId
+}
+
+// lockLost returns true if the write at index failed its condition because the lock item, returned in the cancellation reason, is not held by the holder anymore
+func lockLost(err error, index int, holderAttributeName string, holderValue string) bool {
+	var transactionCanceledException *types.TransactionCanceledException
+	if !errors.As(err, &transactionCanceledException) || index >= len(transactionCanceledException.CancellationReasons) {
+		return false
+	}
+
+	reason := transactionCanceledException.CancellationReasons[index]
+	if aws.ToString(reason.Code) != cancellationReasonConditionalCheckFailed {
+		return false
+	}
+
+	value, found := reason.Item[holderAttributeName].(*types.AttributeValueMemberS)
+
+	return !found || value.Value != holderValue
+}
@@ -0,0 +1,172 @@
+package distrlock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func TestLock_GuardTransactWrite(t *testing.T) {
+	// Given
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		Timeout:          time.Second,
+		IdGenerator:      newIdGenerator(t, "newLockId"),
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+	}
+
+	// When
+	guardedWrite, callbackFn, ok := lock.GuardTransactWrite("DynamoDbTable", map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Some PK"}, "data": &types.AttributeValueMemberS{Value: "value"}}, 0)
+
+	// Then
+	require.True(t, ok)
+	require.Equal(t, &GuardedWrite{
+		ConditionExpression:      "#LockId = :lockId",
+		ConditionAttributeNames:  map[string]string{"#LockId": attributeNameLockId},
+		ConditionAttributeValues: map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: "someLockId"}},
+		SetOperations:            []string{"#A0 = :a0"},
+		SetAttributeNames:        map[string]string{"#A0": attributeNameLockId},
+		SetAttributeValues:       map[string]types.AttributeValue{":a0": &types.AttributeValueMemberS{Value: "newLockId"}},
+		Attributes: map[string]types.AttributeValue{
+			attributeNameLockId:  &types.AttributeValueMemberS{Value: "newLockId"},
+			attributeNameTimeout: &types.AttributeValueMemberN{Value: "1000000000"},
+		},
+	}, guardedWrite)
+
+	_, err := callbackFn(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, "newLockId", lock.LockId())
+	require.Equal(t, 0, lock.writes)
+}
+
+func TestLock_GuardTransactWrite_OtherItem(t *testing.T) {
+	// Given
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      mocks.NewIdGenerator(t),
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+	}
+
+	// When
+	_, _, otherKey := lock.GuardTransactWrite("DynamoDbTable", map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Other PK"}}, 0)
+	_, _, otherTable := lock.GuardTransactWrite("OtherTable", map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Some PK"}}, 0)
+
+	// Then
+	require.False(t, otherKey)
+	require.False(t, otherTable)
+	require.Equal(t, 0, lock.writes)
+}
+
+func TestLock_GuardTransactWrite_Reentrant(t *testing.T) {
+	// Given
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      newIdGenerator(t, "newLockId"),
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+		ownerId:    "owner",
+	}
+
+	// When
+	guardedWrite, callbackFn, ok := lock.GuardTransactWrite("DynamoDbTable", map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Some PK"}}, 0)
+
+	// Then
+	require.True(t, ok)
+	require.Equal(t, "#OwnerId = :ownerId", guardedWrite.ConditionExpression)
+	require.Nil(t, guardedWrite.Attributes)
+
+	_, err := callbackFn(nil, nil)
+	require.NoError(t, err)
+}
+
+func TestLock_GuardTransactWrite_LockLost(t *testing.T) {
+	// Given
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      newIdGenerator(t, "newLockId"),
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+	}
+
+	_, callbackFn, ok := lock.GuardTransactWrite("DynamoDbTable", map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Some PK"}}, 1)
+	require.True(t, ok)
+
+	// When
+	_, err := callbackFn(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{attributeNameLockId: &types.AttributeValueMemberS{Value: "otherLockId"}}},
+		},
+	})
+
+	// Then
+	require.ErrorIs(t, err, ErrLockTakenOver)
+	require.Equal(t, "someLockId", lock.LockId())
+}
+
+func TestLock_GuardTransactWrite_WriteConditionFailed(t *testing.T) {
+	// Given
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      newIdGenerator(t, "newLockId"),
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+	}
+
+	_, callbackFn, ok := lock.GuardTransactWrite("DynamoDbTable", map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Some PK"}}, 0)
+	require.True(t, ok)
+
+	// When
+	_, err := callbackFn(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{attributeNameLockId: &types.AttributeValueMemberS{Value: "someLockId"}}},
+		},
+	})
+
+	// Then
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrLockTakenOver)
+}
+
+func newIdGenerator(t *testing.T, id string) IdGenerator {
+	t.Helper()
+
+	idGenerator := mocks.NewIdGenerator(t)
+	idGenerator.EXPECT().ID().Return(id).Once()
+
+	return idGenerator
+}
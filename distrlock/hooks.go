@@ -0,0 +1,159 @@
+package distrlock
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// LockEvent describes the state of a lock acquisition when a hook is called
+type LockEvent struct {
+	// Partitions that are being locked
+	Partitions []types.AttributeValue
+
+	// Attempt number of attempts so far
+	Attempt int
+
+	// Waited total time spent waiting between attempts so far
+	Waited time.Duration
+
+	// Delay before the next attempt. Only set for OnWait
+	Delay time.Duration
+
+	// LockId of the existing lock. Only set for OnContention and OnTakeover
+	LockId string
+}
+
+// Hooks are callbacks that are called while acquiring a lock. All callbacks are optional.
+// Hooks can be used for logging and metrics.
+type Hooks struct {
+	// OnAttempt is called after every attempt to acquire the lock
+	OnAttempt func(event LockEvent)
+
+	// OnContention is called when an attempt failed because the partition is locked by another holder
+	OnContention func(event LockEvent)
+
+	// OnWait is called before waiting for the next attempt
+	OnWait func(event LockEvent)
+
+	// OnTakeover is called when an expired lock of another holder is taken over
+	OnTakeover func(event LockEvent)
+
+	// OnAcquired is called once the lock is acquired
+	OnAcquired func(event LockEvent)
+
+	// OnTimeout is called if the context is done before the lock is acquired
+	OnTimeout func(event LockEvent)
+}
+
+// WithHooks Specifies callbacks that are called while acquiring a lock
+func WithHooks(hooks Hooks) func(options *Options) {
+	return func(options *Options) {
+		options.Hooks = &hooks
+	}
+}
+
+// Metrics keeps counters of lock acquisitions. Use Hooks to register the metrics in a RepositoryLockHandler.
+// All counters are safe for concurrent use.
+type Metrics struct {
+	Attempts    atomic.Int64
+	Contentions atomic.Int64
+	Takeovers   atomic.Int64
+	Acquired    atomic.Int64
+	Timeouts    atomic.Int64
+
+	// WaitTime total time spent waiting between attempts in nanoseconds
+	WaitTime atomic.Int64
+}
+
+// Hooks returns hooks that update the metrics
+func (m *Metrics) Hooks() Hooks {
+	return Hooks{
+		OnAttempt:    func(LockEvent) { m.Attempts.Add(1) },
+		OnContention: func(LockEvent) { m.Contentions.Add(1) },
+		OnWait:       func(event LockEvent) { m.WaitTime.Add(int64(event.Delay)) },
+		OnTakeover:   func(LockEvent) { m.Takeovers.Add(1) },
+		OnAcquired:   func(LockEvent) { m.Acquired.Add(1) },
+		OnTimeout:    func(LockEvent) { m.Timeouts.Add(1) },
+	}
+}
+
+// lockWaiter keeps track of the attempts and waiting time of a lock acquisition and calls the hooks of the handler
+type lockWaiter struct {
+	handler    *RepositoryLockHandler
+	partitions []types.AttributeValue
+
+	attempts      int
+	waited        time.Duration
+	previousDelay time.Duration
+}
+
+func newLockWaiter(handler *RepositoryLockHandler, partitions ...types.AttributeValue) *lockWaiter {
+	return &lockWaiter{handler: handler, partitions: partitions}
+}
+
+func (w *lockWaiter) event() LockEvent {
+	return LockEvent{Partitions: w.partitions, Attempt: w.attempts, Waited: w.waited}
+}
+
+func (w *lockWaiter) attempted() {
+	w.attempts++
+
+	if hooks := w.handler.Hooks; hooks != nil && hooks.OnAttempt != nil {
+		hooks.OnAttempt(w.event())
+	}
+}
+
+func (w *lockWaiter) contention(lockId string) {
+	if hooks := w.handler.Hooks; hooks != nil && hooks.OnContention != nil {
+		event := w.event()
+		event.LockId = lockId
+
+		hooks.OnContention(event)
+	}
+}
+
+func (w *lockWaiter) takeover(lockId string) {
+	if hooks := w.handler.Hooks; hooks != nil && hooks.OnTakeover != nil {
+		event := w.event()
+		event.LockId = lockId
+
+		hooks.OnTakeover(event)
+	}
+}
+
+func (w *lockWaiter) acquired() {
+	if hooks := w.handler.Hooks; hooks != nil && hooks.OnAcquired != nil {
+		hooks.OnAcquired(w.event())
+	}
+}
+
+func (w *lockWaiter) timeout() error {
+	if hooks := w.handler.Hooks; hooks != nil && hooks.OnTimeout != nil {
+		hooks.OnTimeout(w.event())
+	}
+
+	return ErrTimeout
+}
+
+// wait sleeps until the next attempt according to the wait strategy of the handler
+func (w *lockWaiter) wait(ctx context.Context) {
+	delay := max(w.handler.waitStrategy().Wait(w.attempts, w.previousDelay), 0)
+
+	if hooks := w.handler.Hooks; hooks != nil && hooks.OnWait != nil {
+		event := w.event()
+		event.Delay = delay
+
+		hooks.OnWait(event)
+	}
+
+	w.previousDelay = delay
+	w.waited += delay
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+}
@@ -0,0 +1,102 @@
+package distrlock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func TestLock_Lock_Hooks_TakeOver(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		return input.ExpressionAttributeValues[":lockid"].(*types.AttributeValueMemberS).Value == ""
+	})).Return(nil, fmt.Errorf("context of error: %w", &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		pkName:               pk,
+		attributeNameLockId:  &types.AttributeValueMemberS{Value: "AnotherLock"},
+		attributeNameTimeout: &types.AttributeValueMemberN{Value: "50000000"},
+	}})).Times(2)
+
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		return input.ExpressionAttributeValues[":lockid"].(*types.AttributeValueMemberS).Value == "AnotherLock"
+	})).Return(nil, nil).Once()
+
+	metrics := Metrics{}
+	var takenOver []string
+
+	hooks := metrics.Hooks()
+	hooks.OnTakeover = func(event LockEvent) {
+		metrics.Takeovers.Add(1)
+		takenOver = append(takenOver, event.LockId)
+	}
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100),
+		WithWaitStrategy(ExponentialWait{Base: time.Millisecond * 20, Max: time.Millisecond * 40}),
+		WithHooks(hooks),
+		MockIdGenerator(t, "UniqueID"),
+	)
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	require.Equal(t, int64(3), metrics.Attempts.Load())
+	require.Equal(t, int64(2), metrics.Contentions.Load())
+	require.Equal(t, int64(1), metrics.Takeovers.Load())
+	require.Equal(t, int64(1), metrics.Acquired.Load())
+	require.Equal(t, int64(0), metrics.Timeouts.Load())
+	require.Equal(t, int64(time.Millisecond*60), metrics.WaitTime.Load())
+	require.Equal(t, []string{"AnotherLock"}, takenOver)
+}
+
+func TestLock_Lock_Hooks_Timeout(t *testing.T) {
+	// Given
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancelFn()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(ctx, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		pkName:               pk,
+		attributeNameLockId:  &types.AttributeValueMemberS{Value: "AnotherLock"},
+		attributeNameTimeout: &types.AttributeValueMemberN{Value: "300000000"},
+	}})
+
+	var timeoutEvent *LockEvent
+
+	handler := New(dynamodbClient, tableName, pkName,
+		WithWaitStrategy(FixedWait{Interval: time.Millisecond * 10}),
+		WithHooks(Hooks{OnTimeout: func(event LockEvent) { timeoutEvent = &event }}),
+		MockIdGenerator(t, "UniqueID"),
+	)
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Nil(t, lock)
+	require.NotNil(t, timeoutEvent)
+	require.Equal(t, []types.AttributeValue{pk}, timeoutEvent.Partitions)
+	require.Greater(t, timeoutEvent.Attempt, 0)
+	require.Equal(t, time.Millisecond*10*time.Duration(timeoutEvent.Attempt), timeoutEvent.Waited)
+}
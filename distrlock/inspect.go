@@ -0,0 +1,283 @@
+package distrlock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OwnerMetadata describes the holder of a lock. The metadata is stored in the lock item if configured.
+type OwnerMetadata struct {
+	// Hostname of the machine holding the lock
+	Hostname string
+
+	// ProcessId of the process holding the lock
+	ProcessId int
+
+	// Label is a caller-supplied identifier of the lock holder
+	Label string
+}
+
+// NewOwnerMetadata creates OwnerMetadata for the current process with the given label
+func NewOwnerMetadata(label string) OwnerMetadata {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return OwnerMetadata{
+		Hostname:  hostname,
+		ProcessId: os.Getpid(),
+		Label:     label,
+	}
+}
+
+// LockInfo describes a lock as it is currently stored in the lock table
+type LockInfo struct {
+	// Partition that is locked
+	Partition types.AttributeValue
+
+	// LockId of the current lock
+	LockId string
+
+	// Timeout of the lease
+	Timeout time.Duration
+
+	// Owner of the lock. Nil if the lock holder did not store owner metadata
+	Owner *OwnerMetadata
+
+	// AcquiredAt the time at which the current holder acquired the lock. Nil if unknown
+	AcquiredAt *time.Time
+
+	// RefreshedAt the time at which the current holder last refreshed the lock. Nil if unknown
+	RefreshedAt *time.Time
+
+	// OwnerId of a reentrant lock. Empty if the lock is not reentrant
+	OwnerId string
+
+	// HoldCount number of times a reentrant lock is held by its owner. Zero if the lock is not reentrant
+	HoldCount int64
+
+	// AcquisitionCount number of times the current holder acquired or refreshed the lock. Zero if unknown
+	AcquisitionCount int64
+
+	// LeaseRemaining estimated remaining time of the lease based on the local clock. Nil if unknown
+	LeaseRemaining *time.Duration
+}
+
+// Inspect returns information about the current holder of the lock on the specified partition.
+// If the partition is not locked, nil is returned.
+func (h *RepositoryLockHandler) Inspect(ctx context.Context, partition types.AttributeValue) (*LockInfo, error) {
+	getItemResult, err := h.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &h.TableName,
+		Key:            h.key(partition),
+		ConsistentRead: aws.Bool(true),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if getItemResult == nil {
+		return nil, nil
+	}
+
+	return parseLockInfo(partition, getItemResult.Item)
+}
+
+// List returns information about all locks stored in the lock table.
+// If a list index is specified, the index is queried. Otherwise, the table is scanned.
+// Note that locks of which the lease has expired are included until they are taken over or released.
+func (h *RepositoryLockHandler) List(ctx context.Context) ([]LockInfo, error) {
+	var items []map[string]types.AttributeValue
+
+	var err error
+
+	if h.ListIndexName != nil && h.hasSortKey() {
+		items, err = h.listQuery(ctx)
+	} else {
+		items, err = h.listScan(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]LockInfo, 0, len(items))
+
+	for _, item := range items {
+		info, parseErr := parseLockInfo(item[h.PartitionKeyName], item)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		if info != nil {
+			result = append(result, *info)
+		}
+	}
+
+	return result, nil
+}
+
+func (h *RepositoryLockHandler) listQuery(ctx context.Context) ([]map[string]types.AttributeValue, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                 &h.TableName,
+		IndexName:                 h.ListIndexName,
+		KeyConditionExpression:    aws.String("#SK = :sk"),
+		FilterExpression:          aws.String("attribute_exists(#LockID)"),
+		ExpressionAttributeNames:  map[string]string{"#SK": *h.SortKeyName, "#LockID": attributeNameLockId},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":sk": h.SortKeyValue},
+	}
+
+	var items []map[string]types.AttributeValue
+
+	for {
+		output, err := h.Client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, output.Items...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+func (h *RepositoryLockHandler) listScan(ctx context.Context) ([]map[string]types.AttributeValue, error) {
+	input := &dynamodb.ScanInput{
+		TableName:                &h.TableName,
+		FilterExpression:         aws.String("attribute_exists(#LockID)"),
+		ExpressionAttributeNames: map[string]string{"#LockID": attributeNameLockId},
+		ConsistentRead:           aws.Bool(true),
+	}
+
+	if h.hasSortKey() {
+		input.FilterExpression = aws.String("attribute_exists(#LockID) AND #SK = :sk")
+		input.ExpressionAttributeNames["#SK"] = *h.SortKeyName
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{":sk": h.SortKeyValue}
+	}
+
+	var items []map[string]types.AttributeValue
+
+	for {
+		output, err := h.Client.Scan(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, output.Items...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+func parseLockInfo(partition types.AttributeValue, item map[string]types.AttributeValue) (*LockInfo, error) {
+	lockKeyAttribute, found := item[attributeNameLockId]
+	if !found {
+		// If no lockId is found, we assume that the lock is not active anymore
+		return nil, nil
+	}
+
+	lockKey, ok := lockKeyAttribute.(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, NewDistrLockError(fmt.Sprintf("attribute %s not of expected type AttributeValueMemberS but was %T", attributeNameLockId, lockKeyAttribute), nil)
+	}
+
+	timeoutNs, err := parseNumberAttribute(item, attributeNameTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	info := LockInfo{
+		Partition: partition,
+		LockId:    lockKey.Value,
+		Timeout:   time.Duration(timeoutNs) * time.Nanosecond,
+	}
+
+	if hostname, found := item[attributeNameOwnerHostname].(*types.AttributeValueMemberS); found {
+		info.Owner = &OwnerMetadata{Hostname: hostname.Value}
+
+		if label, labelFound := item[attributeNameOwnerLabel].(*types.AttributeValueMemberS); labelFound {
+			info.Owner.Label = label.Value
+		}
+
+		if _, pidFound := item[attributeNameOwnerProcessId]; pidFound {
+			pid, pidErr := parseNumberAttribute(item, attributeNameOwnerProcessId)
+			if pidErr != nil {
+				return nil, pidErr
+			}
+
+			info.Owner.ProcessId = int(pid)
+		}
+	}
+
+	if ownerId, found := item[attributeNameOwnerId].(*types.AttributeValueMemberS); found {
+		info.OwnerId = ownerId.Value
+
+		info.HoldCount, err = parseNumberAttribute(item, attributeNameHoldCount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info.AcquiredAt, err = parseTimeAttribute(item, attributeNameAcquiredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	info.RefreshedAt, err = parseTimeAttribute(item, attributeNameRefreshedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, found := item[attributeNameAcquisitionCount]; found {
+		info.AcquisitionCount, err = parseNumberAttribute(item, attributeNameAcquisitionCount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if info.RefreshedAt != nil {
+		remaining := max(time.Until(info.RefreshedAt.Add(info.Timeout)), 0)
+		info.LeaseRemaining = &remaining
+	}
+
+	return &info, nil
+}
+
+func parseNumberAttribute(item map[string]types.AttributeValue, attributeName string) (int64, error) {
+	attribute, ok := item[attributeName].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, NewDistrLockError(fmt.Sprintf("attribute %s not of expected type AttributeValueMemberN but was %T", attributeName, item[attributeName]), nil)
+	}
+
+	return strconv.ParseInt(attribute.Value, 10, 64)
+}
+
+func parseTimeAttribute(item map[string]types.AttributeValue, attributeName string) (*time.Time, error) {
+	attribute, found := item[attributeName].(*types.AttributeValueMemberS)
+	if !found {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, attribute.Value)
+	if err != nil {
+		return nil, NewDistrLockError(fmt.Sprintf("attribute %s is not a valid timestamp", attributeName), err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,232 @@
+package distrlock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func TestLock_TryLock_WithOwnerMetadata(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	var storedItems []map[string]types.AttributeValue
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(ctx, mock.Anything).Run(func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) {
+		storedItems = append(storedItems, params.Item)
+	}).Return(nil, nil).Once()
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.Anything).Run(func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) {
+		storedItems = append(storedItems, updatedAttributes(params))
+	}).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
+	handler.Owner = &OwnerMetadata{Hostname: "host", ProcessId: 42, Label: "worker"}
+
+	// When
+	lock, success, err := handler.TryLock(ctx, pk)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	err = lock.Refresh(ctx)
+	require.NoError(t, err)
+
+	// Then
+	require.Len(t, storedItems, 2)
+
+	require.Equal(t, &types.AttributeValueMemberS{Value: "host"}, storedItems[0][attributeNameOwnerHostname])
+	require.Equal(t, &types.AttributeValueMemberN{Value: "42"}, storedItems[0][attributeNameOwnerProcessId])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "worker"}, storedItems[0][attributeNameOwnerLabel])
+	require.Equal(t, &types.AttributeValueMemberN{Value: "1"}, storedItems[0][attributeNameAcquisitionCount])
+	require.Equal(t, &types.AttributeValueMemberN{Value: "2"}, storedItems[1][attributeNameAcquisitionCount])
+	require.Equal(t, storedItems[0][attributeNameAcquiredAt], storedItems[1][attributeNameAcquiredAt])
+}
+
+// updatedAttributes returns the attributes that are set by the SET operations of the update
+func updatedAttributes(input *dynamodb.UpdateItemInput) map[string]types.AttributeValue {
+	attributes := map[string]types.AttributeValue{}
+
+	for name, attributeName := range input.ExpressionAttributeNames {
+		if strings.HasPrefix(name, "#A") {
+			attributes[attributeName] = input.ExpressionAttributeValues[":a"+strings.TrimPrefix(name, "#A")]
+		}
+	}
+
+	return attributes
+}
+
+func TestRepositoryLockHandler_Inspect(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	acquiredAt := time.Now().Add(-time.Minute).UTC()
+	refreshedAt := time.Now().UTC()
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &tableName,
+		Key:            map[string]types.AttributeValue{pkName: pk},
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.GetItemOutput{
+		Item: map[string]types.AttributeValue{
+			pkName:                        pk,
+			attributeNameLockId:           &types.AttributeValueMemberS{Value: "LockID"},
+			attributeNameTimeout:          &types.AttributeValueMemberN{Value: "60000000000"},
+			attributeNameOwnerHostname:    &types.AttributeValueMemberS{Value: "host"},
+			attributeNameOwnerProcessId:   &types.AttributeValueMemberN{Value: "42"},
+			attributeNameOwnerLabel:       &types.AttributeValueMemberS{Value: "worker"},
+			attributeNameAcquiredAt:       &types.AttributeValueMemberS{Value: acquiredAt.Format(time.RFC3339Nano)},
+			attributeNameRefreshedAt:      &types.AttributeValueMemberS{Value: refreshedAt.Format(time.RFC3339Nano)},
+			attributeNameAcquisitionCount: &types.AttributeValueMemberN{Value: "7"},
+		},
+	}, nil)
+
+	handler := New(dynamodbClient, tableName, pkName)
+
+	// When
+	info, err := handler.Inspect(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	require.Equal(t, pk, info.Partition)
+	require.Equal(t, "LockID", info.LockId)
+	require.Equal(t, time.Minute, info.Timeout)
+	require.Equal(t, &OwnerMetadata{Hostname: "host", ProcessId: 42, Label: "worker"}, info.Owner)
+	require.True(t, acquiredAt.Equal(*info.AcquiredAt))
+	require.True(t, refreshedAt.Equal(*info.RefreshedAt))
+	require.Equal(t, int64(7), info.AcquisitionCount)
+	require.NotNil(t, info.LeaseRemaining)
+	require.Greater(t, *info.LeaseRemaining, time.Duration(0))
+	require.LessOrEqual(t, *info.LeaseRemaining, time.Minute)
+}
+
+func TestRepositoryLockHandler_Inspect_NotLocked(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().GetItem(ctx, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	handler := New(dynamodbClient, tableName, pkName)
+
+	// When
+	info, err := handler.Inspect(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.Nil(t, info)
+}
+
+func TestRepositoryLockHandler_List_Scan(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+
+	lastEvaluatedKey := map[string]types.AttributeValue{pkName: &types.AttributeValueMemberS{Value: "PK1"}, "SK": SkString}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().Scan(ctx, &dynamodb.ScanInput{
+		TableName:                 &tableName,
+		FilterExpression:          aws.String("attribute_exists(#LockID) AND #SK = :sk"),
+		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#SK": "SK"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":sk": SkString},
+		ConsistentRead:            aws.Bool(true),
+	}).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				pkName:               &types.AttributeValueMemberS{Value: "PK1"},
+				"SK":                 SkString,
+				attributeNameLockId:  &types.AttributeValueMemberS{Value: "Lock1"},
+				attributeNameTimeout: &types.AttributeValueMemberN{Value: "1000"},
+			},
+		},
+		LastEvaluatedKey: lastEvaluatedKey,
+	}, nil).Once()
+
+	dynamodbClient.EXPECT().Scan(ctx, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				pkName:               &types.AttributeValueMemberS{Value: "PK2"},
+				"SK":                 SkString,
+				attributeNameLockId:  &types.AttributeValueMemberS{Value: "Lock2"},
+				attributeNameTimeout: &types.AttributeValueMemberN{Value: "2000"},
+			},
+		},
+	}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithSortKey("SK"))
+
+	// When
+	locks, err := handler.List(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []LockInfo{
+		{Partition: &types.AttributeValueMemberS{Value: "PK1"}, LockId: "Lock1", Timeout: 1000},
+		{Partition: &types.AttributeValueMemberS{Value: "PK2"}, LockId: "Lock2", Timeout: 2000},
+	}, locks)
+}
+
+func TestRepositoryLockHandler_List_Query(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().Query(ctx, &dynamodb.QueryInput{
+		TableName:                 &tableName,
+		IndexName:                 aws.String("SKIndex"),
+		KeyConditionExpression:    aws.String("#SK = :sk"),
+		FilterExpression:          aws.String("attribute_exists(#LockID)"),
+		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#SK": "SK"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":sk": SkString},
+	}).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				pkName:               &types.AttributeValueMemberS{Value: "PK1"},
+				"SK":                 SkString,
+				attributeNameLockId:  &types.AttributeValueMemberS{Value: "Lock1"},
+				attributeNameTimeout: &types.AttributeValueMemberN{Value: "1000"},
+			},
+		},
+	}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithSortKey("SK"), WithListIndex("SKIndex"))
+
+	// When
+	locks, err := handler.List(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []LockInfo{
+		{Partition: &types.AttributeValueMemberS{Value: "PK1"}, LockId: "Lock1", Timeout: 1000},
+	}, locks)
+}
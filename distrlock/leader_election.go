@@ -0,0 +1,221 @@
+package distrlock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// LeaderCallbacks are called when the LeaderElector starts or stops leading
+type LeaderCallbacks struct {
+	// OnStartedLeading is called in a new goroutine once leadership is acquired.
+	// The context is cancelled as soon as leadership is lost or resigned. The callback should return once the context is Done.
+	OnStartedLeading func(ctx context.Context)
+
+	// OnStoppedLeading is called once leadership is lost or resigned and OnStartedLeading has returned
+	OnStoppedLeading func()
+}
+
+// LeaderElector campaigns for the lock of a single partition and keeps the lease alive while leading.
+// Only one LeaderElector per partition is leading at any time.
+type LeaderElector struct {
+	Handler       *RepositoryLockHandler
+	Partition     types.AttributeValue
+	Callbacks     LeaderCallbacks
+	RenewInterval time.Duration
+
+	mutex    sync.Mutex
+	leading  bool
+	cancelFn context.CancelFunc
+	done     chan struct{}
+}
+
+type LeaderElectorOptions struct {
+	// RenewInterval time between two lease refreshes while leading
+	RenewInterval *time.Duration
+}
+
+// NewLeaderElector creates a new LeaderElector that campaigns for the given partition.
+// Configure the handler WithOwnerMetadata, so followers can see who is leading.
+func NewLeaderElector(handler *RepositoryLockHandler, partition types.AttributeValue, callbacks LeaderCallbacks, optFns ...func(options *LeaderElectorOptions)) *LeaderElector {
+	options := LeaderElectorOptions{}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	elector := &LeaderElector{
+		Handler:       handler,
+		Partition:     partition,
+		Callbacks:     callbacks,
+		RenewInterval: handler.Timeout / 3,
+	}
+
+	if options.RenewInterval != nil {
+		elector.RenewInterval = *options.RenewInterval
+	}
+
+	return elector
+}
+
+// WithRenewInterval Specifies the time between two lease refreshes while leading. Should be significantly smaller than the lock timeout.
+// Default value is a third of the lock timeout.
+func WithRenewInterval(renewInterval time.Duration) func(options *LeaderElectorOptions) {
+	return func(options *LeaderElectorOptions) {
+		options.RenewInterval = &renewInterval
+	}
+}
+
+// Run campaigns for leadership until the context is Done or Resign is called.
+// If leadership is lost, the elector starts campaigning again. The lock is released before Run returns.
+// Transient errors, e.g. throttling, are retried with the wait strategy of the handler. Other errors stop the campaign and are returned.
+func (e *LeaderElector) Run(ctx context.Context) error {
+	e.mutex.Lock()
+
+	if e.done != nil {
+		e.mutex.Unlock()
+
+		return NewDistrLockError("leader elector is already running", nil)
+	}
+
+	runCtx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+
+	e.cancelFn = cancelFn
+	e.done = make(chan struct{})
+
+	done := e.done
+
+	e.mutex.Unlock()
+
+	defer func() {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		close(done)
+
+		e.cancelFn = nil
+		e.done = nil
+	}()
+
+	waitStrategy := e.Handler.waitStrategy()
+	failedAttempts := 0
+	previousDelay := time.Duration(0)
+
+	for {
+		lock, err := e.Handler.Lock(runCtx, e.Partition)
+		if runCtx.Err() != nil {
+			if lock != nil {
+				return lock.Release(context.WithoutCancel(runCtx))
+			}
+
+			return nil
+		}
+
+		if err == nil {
+			failedAttempts = 0
+			previousDelay = 0
+
+			err = e.lead(runCtx, lock)
+		}
+
+		if runCtx.Err() != nil {
+			return err
+		}
+
+		if err != nil {
+			if !isRetryable(err) {
+				return err
+			}
+
+			// Transient errors, e.g. throttling, should not stop the campaign
+			failedAttempts++
+			previousDelay = waitStrategy.Wait(failedAttempts, previousDelay)
+
+			select {
+			case <-runCtx.Done():
+				return nil
+			case <-time.After(previousDelay):
+			}
+		}
+	}
+}
+
+// Resign stops leading, releases the lock and stops campaigning. Resign blocks until Run returns or the context is Done.
+func (e *LeaderElector) Resign(ctx context.Context) error {
+	e.mutex.Lock()
+	cancelFn := e.cancelFn
+	done := e.done
+	e.mutex.Unlock()
+
+	if cancelFn == nil {
+		return nil
+	}
+
+	cancelFn()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsLeader returns true if this elector is currently leading
+func (e *LeaderElector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.leading
+}
+
+// Leader returns information about the current leader, or nil if nobody is leading.
+// The owner metadata is only available if the handler is configured WithOwnerMetadata.
+func (e *LeaderElector) Leader(ctx context.Context) (*LockInfo, error) {
+	return e.Handler.Inspect(ctx, e.Partition)
+}
+
+// lead keeps the lease alive until leadership is lost or the context is Done
+func (e *LeaderElector) lead(ctx context.Context, lock *Lock) error {
+	leadCtx, cancelLeading := context.WithCancel(ctx)
+	defer cancelLeading()
+
+	e.setLeading(true)
+
+	callbackDone := make(chan struct{})
+
+	go func() {
+		defer close(callbackDone)
+
+		if e.Callbacks.OnStartedLeading != nil {
+			e.Callbacks.OnStartedLeading(leadCtx)
+		}
+	}()
+
+	lost := keepAlive(leadCtx, lock, e.RenewInterval, e.Handler.Timeout) != nil
+
+	cancelLeading()
+	<-callbackDone
+
+	var err error
+	if !lost {
+		err = lock.Release(context.WithoutCancel(ctx))
+	}
+
+	e.setLeading(false)
+
+	if e.Callbacks.OnStoppedLeading != nil {
+		e.Callbacks.OnStoppedLeading()
+	}
+
+	return err
+}
+
+func (e *LeaderElector) setLeading(leading bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.leading = leading
+}
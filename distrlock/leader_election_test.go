@@ -0,0 +1,163 @@
+package distrlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func TestLeaderElector_Run_Resign(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "leader"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	dynamodbClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Millisecond*30), MockIdGenerator(t, "UniqueID"))
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+
+	elector := NewLeaderElector(handler, pk, LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+		},
+		OnStoppedLeading: func() {
+			close(stopped)
+		},
+	})
+
+	runErr := make(chan error)
+
+	go func() {
+		runErr <- elector.Run(ctx)
+	}()
+
+	<-started
+	require.True(t, elector.IsLeader())
+
+	time.Sleep(time.Millisecond * 50)
+
+	// When
+	err := elector.Resign(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, <-runErr)
+	require.False(t, elector.IsLeader())
+
+	<-stopped
+
+	// Lease was acquired and refreshed at least once
+	require.GreaterOrEqual(t, len(dynamodbClient.Calls), 3)
+}
+
+func TestLeaderElector_Run_LostLeadership(t *testing.T) {
+	// Given
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	pk := &types.AttributeValueMemberS{Value: "leader"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	dynamodbClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		"pkName":            pk,
+		attributeNameLockId: &types.AttributeValueMemberS{Value: "OtherLock"},
+	}}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Millisecond*30), MockIdGenerator(t, "UniqueID"))
+
+	var leadingCtx context.Context
+
+	elector := NewLeaderElector(handler, pk, LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			leadingCtx = ctx
+			<-ctx.Done()
+		},
+		OnStoppedLeading: cancelFn,
+	})
+
+	// When
+	err := elector.Run(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.False(t, elector.IsLeader())
+	require.Error(t, leadingCtx.Err())
+}
+
+func TestLeaderElector_Run_RetryTransientErrors(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "leader"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{Message: aws.String("throttled")}).Twice()
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	dynamodbClient.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Maybe()
+	dynamodbClient.EXPECT().DeleteItem(mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Millisecond*30),
+		WithWaitStrategy(FixedWait{Interval: time.Millisecond * 5}), MockIdGenerator(t, "UniqueID"))
+
+	started := make(chan struct{})
+
+	elector := NewLeaderElector(handler, pk, LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+		},
+	})
+
+	runErr := make(chan error)
+
+	go func() {
+		runErr <- elector.Run(ctx)
+	}()
+
+	// When
+	<-started
+
+	err := elector.Resign(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, <-runErr)
+}
+
+func TestLeaderElector_Run_NonRetryableError(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "leader"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(mock.Anything, mock.Anything).Return(nil, &types.ResourceNotFoundException{Message: aws.String("table not found")}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithTimeout(time.Millisecond*30), MockIdGenerator(t, "UniqueID"))
+
+	elector := NewLeaderElector(handler, pk, LeaderCallbacks{})
+
+	// When
+	err := elector.Run(ctx)
+
+	// Then
+	var notFoundErr *types.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFoundErr)
+	require.False(t, elector.IsLeader())
+}
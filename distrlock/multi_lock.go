@@ -0,0 +1,437 @@
+package distrlock
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MaxMultiLockPartitions is the maximum number of partitions that can be locked at once. This is limited by the number of items in a single DynamoDB transaction.
+const MaxMultiLockPartitions = 100
+
+const cancellationReasonConditionalCheckFailed = "ConditionalCheckFailed"
+const cancellationReasonTransactionConflict = "TransactionConflict"
+
+// MultiLock is a lock on multiple partitions that is acquired all-or-nothing
+type MultiLock struct {
+	repository *RepositoryLockHandler
+	locks      []*Lock
+}
+
+// TryLockMany tries to lock all specified partitions in a single transaction.
+// If the handler was able to lock all partitions a new MultiLock will be returned. Additionally, the second return argument will be true.
+// If any of the partitions is already locked, no partition is locked and nil and false are returned as first arguments.
+// Partitions are deduplicated and sorted canonically. Note that reentrant locking is not supported for multi partition locks.
+func (h *RepositoryLockHandler) TryLockMany(ctx context.Context, partitions ...types.AttributeValue) (*MultiLock, bool, error) {
+	sortedPartitions, err := canonicalPartitions(partitions)
+	if err != nil {
+		return nil, false, err
+	}
+
+	lock, success, _, err := h.lockMany(ctx, sortedPartitions, make([]string, len(sortedPartitions)), nil)
+
+	return lock, success, err
+}
+
+// LockMany tries to lock all specified partitions in a single transaction.
+// The method will return a new MultiLock once it is able to lock all partitions.
+// If it was unable to lock all partitions it will try again after a delay determined by the WaitStrategy. Locks that are not refreshed within their timeout are taken over.
+// Polling will stop if the context is Done.
+// Partitions are deduplicated and sorted canonically. Note that reentrant locking is not supported for multi partition locks.
+func (h *RepositoryLockHandler) LockMany(ctx context.Context, partitions ...types.AttributeValue) (*MultiLock, error) {
+	sortedPartitions, err := canonicalPartitions(partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	waiter := newLockWaiter(h, sortedPartitions...)
+
+	attempts := make([]*lockAttempt, len(sortedPartitions))
+	for i := range sortedPartitions {
+		attempts[i] = &lockAttempt{handler: h, partition: sortedPartitions[i], waiter: waiter}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, waiter.timeout()
+		default:
+			expectedLockIds := make([]string, len(attempts))
+			for i := range attempts {
+				expectedLockIds[i] = attempts[i].expectedLockId()
+			}
+
+			lock, success, existingLocks, lockErr := h.lockMany(ctx, sortedPartitions, expectedLockIds, nil)
+			if lockErr != nil {
+				return nil, lockErr
+			}
+
+			waiter.attempted()
+
+			if success {
+				for _, expectedLockId := range expectedLockIds {
+					if expectedLockId != "" {
+						waiter.takeover(expectedLockId)
+					}
+				}
+
+				waiter.acquired()
+
+				return lock, nil
+			}
+
+			for i, existingLock := range existingLocks {
+				if existingLock != nil {
+					waiter.contention(existingLock.LockId)
+					attempts[i].observe(&existingLock.LockId, &existingLock.Timeout)
+				}
+			}
+
+			waiter.wait(ctx)
+		}
+	}
+}
+
+// lockMany executes a single transaction to lock all partitions. If the transaction is cancelled, the locks that prevented the acquisition are returned as third argument.
+func (h *RepositoryLockHandler) lockMany(ctx context.Context, partitions []types.AttributeValue, existingLockIds []string, previousLeases []*lease) (*MultiLock, bool, []*LockInfo, error) {
+	transactItems := make([]types.TransactWriteItem, 0, len(partitions))
+	locks := make([]*Lock, 0, len(partitions))
+
+	for i, partition := range partitions {
+		generatedId := h.IdGenerator.ID()
+
+		item := h.key(partition)
+		item[attributeNameLockId] = &types.AttributeValueMemberS{Value: generatedId}
+		item[attributeNameTimeout] = &types.AttributeValueMemberN{Value: strconv.FormatInt(h.Timeout.Nanoseconds(), 10)}
+
+		var previousLease *lease
+		if previousLeases != nil {
+			previousLease = previousLeases[i]
+		}
+
+		newLease := h.ownerAttributes(item, previousLease)
+		h.expirationAttribute(item)
+
+		conditionExpression, expressionAttributeNames, expressionAttributeValues := h.putCondition(existingLockIds[i])
+
+		transactItems = append(transactItems, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName:                           &h.TableName,
+				Item:                                item,
+				ConditionExpression:                 &conditionExpression,
+				ExpressionAttributeNames:            expressionAttributeNames,
+				ExpressionAttributeValues:           expressionAttributeValues,
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+		})
+
+		locks = append(locks, &Lock{lockId: generatedId, partition: partition, repository: h, lease: newLease})
+	}
+
+	_, err := h.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: transactItems})
+	if err != nil {
+		var transactionConflictException *types.TransactionConflictException
+		if errors.As(err, &transactionConflictException) {
+			return nil, false, nil, nil
+		}
+
+		var transactionCanceledException *types.TransactionCanceledException
+		if !errors.As(err, &transactionCanceledException) {
+			return nil, false, nil, err
+		}
+
+		existingLocks, parseErr := h.parseCancellationReasons(ctx, partitions, transactionCanceledException.CancellationReasons)
+		if parseErr != nil {
+			return nil, false, nil, parseErr
+		}
+
+		return nil, false, existingLocks, nil
+	}
+
+	return &MultiLock{repository: h, locks: locks}, true, nil, nil
+}
+
+// parseCancellationReasons returns the existing locks that caused the cancellation of a lock transaction
+func (h *RepositoryLockHandler) parseCancellationReasons(ctx context.Context, partitions []types.AttributeValue, reasons []types.CancellationReason) ([]*LockInfo, error) {
+	existingLocks := make([]*LockInfo, len(partitions))
+
+	for i, reason := range reasons {
+		if i >= len(partitions) || reason.Code == nil {
+			continue
+		}
+
+		switch *reason.Code {
+		case cancellationReasonConditionalCheckFailed:
+			if reason.Item != nil {
+				info, err := parseLockInfo(partitions[i], reason.Item)
+				if err != nil {
+					return nil, err
+				}
+
+				existingLocks[i] = info
+
+				continue
+			}
+
+			existingLockId, leaseDuration, err := h.lockLookup(ctx, partitions[i])
+			if err != nil {
+				return nil, err
+			}
+
+			if existingLockId != nil {
+				existingLocks[i] = &LockInfo{Partition: partitions[i], LockId: *existingLockId, Timeout: *leaseDuration}
+			}
+		case cancellationReasonTransactionConflict, "None":
+			continue
+		default:
+			return nil, NewDistrLockError(fmt.Sprintf("lock transaction cancelled: %s", *reason.Code), nil)
+		}
+	}
+
+	return existingLocks, nil
+}
+
+// Locks returns the locks of the individual partitions
+func (m *MultiLock) Locks() []*Lock {
+	return m.locks
+}
+
+// Partitions returns the locked partitions in canonical order
+func (m *MultiLock) Partitions() []types.AttributeValue {
+	partitions := make([]types.AttributeValue, 0, len(m.locks))
+	for _, lock := range m.locks {
+		partitions = append(partitions, lock.partition)
+	}
+
+	return partitions
+}
+
+// Release removes the locks of all partitions in the database.
+// All locks are released, even if releasing one of them fails. The returned error combines all errors.
+func (m *MultiLock) Release(ctx context.Context) error {
+	var err error
+
+	for _, lock := range m.locks {
+		err = errors.Join(err, lock.Release(ctx))
+	}
+
+	return err
+}
+
+// Refresh updates the timeout of the locks of all partitions in a single transaction.
+// Like Lock.Refresh, the lock items are only updated and never created, so released or removed locks are not acquired again.
+// Refresh waits until the lock-conditioned writes of the locks are finished, as refreshing the locks invalidates their conditions.
+// ErrLockTakenOver is returned if any of the locks is held by another holder and ErrLockNotHeld if any of the locks was released or removed.
+// If the context is Done while waiting for writes, the context error is returned.
+func (m *MultiLock) Refresh(ctx context.Context) error {
+	if err := m.lockAll(ctx); err != nil {
+		return err
+	}
+
+	defer m.unlockAll(len(m.locks))
+
+	for _, lock := range m.locks {
+		if lock.released {
+			return ErrLockNotHeld
+		}
+	}
+
+	transactItems := make([]types.TransactWriteItem, 0, len(m.locks))
+	generatedIds := make([]string, 0, len(m.locks))
+	newLeases := make([]*lease, 0, len(m.locks))
+
+	for _, lock := range m.locks {
+		conditionExpression, expressionAttributeNames, expressionAttributeValues := lock.condition()
+		conditionExpression = "attribute_exists(#LockId) AND " + conditionExpression
+
+		updateExpression, generatedId, newLease := lock.refreshExpression(expressionAttributeNames, expressionAttributeValues)
+
+		transactItems = append(transactItems, types.TransactWriteItem{
+			Update: &types.Update{
+				TableName:                           &m.repository.TableName,
+				Key:                                 lock.key(),
+				UpdateExpression:                    &updateExpression,
+				ConditionExpression:                 &conditionExpression,
+				ExpressionAttributeNames:            expressionAttributeNames,
+				ExpressionAttributeValues:           expressionAttributeValues,
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+		})
+
+		generatedIds = append(generatedIds, generatedId)
+		newLeases = append(newLeases, newLease)
+	}
+
+	err := m.repository.retryConflicts(ctx, func() error {
+		_, transactErr := m.repository.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: transactItems})
+
+		return refreshManyError(transactErr)
+	})
+	if err != nil {
+		if isTransactionConflict(err) {
+			return fmt.Errorf("%w: %w", ErrLockUpdate, err)
+		}
+
+		return err
+	}
+
+	for i, lock := range m.locks {
+		lock.lockId = generatedIds[i]
+		lock.lease = newLeases[i]
+	}
+
+	return nil
+}
+
+// lockAll locks the mutexes of all locks once none of the locks has lock-conditioned writes in flight.
+// No mutex is held while waiting, so writes that guard several of the locks can finish.
+func (m *MultiLock) lockAll(ctx context.Context) error {
+	for {
+		var writesDone chan struct{}
+
+		for i, lock := range m.locks {
+			lock.mutex.Lock()
+
+			if lock.writes > 0 {
+				writesDone = lock.writesDone
+				m.unlockAll(i + 1)
+
+				break
+			}
+		}
+
+		if writesDone == nil {
+			return nil
+		}
+
+		select {
+		case <-writesDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// unlockAll unlocks the mutexes of the first n locks
+func (m *MultiLock) unlockAll(n int) {
+	for _, lock := range m.locks[:n] {
+		lock.mutex.Unlock()
+	}
+}
+
+// refreshManyError interprets a failed refresh transaction of a MultiLock.
+// A failed condition is returned as ErrLockNotHeld if the lock item was removed and as ErrLockTakenOver otherwise. A cancellation by a conflicting transaction is returned as errTransactionConflict, so it is retried.
+func refreshManyError(err error) error {
+	var transactionCanceledException *types.TransactionCanceledException
+	if !errors.As(err, &transactionCanceledException) {
+		return err
+	}
+
+	conflict := false
+
+	for _, reason := range transactionCanceledException.CancellationReasons {
+		switch aws.ToString(reason.Code) {
+		case cancellationReasonConditionalCheckFailed:
+			if reason.Item == nil {
+				return fmt.Errorf("%w: %w", ErrLockNotHeld, err)
+			}
+
+			return fmt.Errorf("%w: %w", ErrLockTakenOver, err)
+		case cancellationReasonTransactionConflict:
+			conflict = true
+		}
+	}
+
+	if conflict {
+		return fmt.Errorf("%w: %w", errTransactionConflict, err)
+	}
+
+	return err
+}
+
+// TransactionCondition returns TransactWriteItems to validate if the locks of all partitions are still active
+func (m *MultiLock) TransactionCondition() []types.TransactWriteItem {
+	items := make([]types.TransactWriteItem, 0, len(m.locks))
+	for _, lock := range m.locks {
+		items = append(items, lock.TransactionCondition())
+	}
+
+	return items
+}
+
+// TransactionWithRefresh returns TransactWriteItems to validate if the locks of all partitions are still active and refresh the locks if successful
+// Note the callback function returned as second argument should be called with the return types of the TransactWriteItems call
+func (m *MultiLock) TransactionWithRefresh() ([]types.TransactWriteItem, func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error)) {
+	items := make([]types.TransactWriteItem, 0, len(m.locks))
+	callbacks := make([]func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), 0, len(m.locks))
+
+	for _, lock := range m.locks {
+		item, callback := lock.TransactionWithRefresh()
+		items = append(items, item)
+		callbacks = append(callbacks, callback)
+	}
+
+	return items, func(output *dynamodb.TransactWriteItemsOutput, err error) (*dynamodb.TransactWriteItemsOutput, error) {
+		for _, callback := range callbacks {
+			output, err = callback(output, err)
+		}
+
+		return output, err
+	}
+}
+
+// canonicalPartitions deduplicates and sorts the partitions, so locks are always acquired in the same order
+func canonicalPartitions(partitions []types.AttributeValue) ([]types.AttributeValue, error) {
+	if len(partitions) == 0 {
+		return nil, NewDistrLockError("at least one partition should be specified", nil)
+	}
+
+	keyed := make(map[string]types.AttributeValue, len(partitions))
+
+	for _, partition := range partitions {
+		key, err := partitionSortKey(partition)
+		if err != nil {
+			return nil, err
+		}
+
+		keyed[key] = partition
+	}
+
+	if len(keyed) > MaxMultiLockPartitions {
+		return nil, NewDistrLockError(fmt.Sprintf("unable to lock %d partitions at once, the maximum is %d", len(keyed), MaxMultiLockPartitions), nil)
+	}
+
+	keys := make([]string, 0, len(keyed))
+	for key := range keyed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make([]types.AttributeValue, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, keyed[key])
+	}
+
+	return result, nil
+}
+
+func partitionSortKey(partition types.AttributeValue) (string, error) {
+	switch p := partition.(type) {
+	case *types.AttributeValueMemberS:
+		return "S#" + p.Value, nil
+	case *types.AttributeValueMemberN:
+		return "N#" + p.Value, nil
+	case *types.AttributeValueMemberB:
+		return "B#" + hex.EncodeToString(p.Value), nil
+	default:
+		return "", NewDistrLockError(fmt.Sprintf("partition of type %T can not be used as partition key", partition), nil)
+	}
+}
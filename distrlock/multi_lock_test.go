@@ -0,0 +1,366 @@
+package distrlock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func multiLockPut(tableName string, pkName string, pk types.AttributeValue, lockId string, existingLockId string) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: &tableName,
+			Item: map[string]types.AttributeValue{
+				pkName:               pk,
+				attributeNameLockId:  &types.AttributeValueMemberS{Value: lockId},
+				attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
+			},
+			ConditionExpression:                 aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
+			ExpressionAttributeNames:            map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
+			ExpressionAttributeValues:           map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: existingLockId}},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+	}
+}
+
+func multiLockRefresh(tableName string, pkName string, pk types.AttributeValue, lockId string, existingLockId string) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:           &tableName,
+			Key:                 map[string]types.AttributeValue{pkName: pk},
+			UpdateExpression:    aws.String("SET #A0 = :a0, #A1 = :a1"),
+			ConditionExpression: aws.String("attribute_exists(#LockId) AND #LockId = :lockId"),
+			ExpressionAttributeNames: map[string]string{
+				"#LockId": attributeNameLockId,
+				"#A0":     attributeNameLockId,
+				"#A1":     attributeNameTimeout,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":lockId": &types.AttributeValueMemberS{Value: existingLockId},
+				":a0":     &types.AttributeValueMemberS{Value: lockId},
+				":a1":     &types.AttributeValueMemberN{Value: "100000000"},
+			},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+	}
+}
+
+func TestLock_TryLockMany_Success(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			multiLockPut(tableName, pkName, pkA, "UniqueID", ""),
+			multiLockPut(tableName, pkName, pkB, "UniqueID", ""),
+		},
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, success, err := handler.TryLockMany(ctx, pkB, pkA, &types.AttributeValueMemberS{Value: "B"})
+
+	// Then
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Equal(t, []types.AttributeValue{pkA, pkB}, lock.Partitions())
+	require.Len(t, lock.Locks(), 2)
+}
+
+func TestLock_TryLockMany_AlreadyLocked(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, mock.Anything).Return(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{
+				pkName:               pkB,
+				attributeNameLockId:  &types.AttributeValueMemberS{Value: "OtherLock"},
+				attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
+			}},
+		},
+	}).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, success, err := handler.TryLockMany(ctx, pkA, pkB)
+
+	// Then
+	require.NoError(t, err)
+	require.False(t, success)
+	require.Nil(t, lock)
+}
+
+func TestLock_LockMany_TakeOverExpiredLock(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			multiLockPut(tableName, pkName, pkA, "UniqueID", ""),
+			multiLockPut(tableName, pkName, pkB, "UniqueID", ""),
+		},
+	}).Return(nil, fmt.Errorf("context of error: %w", &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{
+				pkName:               pkB,
+				attributeNameLockId:  &types.AttributeValueMemberS{Value: "ExpiredLock"},
+				attributeNameTimeout: &types.AttributeValueMemberN{Value: "1000000"},
+			}},
+		},
+	})).Once()
+
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			multiLockPut(tableName, pkName, pkA, "UniqueID", ""),
+			multiLockPut(tableName, pkName, pkB, "UniqueID", "ExpiredLock"),
+		},
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100),
+		WithRefreshInterval(time.Millisecond*10),
+		WithRefreshVariance(0),
+		MockIdGenerator(t, "UniqueID"),
+	)
+
+	// When
+	lock, err := handler.LockMany(ctx, pkB, pkA)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []types.AttributeValue{pkA, pkB}, lock.Partitions())
+}
+
+func TestMultiLock_Refresh(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			multiLockRefresh(tableName, pkName, pkA, "UniqueID", "LockA"),
+			multiLockRefresh(tableName, pkName, pkB, "UniqueID", "LockB"),
+		},
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
+
+	lock := MultiLock{repository: handler, locks: []*Lock{
+		{lockId: "LockA", partition: pkA, repository: handler},
+		{lockId: "LockB", partition: pkB, repository: handler},
+	}}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "UniqueID", lock.Locks()[0].LockId())
+	require.Equal(t, "UniqueID", lock.Locks()[1].LockId())
+}
+
+func TestMultiLock_Refresh_RetryConflictingTransaction(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, mock.Anything).Return(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("TransactionConflict")}, {Code: aws.String("None")}},
+	}).Twice()
+
+	handler := New(dynamodbClient, tableName, pkName, WithConflictRetry(2, FixedWait{Interval: time.Millisecond}), MockIdGenerator(t, "UniqueID"))
+
+	lock := MultiLock{repository: handler, locks: []*Lock{
+		{lockId: "LockA", partition: pkA, repository: handler},
+		{lockId: "LockB", partition: pkB, repository: handler},
+	}}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockUpdate)
+	require.NotErrorIs(t, err, ErrLockTakenOver)
+	require.Equal(t, "LockA", lock.Locks()[0].LockId())
+}
+
+func TestMultiLock_Refresh_TakenOver(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, mock.Anything).Return(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{
+				pkName:              pkB,
+				attributeNameLockId: &types.AttributeValueMemberS{Value: "OtherLock"},
+			}},
+		},
+	}).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, MockIdGenerator(t, "UniqueID"))
+
+	lock := MultiLock{repository: handler, locks: []*Lock{
+		{lockId: "LockA", partition: pkA, repository: handler},
+		{lockId: "LockB", partition: pkB, repository: handler},
+	}}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockTakenOver)
+	require.Equal(t, "LockA", lock.Locks()[0].LockId())
+	require.Equal(t, "LockB", lock.Locks()[1].LockId())
+}
+
+func TestMultiLock_Refresh_RemovedLock(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().TransactWriteItems(ctx, mock.Anything).Return(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}, {Code: aws.String("None")}},
+	}).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, MockIdGenerator(t, "UniqueID"))
+
+	lock := MultiLock{repository: handler, locks: []*Lock{
+		{lockId: "LockA", partition: pkA, repository: handler},
+		{lockId: "LockB", partition: pkB, repository: handler},
+	}}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockNotHeld)
+	require.NotErrorIs(t, err, ErrLockTakenOver)
+}
+
+func TestMultiLock_Refresh_Released(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+
+	handler := New(dynamodbClient, tableName, pkName)
+
+	lock := MultiLock{repository: handler, locks: []*Lock{
+		{lockId: "LockA", partition: pkA, repository: handler},
+		{lockId: "LockB", partition: pkB, repository: handler, released: true},
+	}}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockNotHeld)
+}
+
+func TestMultiLock_ReleaseAndTransactionCondition(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pkA := &types.AttributeValueMemberS{Value: "A"}
+	pkB := &types.AttributeValueMemberS{Value: "B"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().DeleteItem(ctx, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Twice()
+
+	handler := New(dynamodbClient, tableName, pkName)
+
+	lock := MultiLock{repository: handler, locks: []*Lock{
+		{lockId: "LockA", partition: pkA, repository: handler},
+		{lockId: "LockB", partition: pkB, repository: handler},
+	}}
+
+	// When
+	conditions := lock.TransactionCondition()
+	err := lock.Release(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, conditions, 2)
+	require.Equal(t, map[string]types.AttributeValue{pkName: pkA}, conditions[0].ConditionCheck.Key)
+	require.Equal(t, map[string]types.AttributeValue{pkName: pkB}, conditions[1].ConditionCheck.Key)
+}
+
+func TestLock_TryLockMany_TooManyPartitions(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	partitions := make([]types.AttributeValue, 0, MaxMultiLockPartitions+1)
+	for i := 0; i <= MaxMultiLockPartitions; i++ {
+		partitions = append(partitions, &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", i)})
+	}
+
+	handler := New(mocks.NewDynamodbClient(t), "tableName", "pkName")
+
+	// When
+	lock, success, err := handler.TryLockMany(ctx, partitions...)
+
+	// Then
+	require.Error(t, err)
+	require.False(t, success)
+	require.Nil(t, lock)
+}
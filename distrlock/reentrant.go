@@ -0,0 +1,151 @@
+package distrlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const attributeNameOwnerId = "ownerId"
+const attributeNameHoldCount = "holdCount"
+
+// WithReentrantOwner Specifies that locks are reentrant for the given owner ID.
+// If a partition is locked again by the same owner, the hold count of the lock is increased instead of blocking.
+// The lock is only removed once it is released as many times as it was acquired.
+// The owner ID should be unique for each owner, e.g. a combination of hostname, process ID and worker ID.
+func WithReentrantOwner(ownerId string) func(options *Options) {
+	return func(options *Options) {
+		options.ReentrantOwnerId = &ownerId
+	}
+}
+
+// reenter increases the hold count of a lock if the partition is already locked by the same owner.
+// Like Refresh, reentering extends the lease: the lockId is rotated and the timeout and expiration are reset, so waiters restart their takeover timer.
+func (h *RepositoryLockHandler) reenter(ctx context.Context, partition types.AttributeValue) (*Lock, bool, error) {
+	expressionAttributeNames := map[string]string{"#HoldCount": attributeNameHoldCount, "#OwnerId": attributeNameOwnerId}
+	expressionAttributeValues := map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}, ":ownerId": &types.AttributeValueMemberS{Value: *h.ReentrantOwnerId}}
+
+	attributes := map[string]types.AttributeValue{
+		attributeNameLockId:  &types.AttributeValueMemberS{Value: h.IdGenerator.ID()},
+		attributeNameTimeout: &types.AttributeValueMemberN{Value: strconv.FormatInt(h.Timeout.Nanoseconds(), 10)},
+	}
+	h.expirationAttribute(attributes)
+
+	updateExpression := "ADD #HoldCount :one SET " + strings.Join(setOperations(attributes, expressionAttributeNames, expressionAttributeValues), ", ")
+
+	output, err := h.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 &h.TableName,
+		Key:                       h.key(partition),
+		UpdateExpression:          &updateExpression,
+		ConditionExpression:       aws.String("#OwnerId = :ownerId"),
+		ExpressionAttributeNames:  expressionAttributeNames,
+		ExpressionAttributeValues: expressionAttributeValues,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionalCheckFailedException *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailedException) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	info, err := parseLockInfo(partition, output.Attributes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if info == nil {
+		return nil, false, NewDistrLockError(fmt.Sprintf("reentered lock without attribute %s", attributeNameLockId), nil)
+	}
+
+	lock := &Lock{lockId: info.LockId, partition: partition, repository: h, ownerId: *h.ReentrantOwnerId}
+
+	if h.Owner != nil && info.AcquiredAt != nil {
+		lock.lease = &lease{acquiredAt: *info.AcquiredAt, acquisitionCount: info.AcquisitionCount}
+	}
+
+	return lock, true, nil
+}
+
+// releaseReentrant decreases the hold count of a reentrant lock and removes the lock if the hold count reaches zero
+func (l *Lock) releaseReentrant(ctx context.Context) error {
+	expressionAttributeNames := map[string]string{"#OwnerId": attributeNameOwnerId, "#HoldCount": attributeNameHoldCount}
+	ownerId := &types.AttributeValueMemberS{Value: l.ownerId}
+	one := &types.AttributeValueMemberN{Value: "1"}
+
+	for {
+		err := l.repository.retryConflicts(ctx, func() error {
+			_, updateErr := l.repository.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                           &l.repository.TableName,
+				Key:                                 l.key(),
+				UpdateExpression:                    aws.String("ADD #HoldCount :minusOne"),
+				ConditionExpression:                 aws.String("#OwnerId = :ownerId AND #HoldCount > :one"),
+				ExpressionAttributeNames:            expressionAttributeNames,
+				ExpressionAttributeValues:           map[string]types.AttributeValue{":ownerId": ownerId, ":one": one, ":minusOne": &types.AttributeValueMemberN{Value: "-1"}},
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			})
+
+			return updateErr
+		})
+		if err == nil {
+			return nil
+		}
+
+		held, releaseErr := l.reentrantHoldState(err)
+		if !held {
+			return releaseErr
+		}
+
+		// The lock is held only once, so it can be removed
+		err = l.repository.retryConflicts(ctx, func() error {
+			_, deleteErr := l.repository.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName:                           &l.repository.TableName,
+				Key:                                 l.key(),
+				ConditionExpression:                 aws.String("#OwnerId = :ownerId AND #HoldCount <= :one"),
+				ExpressionAttributeNames:            expressionAttributeNames,
+				ExpressionAttributeValues:           map[string]types.AttributeValue{":ownerId": ownerId, ":one": one},
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			})
+
+			return deleteErr
+		})
+		if err == nil {
+			return nil
+		}
+
+		held, releaseErr = l.reentrantHoldState(err)
+		if !held {
+			return releaseErr
+		}
+
+		// The hold count was increased concurrently by the same owner, so the hold count is decreased instead
+	}
+}
+
+// reentrantHoldState interprets a failed release request. True is returned if the lock is still held by the owner.
+// Otherwise, the error to return from Release is returned: nil if the lock was already removed and ErrLockTakenOver if the lock is held by another owner.
+func (l *Lock) reentrantHoldState(err error) (bool, error) {
+	conditionalCheckFailedException := conditionalCheckFailure(err)
+	if conditionalCheckFailedException == nil {
+		return false, err
+	}
+
+	if conditionalCheckFailedException.Item == nil {
+		return false, nil
+	}
+
+	currentOwner, found := conditionalCheckFailedException.Item[attributeNameOwnerId].(*types.AttributeValueMemberS)
+	if !found || currentOwner.Value != l.ownerId {
+		return false, fmt.Errorf("%w: %w", ErrLockTakenOver, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,291 @@
+package distrlock
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func reenterInput(tableName string, key map[string]types.AttributeValue, ownerId string, lockId string, timeout time.Duration) *dynamodb.UpdateItemInput {
+	return &dynamodb.UpdateItemInput{
+		TableName:                &tableName,
+		Key:                      key,
+		UpdateExpression:         aws.String("ADD #HoldCount :one SET #A0 = :a0, #A1 = :a1"),
+		ConditionExpression:      aws.String("#OwnerId = :ownerId"),
+		ExpressionAttributeNames: map[string]string{"#HoldCount": attributeNameHoldCount, "#OwnerId": attributeNameOwnerId, "#A0": attributeNameLockId, "#A1": attributeNameTimeout},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":     &types.AttributeValueMemberN{Value: "1"},
+			":ownerId": &types.AttributeValueMemberS{Value: ownerId},
+			":a0":      &types.AttributeValueMemberS{Value: lockId},
+			":a1":      &types.AttributeValueMemberN{Value: strconv.FormatInt(timeout.Nanoseconds(), 10)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	}
+}
+
+func TestLock_TryLock_Reentrant_FirstAcquisition(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, reenterInput(tableName, map[string]types.AttributeValue{pkName: pk}, "owner", "UniqueID", time.Millisecond*100)).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+	dynamodbClient.EXPECT().PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &tableName,
+		Item: map[string]types.AttributeValue{
+			pkName:                 pk,
+			attributeNameLockId:    &types.AttributeValueMemberS{Value: "UniqueID"},
+			attributeNameTimeout:   &types.AttributeValueMemberN{Value: "100000000"},
+			attributeNameOwnerId:   &types.AttributeValueMemberS{Value: "owner"},
+			attributeNameHoldCount: &types.AttributeValueMemberN{Value: "1"},
+		},
+		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
+		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: ""}},
+	}).Return(nil, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), WithReentrantOwner("owner"), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, success, err := handler.TryLock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Equal(t, &Lock{
+		lockId:     "UniqueID",
+		partition:  pk,
+		repository: handler,
+		ownerId:    "owner",
+	}, lock)
+}
+
+func TestLock_Lock_Reentrant_SameOwner(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, reenterInput(tableName, map[string]types.AttributeValue{pkName: pk}, "owner", "UniqueID", time.Millisecond*100)).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]types.AttributeValue{
+			pkName:                 pk,
+			attributeNameLockId:    &types.AttributeValueMemberS{Value: "UniqueID"},
+			attributeNameTimeout:   &types.AttributeValueMemberN{Value: "100000000"},
+			attributeNameOwnerId:   &types.AttributeValueMemberS{Value: "owner"},
+			attributeNameHoldCount: &types.AttributeValueMemberN{Value: "2"},
+		},
+	}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), WithReentrantOwner("owner"), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, &Lock{
+		lockId:     "UniqueID",
+		partition:  pk,
+		repository: handler,
+		ownerId:    "owner",
+	}, lock)
+}
+
+func TestLock_Lock_Reentrant_SameOwner_WithTTL(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.UpdateExpression == "ADD #HoldCount :one SET #A0 = :a0, #A1 = :a1, #A2 = :a2" &&
+			input.ExpressionAttributeNames["#A0"] == "expiresAt" &&
+			isExpiration(input.ExpressionAttributeValues[":a0"], time.Hour) &&
+			input.ExpressionAttributeNames["#A1"] == attributeNameLockId &&
+			input.ExpressionAttributeNames["#A2"] == attributeNameTimeout
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]types.AttributeValue{
+			pkName:                 pk,
+			attributeNameLockId:    &types.AttributeValueMemberS{Value: "UniqueID"},
+			attributeNameTimeout:   &types.AttributeValueMemberN{Value: "100000000"},
+			attributeNameOwnerId:   &types.AttributeValueMemberS{Value: "owner"},
+			attributeNameHoldCount: &types.AttributeValueMemberN{Value: "2"},
+		},
+	}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), WithReentrantOwner("owner"), WithTTL("expiresAt", time.Hour), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	lock, err := handler.Lock(ctx, pk)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "UniqueID", lock.LockId())
+}
+
+func TestLock_Release_Reentrant_DecreaseHoldCount(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                &tableName,
+		Key:                      map[string]types.AttributeValue{pkName: pk},
+		UpdateExpression:         aws.String("ADD #HoldCount :minusOne"),
+		ConditionExpression:      aws.String("#OwnerId = :ownerId AND #HoldCount > :one"),
+		ExpressionAttributeNames: map[string]string{"#OwnerId": attributeNameOwnerId, "#HoldCount": attributeNameHoldCount},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ownerId":  &types.AttributeValueMemberS{Value: "owner"},
+			":one":      &types.AttributeValueMemberN{Value: "1"},
+			":minusOne": &types.AttributeValueMemberN{Value: "-1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithReentrantOwner("owner"))
+
+	lock := Lock{lockId: "LockID", partition: pk, repository: handler, ownerId: "owner"}
+
+	// When
+	err := lock.Release(ctx)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestLock_Release_Reentrant_RemoveLastHold(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                &tableName,
+		Key:                      map[string]types.AttributeValue{pkName: pk},
+		UpdateExpression:         aws.String("ADD #HoldCount :minusOne"),
+		ConditionExpression:      aws.String("#OwnerId = :ownerId AND #HoldCount > :one"),
+		ExpressionAttributeNames: map[string]string{"#OwnerId": attributeNameOwnerId, "#HoldCount": attributeNameHoldCount},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ownerId":  &types.AttributeValueMemberS{Value: "owner"},
+			":one":      &types.AttributeValueMemberN{Value: "1"},
+			":minusOne": &types.AttributeValueMemberN{Value: "-1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		pkName:                 pk,
+		attributeNameOwnerId:   &types.AttributeValueMemberS{Value: "owner"},
+		attributeNameHoldCount: &types.AttributeValueMemberN{Value: "1"},
+	}}).Once()
+
+	dynamodbClient.EXPECT().DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                &tableName,
+		Key:                      map[string]types.AttributeValue{pkName: pk},
+		ConditionExpression:      aws.String("#OwnerId = :ownerId AND #HoldCount <= :one"),
+		ExpressionAttributeNames: map[string]string{"#OwnerId": attributeNameOwnerId, "#HoldCount": attributeNameHoldCount},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ownerId": &types.AttributeValueMemberS{Value: "owner"},
+			":one":     &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithReentrantOwner("owner"))
+
+	lock := Lock{lockId: "LockID", partition: pk, repository: handler, ownerId: "owner"}
+
+	// When
+	err := lock.Release(ctx)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestLock_Refresh_Reentrant(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableName := "tableName"
+	pkName := "pkName"
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                &tableName,
+		Key:                      map[string]types.AttributeValue{pkName: pk},
+		UpdateExpression:         aws.String("SET #A0 = :a0, #A1 = :a1"),
+		ConditionExpression:      aws.String("#OwnerId = :ownerId"),
+		ExpressionAttributeNames: map[string]string{"#OwnerId": attributeNameOwnerId, "#A0": attributeNameLockId, "#A1": attributeNameTimeout},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ownerId": &types.AttributeValueMemberS{Value: "owner"},
+			":a0":      &types.AttributeValueMemberS{Value: "UniqueID"},
+			":a1":      &types.AttributeValueMemberN{Value: "100000000"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), WithReentrantOwner("owner"), MockIdGenerator(t, "UniqueID"))
+
+	lock := Lock{lockId: "LockID", partition: pk, repository: handler, ownerId: "owner"}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "UniqueID", lock.LockId())
+}
+
+func TestLock_TransactionCondition_Reentrant(t *testing.T) {
+	// Given
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+		ownerId:    "owner",
+	}
+
+	// When
+	condition := lock.TransactionCondition()
+
+	// Then
+	require.Equal(t, types.TransactWriteItem{
+		ConditionCheck: &types.ConditionCheck{
+			TableName:                           aws.String("DynamoDbTable"),
+			Key:                                 map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "Some PK"}},
+			ConditionExpression:                 aws.String("#OwnerId = :ownerId"),
+			ExpressionAttributeNames:            map[string]string{"#OwnerId": attributeNameOwnerId},
+			ExpressionAttributeValues:           map[string]types.AttributeValue{":ownerId": &types.AttributeValueMemberS{Value: "owner"}},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+		},
+	}, condition)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -19,6 +21,12 @@ var SkString = &types.AttributeValueMemberS{Value: "##LOCK##"}
 
 const attributeNameLockId = "lockId"
 const attributeNameTimeout = "timeout"
+const attributeNameOwnerHostname = "ownerHostname"
+const attributeNameOwnerProcessId = "ownerProcessId"
+const attributeNameOwnerLabel = "ownerLabel"
+const attributeNameAcquiredAt = "acquiredAt"
+const attributeNameRefreshedAt = "refreshedAt"
+const attributeNameAcquisitionCount = "acquisitionCount"
 
 // Interface validation check
 var _ DynamodbClient = (*dynamodb.Client)(nil)
@@ -28,6 +36,10 @@ type DynamodbClient interface {
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.PutItemOutput, error)
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.ScanOutput, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2 --name=IdGenerator --with-expecter
@@ -47,6 +59,19 @@ type RepositoryLockHandler struct {
 	RefreshInterval  time.Duration
 	RefreshVariance  time.Duration
 	IdGenerator      IdGenerator
+	Owner            *OwnerMetadata
+	ListIndexName    *string
+	FairQueue        bool
+	TicketTimeout    time.Duration
+	ReentrantOwnerId *string
+	WaitStrategy     WaitStrategy
+	Hooks            *Hooks
+
+	ConflictRetryAttempts int
+	ConflictRetryWait     WaitStrategy
+
+	TTLAttributeName *string
+	TTL              time.Duration
 }
 
 type Options struct {
@@ -67,6 +92,36 @@ type Options struct {
 
 	// IdGenerator a generator of unique IDs
 	IdGenerator IdGenerator
+
+	// Owner metadata stored in the lock item. If nil, no owner metadata is stored
+	Owner *OwnerMetadata
+
+	// ListIndexName global secondary index with the sort key as partition key, used to list all locks
+	ListIndexName *string
+
+	// TicketTimeout if not nil, fair queueing is enabled. Tickets that are not refreshed within the timeout are considered abandoned
+	TicketTimeout *time.Duration
+
+	// ReentrantOwnerId if not nil, locks are reentrant for the given owner ID
+	ReentrantOwnerId *string
+
+	// WaitStrategy strategy to determine the time between two lock poll requests. If nil, the refresh interval and variance are used
+	WaitStrategy WaitStrategy
+
+	// Hooks callbacks that are called while acquiring a lock
+	Hooks *Hooks
+
+	// ConflictRetryAttempts maximum number of attempts of Release and Refresh if the request conflicts with an ongoing transaction
+	ConflictRetryAttempts *int
+
+	// ConflictRetryWait strategy to determine the time between two attempts of Release and Refresh
+	ConflictRetryWait WaitStrategy
+
+	// TTLAttributeName if not nil, lock items store an expiration time in this attribute, so abandoned locks are removed by DynamoDB TTL
+	TTLAttributeName *string
+
+	// TTL time after the last refresh after which a lock item expires
+	TTL *time.Duration
 }
 
 // New create a new initialized distributed lock.
@@ -85,6 +140,9 @@ func New(client DynamodbClient, tableName string, partitionKeyName string, optFn
 		Timeout:          time.Second,
 		RefreshInterval:  time.Millisecond * 200,
 		RefreshVariance:  time.Millisecond * 20,
+
+		ConflictRetryAttempts: defaultConflictRetryAttempts,
+		ConflictRetryWait:     defaultConflictRetryWait,
 	}
 
 	if options.SortKeyName != nil {
@@ -107,6 +165,44 @@ func New(client DynamodbClient, tableName string, partitionKeyName string, optFn
 		repositoryLock.RefreshVariance = *options.RefreshVariance
 	}
 
+	if options.Owner != nil {
+		repositoryLock.Owner = options.Owner
+	}
+
+	if options.ListIndexName != nil {
+		repositoryLock.ListIndexName = options.ListIndexName
+	}
+
+	if options.TicketTimeout != nil {
+		repositoryLock.FairQueue = true
+		repositoryLock.TicketTimeout = *options.TicketTimeout
+	}
+
+	if options.ReentrantOwnerId != nil {
+		repositoryLock.ReentrantOwnerId = options.ReentrantOwnerId
+	}
+
+	if options.WaitStrategy != nil {
+		repositoryLock.WaitStrategy = options.WaitStrategy
+	}
+
+	if options.Hooks != nil {
+		repositoryLock.Hooks = options.Hooks
+	}
+
+	if options.ConflictRetryAttempts != nil {
+		repositoryLock.ConflictRetryAttempts = *options.ConflictRetryAttempts
+	}
+
+	if options.ConflictRetryWait != nil {
+		repositoryLock.ConflictRetryWait = options.ConflictRetryWait
+	}
+
+	if options.TTLAttributeName != nil {
+		repositoryLock.TTLAttributeName = options.TTLAttributeName
+		repositoryLock.TTL = *options.TTL
+	}
+
 	if options.IdGenerator != nil {
 		repositoryLock.IdGenerator = options.IdGenerator
 	} else {
@@ -154,69 +250,266 @@ func WithRefreshVariance(refreshVariance time.Duration) func(options *Options) {
 	}
 }
 
+// WithOwnerMetadata Specifies that owner metadata should be stored in the lock item.
+// The hostname and process ID are detected automatically. The label can be used to identify the caller holding the lock.
+func WithOwnerMetadata(label string) func(options *Options) {
+	return func(options *Options) {
+		owner := NewOwnerMetadata(label)
+		options.Owner = &owner
+	}
+}
+
+// WithListIndex Specifies a global secondary index that has the sort key as partition key.
+// If specified, List will query the index instead of scanning the full table.
+func WithListIndex(indexName string) func(options *Options) {
+	return func(options *Options) {
+		options.ListIndexName = &indexName
+	}
+}
+
+// WithFairQueue Specifies that waiters should acquire the lock in arrival order.
+// Each waiter enqueues a ticket item in the partition. The arrival order is taken from a counter item in the partition, so it does not depend on the clocks of the waiters.
+// Tickets are refreshed while waiting and expire if not refreshed within ticketTimeout. Ticket expiration uses the clock of the waiter, so clocks of waiters may differ by up to ticketTimeout.
+// Fair queueing requires a table with a sort key of type string.
+func WithFairQueue(ticketTimeout time.Duration) func(options *Options) {
+	return func(options *Options) {
+		options.TicketTimeout = &ticketTimeout
+	}
+}
+
 type Lock struct {
 	repository *RepositoryLockHandler
 	partition  types.AttributeValue
 	lockId     string
+	lease      *lease
+	ownerId    string
+	released   bool
+
+	// writes number of lock-conditioned writes in flight. Refresh waits until no writes are in flight, so it does not invalidate their conditions
+	writes     int
+	writesDone chan struct{}
+
+	// mutex guards lockId, lease, released and writes, as a lock can be refreshed in the background while it is used
+	mutex sync.Mutex
+}
+
+// lease keeps track of the acquisition of a lock if owner metadata is stored
+type lease struct {
+	acquiredAt       time.Time
+	acquisitionCount int64
 }
 
 // TryLock tries to lock a specified partition.
 // If the handler was able to lock the partition a new lock will be returned. Additionally, the second return argument will be true
 // If the handler was unable to lock the partition nil and false is returned as first arguments.
+// If fair queueing is enabled, the lock will not be acquired as long as other waiters are queued.
+// If reentrant locking is enabled and the partition is already locked by the same owner, the hold count of the lock is increased.
 func (h *RepositoryLockHandler) TryLock(ctx context.Context, partition types.AttributeValue) (*Lock, bool, error) {
-	lock, success, err := h.lock(ctx, partition, "")
+	if h.ReentrantOwnerId != nil {
+		lock, reentered, err := h.reenter(ctx, partition)
+		if err != nil || reentered {
+			return lock, reentered, err
+		}
+	}
+
+	if h.FairQueue {
+		return h.fairTryLock(ctx, partition)
+	}
+
+	lock, success, err := h.lock(ctx, partition, "", nil)
 	return lock, success, err
 }
 
 // Lock tries to lock a specified partition.
 // The method will return a new lock once it is able to create a lock.
-// If it was unable to create a new lock it will try after a RefreshInterval.
+// If it was unable to create a new lock it will try again after a delay determined by the WaitStrategy.
+// If fair queueing is enabled, waiters will acquire the lock in arrival order.
+// If reentrant locking is enabled and the partition is already locked by the same owner, the hold count of the lock is increased.
 // Polling will stop if the context is Done.
 func (h *RepositoryLockHandler) Lock(ctx context.Context, partition types.AttributeValue) (*Lock, error) {
-	currentLockId := ""
-	timeoutLock := ""
-	var currentLocktimeout time.Time
+	if h.ReentrantOwnerId != nil {
+		lock, reentered, err := h.reenter(ctx, partition)
+		if err != nil {
+			return nil, err
+		}
+
+		if reentered {
+			return lock, nil
+		}
+	}
+
+	if h.FairQueue {
+		return h.fairLock(ctx, partition)
+	}
+
+	attempt := newLockAttempt(h, partition)
 
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, ErrTimeout
+			return nil, attempt.waiter.timeout()
 		default:
-			if currentLocktimeout.Before(time.Now()) {
-				timeoutLock = currentLockId
-			}
-
-			lock, success, err := h.lock(ctx, partition, timeoutLock)
+			lock, err := attempt.try(ctx)
 			if err != nil {
 				return nil, err
 			}
 
-			if success {
+			if lock != nil {
 				return lock, nil
 			}
 
-			existingLockId, leaseDuration, err := h.lockLookup(ctx, partition)
-			if err != nil {
-				return nil, err
-			}
+			attempt.waiter.wait(ctx)
+		}
+	}
+}
 
-			if existingLockId != nil && *existingLockId != currentLockId {
-				currentLockId = *existingLockId
-				currentLocktimeout = time.Now().Add(*leaseDuration)
-			}
+// lockAttempt keeps track of the lock currently holding a partition while waiting to acquire it.
+// The existing lock is taken over if its lockId did not change within its timeout.
+type lockAttempt struct {
+	handler   *RepositoryLockHandler
+	partition types.AttributeValue
+
+	waiter *lockWaiter
+
+	currentLockId      string
+	currentLockTimeout time.Time
+	timeoutLock        string
+}
+
+func newLockAttempt(handler *RepositoryLockHandler, partition types.AttributeValue) *lockAttempt {
+	return &lockAttempt{handler: handler, partition: partition, waiter: newLockWaiter(handler, partition)}
+}
+
+// try executes a single attempt to acquire the lock. Nil is returned if the lock could not be acquired.
+func (a *lockAttempt) try(ctx context.Context) (*Lock, error) {
+	expectedLockId := a.expectedLockId()
+
+	lock, holder, err := a.handler.lockOrHolder(ctx, a.partition, expectedLockId)
+	if err != nil {
+		return nil, err
+	}
+
+	a.waiter.attempted()
+
+	if lock != nil {
+		if expectedLockId != "" {
+			a.waiter.takeover(expectedLockId)
+		}
+
+		a.waiter.acquired()
 
-			sleepContext(ctx, h.RefreshInterval, h.RefreshVariance)
+		return lock, nil
+	}
+
+	if holder != nil {
+		a.waiter.contention(holder.LockId)
+		a.observe(&holder.LockId, &holder.Timeout)
+	}
+
+	return nil, nil
+}
+
+// expectedLockId returns the lockId of the existing lock that may be taken over, or an empty string if no lock can be taken over
+func (a *lockAttempt) expectedLockId() string {
+	if a.currentLockTimeout.Before(time.Now()) {
+		a.timeoutLock = a.currentLockId
+	}
+
+	return a.timeoutLock
+}
+
+// observe keeps track of the existing lock. The timeout restarts every time the lockId of the existing lock changes.
+func (a *lockAttempt) observe(existingLockId *string, leaseDuration *time.Duration) {
+	if existingLockId != nil && *existingLockId != a.currentLockId {
+		a.currentLockId = *existingLockId
+		a.currentLockTimeout = time.Now().Add(*leaseDuration)
+	}
+}
+
+func (h *RepositoryLockHandler) lock(ctx context.Context, partition types.AttributeValue, existingLockId string, previousLease *lease) (*Lock, bool, error) {
+	lock, err := h.putLock(ctx, partition, existingLockId, previousLease)
+	if err != nil {
+		if conditionalCheckFailure(err) != nil || isTransactionConflict(err) {
+			return nil, false, nil
 		}
+
+		return nil, false, err
+	}
+
+	return lock, true, nil
+}
+
+// putLock writes a new lock item. The error of the PutItem call is returned if the lock could not be written.
+func (h *RepositoryLockHandler) putLock(ctx context.Context, partition types.AttributeValue, existingLockId string, previousLease *lease) (*Lock, error) {
+	input, lock := h.putLockInput(partition, existingLockId, previousLease)
+
+	_, err := h.Client.PutItem(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// lockOrHolder writes a new lock item. If the partition is locked by another holder, the current holder is returned instead.
+// The holder is read from the failed conditional write, so no additional read is needed. Both are nil if the write conflicted with an ongoing transaction.
+func (h *RepositoryLockHandler) lockOrHolder(ctx context.Context, partition types.AttributeValue, existingLockId string) (*Lock, *LockInfo, error) {
+	input, lock := h.putLockInput(partition, existingLockId, nil)
+	input.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+
+	_, err := h.Client.PutItem(ctx, input)
+	if err == nil {
+		return lock, nil, nil
+	}
+
+	if isTransactionConflict(err) {
+		return nil, nil, nil
+	}
+
+	conditionalCheckFailedException := conditionalCheckFailure(err)
+	if conditionalCheckFailedException == nil {
+		return nil, nil, err
+	}
+
+	holder, err := parseLockInfo(partition, conditionalCheckFailedException.Item)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	return nil, holder, nil
 }
 
-func (h *RepositoryLockHandler) lock(ctx context.Context, partition types.AttributeValue, existingLockId string) (*Lock, bool, error) {
+// putLockInput returns the PutItem input to write a new lock item and the lock that is held once the item is written
+func (h *RepositoryLockHandler) putLockInput(partition types.AttributeValue, existingLockId string, previousLease *lease) (*dynamodb.PutItemInput, *Lock) {
 	generatedId := h.IdGenerator.ID()
 
 	item := h.key(partition)
 	item[attributeNameLockId] = &types.AttributeValueMemberS{Value: generatedId}
 	item[attributeNameTimeout] = &types.AttributeValueMemberN{Value: strconv.FormatInt(h.Timeout.Nanoseconds(), 10)}
 
+	newLease := h.ownerAttributes(item, previousLease)
+	h.expirationAttribute(item)
+
+	ownerId := ""
+	if h.ReentrantOwnerId != nil {
+		ownerId = *h.ReentrantOwnerId
+		item[attributeNameOwnerId] = &types.AttributeValueMemberS{Value: ownerId}
+		item[attributeNameHoldCount] = &types.AttributeValueMemberN{Value: "1"}
+	}
+
+	conditionExpression, expressionAttributeNames, expressionAttributeValues := h.putCondition(existingLockId)
+
+	return &dynamodb.PutItemInput{
+		Item:                      item,
+		TableName:                 &h.TableName,
+		ConditionExpression:       &conditionExpression,
+		ExpressionAttributeNames:  expressionAttributeNames,
+		ExpressionAttributeValues: expressionAttributeValues,
+	}, &Lock{lockId: generatedId, partition: partition, repository: h, lease: newLease, ownerId: ownerId}
+}
+
+// putCondition returns the condition expression to create a new lock or to take over the lock with the given existingLockId
+func (h *RepositoryLockHandler) putCondition(existingLockId string) (string, map[string]string, map[string]types.AttributeValue) {
 	var conditionExpression string
 	expressionAttributeNames := map[string]string{"#LockID": attributeNameLockId}
 	expressionAttributeValues := map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: existingLockId}}
@@ -229,29 +522,31 @@ func (h *RepositoryLockHandler) lock(ctx context.Context, partition types.Attrib
 		expressionAttributeNames["#PK"] = h.PartitionKeyName
 	}
 
-	_, err := h.Client.PutItem(ctx, &dynamodb.PutItemInput{
-		Item:                      item,
-		TableName:                 &h.TableName,
-		ConditionExpression:       &conditionExpression,
-		ExpressionAttributeNames:  expressionAttributeNames,
-		ExpressionAttributeValues: expressionAttributeValues,
-	})
+	return conditionExpression, expressionAttributeNames, expressionAttributeValues
+}
 
-	if err != nil {
-		var conditionalCheckFailedException *types.ConditionalCheckFailedException
-		if errors.As(err, &conditionalCheckFailedException) {
-			return nil, false, nil
-		}
+// ownerAttributes adds the owner metadata to the lock item if configured and returns the resulting lease
+func (h *RepositoryLockHandler) ownerAttributes(item map[string]types.AttributeValue, previousLease *lease) *lease {
+	if h.Owner == nil {
+		return nil
+	}
 
-		var transactionConflictException *types.TransactionConflictException
-		if errors.As(err, &transactionConflictException) {
-			return nil, false, nil
-		}
+	now := time.Now()
 
-		return nil, false, err
+	newLease := &lease{acquiredAt: now, acquisitionCount: 1}
+	if previousLease != nil {
+		newLease.acquiredAt = previousLease.acquiredAt
+		newLease.acquisitionCount = previousLease.acquisitionCount + 1
 	}
 
-	return &Lock{lockId: generatedId, partition: partition, repository: h}, true, nil
+	item[attributeNameOwnerHostname] = &types.AttributeValueMemberS{Value: h.Owner.Hostname}
+	item[attributeNameOwnerProcessId] = &types.AttributeValueMemberN{Value: strconv.Itoa(h.Owner.ProcessId)}
+	item[attributeNameOwnerLabel] = &types.AttributeValueMemberS{Value: h.Owner.Label}
+	item[attributeNameAcquiredAt] = &types.AttributeValueMemberS{Value: newLease.acquiredAt.UTC().Format(time.RFC3339Nano)}
+	item[attributeNameRefreshedAt] = &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339Nano)}
+	item[attributeNameAcquisitionCount] = &types.AttributeValueMemberN{Value: strconv.FormatInt(newLease.acquisitionCount, 10)}
+
+	return newLease
 }
 
 func (h *RepositoryLockHandler) lockLookup(ctx context.Context, partition types.AttributeValue) (*string, *time.Duration, error) {
@@ -266,25 +561,12 @@ func (h *RepositoryLockHandler) lockLookup(ctx context.Context, partition types.
 	}
 
 	if getItemResult != nil {
-		lockKeyAttribute, found := getItemResult.Item[attributeNameLockId]
-		if !found {
-			// If no lockId is found, we assume that the lock is not active anymore
-			return nil, nil, nil
-		}
-
-		lockKey, ok := lockKeyAttribute.(*types.AttributeValueMemberS)
-		if !ok {
-			return nil, nil, NewDistrLockError(fmt.Sprintf("attribute %s not of expected type AttributeValueMemberS but was %T", attributeNameLockId, lockKeyAttribute), nil)
-		}
-
-		timeoutNs, err := strconv.ParseInt(getItemResult.Item[attributeNameTimeout].(*types.AttributeValueMemberN).Value, 10, 64)
-		if err != nil {
+		info, err := parseLockInfo(partition, getItemResult.Item)
+		if err != nil || info == nil {
 			return nil, nil, err
 		}
 
-		timeout := time.Duration(timeoutNs) * time.Nanosecond
-
-		return &lockKey.Value, &timeout, nil
+		return &info.LockId, &info.Timeout, nil
 	}
 
 	return nil, nil, nil
@@ -308,43 +590,99 @@ func (h *RepositoryLockHandler) hasSortKey() bool {
 
 // LockId returns the current id used by the lock
 func (l *Lock) LockId() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
 	return l.lockId
 }
 
 // Release remove the lock in the database
+// If the lock is reentrant, the hold count is decreased and the lock is only removed once the hold count reaches zero.
+// Release is idempotent: releasing a lock that was already released or removed returns nil.
+// ErrLockTakenOver is returned if the lock is held by another holder.
 func (l *Lock) Release(ctx context.Context) error {
-	for {
-		_, err := l.repository.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-			TableName:                 &l.repository.TableName,
-			Key:                       l.key(),
-			ConditionExpression:       aws.String("#LockId = :lockId"),
-			ExpressionAttributeNames:  map[string]string{"#LockId": attributeNameLockId},
-			ExpressionAttributeValues: map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: l.lockId}},
-		})
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 
-		if err != nil {
-			if errors.Is(err, &types.TransactionConflictException{}) {
-				sleepContext(ctx, time.Millisecond*15, time.Millisecond*10)
+	if l.released {
+		return nil
+	}
 
-				continue
-			}
+	var err error
+
+	if l.ownerId != "" {
+		err = l.releaseReentrant(ctx)
+	} else {
+		err = l.release(ctx)
+	}
+
+	if err != nil && !errors.Is(err, ErrLockTakenOver) {
+		return err
+	}
+
+	l.released = true
+
+	return err
+}
+
+func (l *Lock) release(ctx context.Context) error {
+	err := l.repository.retryConflicts(ctx, func() error {
+		_, deleteErr := l.repository.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName:                           &l.repository.TableName,
+			Key:                                 l.key(),
+			ConditionExpression:                 aws.String("#LockId = :lockId"),
+			ExpressionAttributeNames:            map[string]string{"#LockId": attributeNameLockId},
+			ExpressionAttributeValues:           map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: l.lockId}},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		})
+
+		return deleteErr
+	})
 
-			return err
+	if conditionalCheckFailedException := conditionalCheckFailure(err); conditionalCheckFailedException != nil {
+		if conditionalCheckFailedException.Item == nil {
+			// The lock was already removed
+			return nil
 		}
 
-		return nil
+		return fmt.Errorf("%w: %w", ErrLockTakenOver, err)
 	}
+
+	return err
 }
 
 // TransactionCondition returns a TransactWriteItem to validate if the lock is still active
+// If the lock is refreshed in the background, e.g. by Do, a refresh between building and executing the transaction fails the condition. Use WithTransactionCondition instead.
 func (l *Lock) TransactionCondition() types.TransactWriteItem {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.transactionCondition()
+}
+
+// WithTransactionCondition calls fn with a TransactWriteItem to validate if the lock is still active.
+// The lock is not refreshed while fn is running, so the condition stays valid if the lock is refreshed in the background, e.g. by Do.
+func (l *Lock) WithTransactionCondition(fn func(condition types.TransactWriteItem) error) error {
+	l.mutex.Lock()
+	condition := l.transactionCondition()
+	l.beginWrite()
+	l.mutex.Unlock()
+
+	defer l.endWrite()
+
+	return fn(condition)
+}
+
+func (l *Lock) transactionCondition() types.TransactWriteItem {
+	conditionExpression, expressionAttributeNames, expressionAttributeValues := l.condition()
+
 	return types.TransactWriteItem{
 		ConditionCheck: &types.ConditionCheck{
 			TableName:                           &l.repository.TableName,
 			Key:                                 l.key(),
-			ConditionExpression:                 aws.String("#LockId = :lockId"),
-			ExpressionAttributeNames:            map[string]string{"#LockId": attributeNameLockId},
-			ExpressionAttributeValues:           map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: l.lockId}},
+			ConditionExpression:                 &conditionExpression,
+			ExpressionAttributeNames:            expressionAttributeNames,
+			ExpressionAttributeValues:           expressionAttributeValues,
 			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
 		},
 	}
@@ -352,58 +690,209 @@ func (l *Lock) TransactionCondition() types.TransactWriteItem {
 
 // TransactionWithRefresh returns a TransactWriteItem to validate if the lock is still active and refresh the lock if successful
 // Note the callback function returned as second argument should be called with the return types of the TransactWriteItems call
+// The lock is not refreshed until the callback is called, so the condition stays valid if the lock is refreshed in the background, e.g. by Do.
 func (l *Lock) TransactionWithRefresh() (types.TransactWriteItem, func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error)) {
 	generatedId := l.repository.IdGenerator.ID()
 
+	l.mutex.Lock()
+	conditionExpression, expressionAttributeNames, expressionAttributeValues := l.condition()
+	previousLease := l.lease
+	l.beginWrite()
+	l.mutex.Unlock()
+
+	attributes := map[string]types.AttributeValue{}
+	newLease := l.repository.ownerAttributes(attributes, previousLease)
+	l.repository.expirationAttribute(attributes)
+
+	expressionAttributeNames["#LockId"] = attributeNameLockId
+	expressionAttributeValues[":newLockId"] = &types.AttributeValueMemberS{Value: generatedId}
+
+	operations := append([]string{"#LockId = :newLockId"}, setOperations(attributes, expressionAttributeNames, expressionAttributeValues)...)
+
+	var callbackOnce sync.Once
+
 	return types.TransactWriteItem{
 			Update: &types.Update{
 				TableName:                           &l.repository.TableName,
 				Key:                                 l.key(),
-				ConditionExpression:                 aws.String("#LockId = :lockId"),
-				UpdateExpression:                    aws.String("SET #LockId = :newLockId"),
-				ExpressionAttributeNames:            map[string]string{"#LockId": attributeNameLockId},
-				ExpressionAttributeValues:           map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: l.lockId}, ":newLockId": &types.AttributeValueMemberS{Value: generatedId}},
+				ConditionExpression:                 &conditionExpression,
+				UpdateExpression:                    aws.String("SET " + strings.Join(operations, ", ")),
+				ExpressionAttributeNames:            expressionAttributeNames,
+				ExpressionAttributeValues:           expressionAttributeValues,
 				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
 			},
 		}, func(output *dynamodb.TransactWriteItemsOutput, err error) (*dynamodb.TransactWriteItemsOutput, error) {
-			if err == nil {
-				l.lockId = generatedId
-			}
+			callbackOnce.Do(func() {
+				if err == nil {
+					l.mutex.Lock()
+					l.lockId = generatedId
+					l.lease = newLease
+					l.mutex.Unlock()
+				}
+
+				l.endWrite()
+			})
 
 			return output, err
 		}
 }
 
+// beginWrite marks a lock-conditioned write as in flight, so refreshes wait until it is finished. The mutex should be held by the caller.
+func (l *Lock) beginWrite() {
+	if l.writes == 0 {
+		l.writesDone = make(chan struct{})
+	}
+
+	l.writes++
+}
+
+// endWrite marks a lock-conditioned write as finished, so waiting refreshes can continue
+func (l *Lock) endWrite() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.writes--
+
+	if l.writes == 0 {
+		close(l.writesDone)
+		l.writesDone = nil
+	}
+}
+
+// waitForWrites waits until no lock-conditioned writes are in flight or the context is Done. The mutex should be held by the caller.
+// The mutex is released while waiting, so writes can finish.
+func (l *Lock) waitForWrites(ctx context.Context) error {
+	for l.writes > 0 {
+		writesDone := l.writesDone
+
+		l.mutex.Unlock()
+
+		select {
+		case <-writesDone:
+		case <-ctx.Done():
+			l.mutex.Lock()
+
+			return ctx.Err()
+		}
+
+		l.mutex.Lock()
+	}
+
+	return nil
+}
+
 // Refresh updates the timeout of the current active lock
+// Refresh waits until the lock-conditioned writes of WithTransactionCondition and TransactionWithRefresh are finished, as refreshing the lock invalidates their conditions.
+// If the context is Done while waiting, the context error is returned.
+// ErrLockTakenOver is returned if the lock is held by another holder and ErrLockNotHeld if the lock was released.
 func (l *Lock) Refresh(ctx context.Context) error {
-	newLock, success, err := l.repository.lock(ctx, l.partition, l.lockId)
-	if err != nil {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.waitForWrites(ctx); err != nil {
 		return err
 	}
 
-	if !success {
-		return ErrLockUpdate
+	if l.released {
+		return ErrLockNotHeld
+	}
+
+	conditionExpression, expressionAttributeNames, expressionAttributeValues := l.condition()
+
+	if l.ownerId == "" {
+		conditionExpression = "attribute_exists(#LockId) AND " + conditionExpression
+	}
+
+	return l.refresh(ctx, conditionExpression, expressionAttributeNames, expressionAttributeValues)
+}
+
+// refresh updates the lock item with a new lockId and timeout if the condition is met.
+// The hold count of a reentrant lock is not modified. The lock item is never created, so a released lock is not acquired again.
+func (l *Lock) refresh(ctx context.Context, conditionExpression string, expressionAttributeNames map[string]string, expressionAttributeValues map[string]types.AttributeValue) error {
+	updateExpression, generatedId, newLease := l.refreshExpression(expressionAttributeNames, expressionAttributeValues)
+
+	err := l.repository.retryConflicts(ctx, func() error {
+		_, updateErr := l.repository.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                           &l.repository.TableName,
+			Key:                                 l.key(),
+			UpdateExpression:                    &updateExpression,
+			ConditionExpression:                 &conditionExpression,
+			ExpressionAttributeNames:            expressionAttributeNames,
+			ExpressionAttributeValues:           expressionAttributeValues,
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		})
+
+		return updateErr
+	})
+	if err != nil {
+		if conditionalCheckFailedException := conditionalCheckFailure(err); conditionalCheckFailedException != nil {
+			if conditionalCheckFailedException.Item == nil {
+				return fmt.Errorf("%w: %w", ErrLockNotHeld, err)
+			}
+
+			return fmt.Errorf("%w: %w", ErrLockTakenOver, err)
+		}
+
+		return err
 	}
 
-	l.lockId = newLock.lockId
+	l.lockId = generatedId
+	l.lease = newLease
 
 	return nil
 }
 
+// refreshExpression returns the update expression that sets a new lockId and timeout, together with the new lockId and lease. The mutex should be held by the caller.
+func (l *Lock) refreshExpression(expressionAttributeNames map[string]string, expressionAttributeValues map[string]types.AttributeValue) (string, string, *lease) {
+	generatedId := l.repository.IdGenerator.ID()
+
+	attributes := map[string]types.AttributeValue{
+		attributeNameLockId:  &types.AttributeValueMemberS{Value: generatedId},
+		attributeNameTimeout: &types.AttributeValueMemberN{Value: strconv.FormatInt(l.repository.Timeout.Nanoseconds(), 10)},
+	}
+
+	newLease := l.repository.ownerAttributes(attributes, l.lease)
+	l.repository.expirationAttribute(attributes)
+
+	return "SET " + strings.Join(setOperations(attributes, expressionAttributeNames, expressionAttributeValues), ", "), generatedId, newLease
+}
+
+// condition returns the condition expression that validates if the lock is still held
+func (l *Lock) condition() (string, map[string]string, map[string]types.AttributeValue) {
+	if l.ownerId != "" {
+		return "#OwnerId = :ownerId", map[string]string{"#OwnerId": attributeNameOwnerId}, map[string]types.AttributeValue{":ownerId": &types.AttributeValueMemberS{Value: l.ownerId}}
+	}
+
+	return "#LockId = :lockId", map[string]string{"#LockId": attributeNameLockId}, map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: l.lockId}}
+}
+
 func (l *Lock) key() map[string]types.AttributeValue {
 	return l.repository.key(l.partition)
 }
 
-func sleepContext(ctx context.Context, delay time.Duration, delayVariance time.Duration) {
-	variance := time.Duration(0)
+// setOperations adds the attributes to the expression attribute names and values and returns the SET operations to write them, sorted by attribute name
+func setOperations(attributes map[string]types.AttributeValue, expressionAttributeNames map[string]string, expressionAttributeValues map[string]types.AttributeValue) []string {
+	attributeNames := make([]string, 0, len(attributes))
+	for attributeName := range attributes {
+		attributeNames = append(attributeNames, attributeName)
+	}
+
+	sort.Strings(attributeNames)
 
-	if delayVariance > 0 {
-		varianceMilliSecs := rand.Int63n(delayVariance.Milliseconds()*2) - delayVariance.Milliseconds()
-		variance = time.Millisecond * time.Duration(varianceMilliSecs)
+	operations := make([]string, 0, len(attributeNames))
+
+	for i, attributeName := range attributeNames {
+		expressionAttributeNames[fmt.Sprintf("#A%d", i)] = attributeName
+		expressionAttributeValues[fmt.Sprintf(":a%d", i)] = attributes[attributeName]
+		operations = append(operations, fmt.Sprintf("#A%d = :a%d", i, i))
 	}
 
+	return operations
+}
+
+func sleepContext(ctx context.Context, delay time.Duration, delayVariance time.Duration) {
 	select {
 	case <-ctx.Done():
-	case <-time.After(delay + variance):
+	case <-time.After(delay + randomVariance(delayVariance)):
 	}
 }
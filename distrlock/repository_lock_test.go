@@ -182,22 +182,15 @@ func TestLock_Lock_Success(t *testing.T) {
 			attributeNameLockId:  &types.AttributeValueMemberS{Value: "UniqueID"},
 			attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
 		},
-		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
-		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: ""}},
-	}).Return(nil, fmt.Errorf("context of error: %w", &types.ConditionalCheckFailedException{Message: ptr.String("condition failed")})).Times(3)
-
-	dynamodbClient.EXPECT().GetItem(mock.Anything, &dynamodb.GetItemInput{
-		TableName:      &tableName,
-		Key:            map[string]types.AttributeValue{pkName: pk},
-		ConsistentRead: aws.Bool(true),
-	}).Return(&dynamodb.GetItemOutput{
-		Item: map[string]types.AttributeValue{
-			pkName:               pk,
-			attributeNameLockId:  &types.AttributeValueMemberS{Value: "AnotherLock"},
-			attributeNameTimeout: &types.AttributeValueMemberN{Value: "30000000"},
-		},
-	}, nil).Times(3)
+		ConditionExpression:                 aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
+		ExpressionAttributeNames:            map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
+		ExpressionAttributeValues:           map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: ""}},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(nil, fmt.Errorf("context of error: %w", &types.ConditionalCheckFailedException{Message: ptr.String("condition failed"), Item: map[string]types.AttributeValue{
+		pkName:               pk,
+		attributeNameLockId:  &types.AttributeValueMemberS{Value: "AnotherLock"},
+		attributeNameTimeout: &types.AttributeValueMemberN{Value: "30000000"},
+	}})).Times(3)
 
 	dynamodbClient.EXPECT().PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: &tableName,
@@ -206,9 +199,10 @@ func TestLock_Lock_Success(t *testing.T) {
 			attributeNameLockId:  &types.AttributeValueMemberS{Value: "UniqueID"},
 			attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
 		},
-		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
-		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: "AnotherLock"}},
+		ConditionExpression:                 aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
+		ExpressionAttributeNames:            map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
+		ExpressionAttributeValues:           map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: "AnotherLock"}},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 	}).Return(nil, nil).Once()
 
 	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100),
@@ -248,22 +242,15 @@ func TestLock_Lock_Timeout(t *testing.T) {
 			attributeNameLockId:  &types.AttributeValueMemberS{Value: "UniqueID"},
 			attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
 		},
-		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
-		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: ""}},
-	}).Return(nil, fmt.Errorf("context of error: %w", &types.ConditionalCheckFailedException{Message: ptr.String("condition failed")}))
-
-	dynamodbClient.EXPECT().GetItem(mock.Anything, &dynamodb.GetItemInput{
-		TableName:      &tableName,
-		Key:            map[string]types.AttributeValue{pkName: pk},
-		ConsistentRead: aws.Bool(true),
-	}).Return(&dynamodb.GetItemOutput{
-		Item: map[string]types.AttributeValue{
-			pkName:               pk,
-			attributeNameLockId:  &types.AttributeValueMemberS{Value: "AnotherLock"},
-			attributeNameTimeout: &types.AttributeValueMemberN{Value: "300000000"},
-		},
-	}, nil)
+		ConditionExpression:                 aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
+		ExpressionAttributeNames:            map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
+		ExpressionAttributeValues:           map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: ""}},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(nil, fmt.Errorf("context of error: %w", &types.ConditionalCheckFailedException{Message: ptr.String("condition failed"), Item: map[string]types.AttributeValue{
+		pkName:               pk,
+		attributeNameLockId:  &types.AttributeValueMemberS{Value: "AnotherLock"},
+		attributeNameTimeout: &types.AttributeValueMemberN{Value: "300000000"},
+	}}))
 
 	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100),
 		WithRefreshInterval(time.Millisecond*10),
@@ -274,7 +261,7 @@ func TestLock_Lock_Timeout(t *testing.T) {
 	lock, err := handler.Lock(ctx, pk)
 
 	//Then
-	require.ErrorAs(t, err, &ErrTimeout)
+	require.ErrorIs(t, err, ErrTimeout)
 	require.Nil(t, lock)
 }
 
@@ -307,9 +294,10 @@ func TestLock_Unlock(t *testing.T) {
 		Key: map[string]types.AttributeValue{
 			pkName: pk,
 		},
-		ConditionExpression:       aws.String("#LockId = :lockId"),
-		ExpressionAttributeNames:  map[string]string{"#LockId": attributeNameLockId},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: "UniqueID"}},
+		ConditionExpression:                 aws.String("#LockId = :lockId"),
+		ExpressionAttributeNames:            map[string]string{"#LockId": attributeNameLockId},
+		ExpressionAttributeValues:           map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: "UniqueID"}},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 	}).Return(nil, nil).Once()
 
 	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100))
@@ -489,6 +477,107 @@ func TestLock_TransactionWithRefresh_HashRange(t *testing.T) {
 	require.Equal(t, "newLockId", lock.LockId())
 }
 
+func TestLock_TransactionWithRefresh_WithOwnerMetadata(t *testing.T) {
+	// Given
+	idGenerator := mocks.NewIdGenerator(t)
+	idGenerator.EXPECT().ID().Return("newLockId").Maybe()
+
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      idGenerator,
+		Owner:            &OwnerMetadata{Hostname: "host", ProcessId: 42, Label: "worker"},
+	}
+
+	acquiredAt := time.Now().Add(-time.Minute)
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+		lease:      &lease{acquiredAt: acquiredAt, acquisitionCount: 2},
+	}
+
+	// When
+	writeItem, callbackFn := lock.TransactionWithRefresh()
+
+	// Then
+	update := writeItem.Update
+	require.Equal(t, "SET #LockId = :newLockId, #A0 = :a0, #A1 = :a1, #A2 = :a2, #A3 = :a3, #A4 = :a4, #A5 = :a5", *update.UpdateExpression)
+	require.Equal(t, attributeNameAcquiredAt, update.ExpressionAttributeNames["#A0"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: acquiredAt.UTC().Format(time.RFC3339Nano)}, update.ExpressionAttributeValues[":a0"])
+	require.Equal(t, attributeNameAcquisitionCount, update.ExpressionAttributeNames["#A1"])
+	require.Equal(t, &types.AttributeValueMemberN{Value: "3"}, update.ExpressionAttributeValues[":a1"])
+	require.Equal(t, attributeNameRefreshedAt, update.ExpressionAttributeNames["#A5"])
+
+	_, err := callbackFn(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, "newLockId", lock.LockId())
+	require.Equal(t, &lease{acquiredAt: acquiredAt, acquisitionCount: 3}, lock.lease)
+}
+
+func TestLock_TransactionWithRefresh_WithTTL(t *testing.T) {
+	// Given
+	idGenerator := mocks.NewIdGenerator(t)
+	idGenerator.EXPECT().ID().Return("newLockId").Maybe()
+
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      idGenerator,
+		TTLAttributeName: ptr.String("expiresAt"),
+		TTL:              time.Hour,
+	}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+	}
+
+	// When
+	writeItem, _ := lock.TransactionWithRefresh()
+
+	// Then
+	update := writeItem.Update
+	require.Equal(t, "SET #LockId = :newLockId, #A0 = :a0", *update.UpdateExpression)
+	require.Equal(t, "expiresAt", update.ExpressionAttributeNames["#A0"])
+	require.True(t, isExpiration(update.ExpressionAttributeValues[":a0"], time.Hour))
+}
+
+func TestLock_TransactionWithRefresh_Failed(t *testing.T) {
+	// Given
+	idGenerator := mocks.NewIdGenerator(t)
+	idGenerator.EXPECT().ID().Return("newLockId").Maybe()
+
+	rh := RepositoryLockHandler{
+		TableName:        "DynamoDbTable",
+		PartitionKeyName: "PK",
+		IdGenerator:      idGenerator,
+		Owner:            &OwnerMetadata{Hostname: "host", ProcessId: 42, Label: "worker"},
+	}
+
+	previousLease := &lease{acquiredAt: time.Now(), acquisitionCount: 2}
+
+	lock := Lock{
+		lockId:     "someLockId",
+		partition:  &types.AttributeValueMemberS{Value: "Some PK"},
+		repository: &rh,
+		lease:      previousLease,
+	}
+
+	_, callbackFn := lock.TransactionWithRefresh()
+
+	// When
+	_, err := callbackFn(nil, &types.TransactionCanceledException{})
+
+	// Then
+	require.Error(t, err)
+	require.Equal(t, "someLockId", lock.LockId())
+	require.Same(t, previousLease, lock.lease)
+}
+
 func TestLock_Refresh_Success(t *testing.T) {
 	// Given
 
@@ -499,17 +588,23 @@ func TestLock_Refresh_Success(t *testing.T) {
 	pk := &types.AttributeValueMemberS{Value: "PK"}
 
 	dynamodbClient := mocks.NewDynamodbClient(t)
-	dynamodbClient.EXPECT().PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: &tableName,
-		Item: map[string]types.AttributeValue{
-			pkName:               pk,
-			attributeNameLockId:  &types.AttributeValueMemberS{Value: "UniqueID"},
-			attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &tableName,
+		Key:                 map[string]types.AttributeValue{pkName: pk},
+		UpdateExpression:    aws.String("SET #A0 = :a0, #A1 = :a1"),
+		ConditionExpression: aws.String("attribute_exists(#LockId) AND #LockId = :lockId"),
+		ExpressionAttributeNames: map[string]string{
+			"#LockId": attributeNameLockId,
+			"#A0":     attributeNameLockId,
+			"#A1":     attributeNameTimeout,
 		},
-		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
-		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: "existingLock"}},
-	}).Return(nil, nil)
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lockId": &types.AttributeValueMemberS{Value: "existingLock"},
+			":a0":     &types.AttributeValueMemberS{Value: "UniqueID"},
+			":a1":     &types.AttributeValueMemberN{Value: "100000000"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}).Return(&dynamodb.UpdateItemOutput{}, nil)
 
 	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
 
@@ -537,16 +632,22 @@ func TestLock_Refresh_Failed(t *testing.T) {
 	pk := &types.AttributeValueMemberS{Value: "PK"}
 
 	dynamodbClient := mocks.NewDynamodbClient(t)
-	dynamodbClient.EXPECT().PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: &tableName,
-		Item: map[string]types.AttributeValue{
-			pkName:               pk,
-			attributeNameLockId:  &types.AttributeValueMemberS{Value: "UniqueID"},
-			attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &tableName,
+		Key:                 map[string]types.AttributeValue{pkName: pk},
+		UpdateExpression:    aws.String("SET #A0 = :a0, #A1 = :a1"),
+		ConditionExpression: aws.String("attribute_exists(#LockId) AND #LockId = :lockId"),
+		ExpressionAttributeNames: map[string]string{
+			"#LockId": attributeNameLockId,
+			"#A0":     attributeNameLockId,
+			"#A1":     attributeNameTimeout,
 		},
-		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
-		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: "existingLock"}},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lockId": &types.AttributeValueMemberS{Value: "existingLock"},
+			":a0":     &types.AttributeValueMemberS{Value: "UniqueID"},
+			":a1":     &types.AttributeValueMemberN{Value: "100000000"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 	}).Return(nil, &types.ConditionalCheckFailedException{})
 
 	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
@@ -561,7 +662,8 @@ func TestLock_Refresh_Failed(t *testing.T) {
 	err := lock.Refresh(ctx)
 
 	// Then
-	require.ErrorAs(t, err, &ErrLockUpdate)
+	require.ErrorIs(t, err, ErrLockUpdate)
+	require.ErrorIs(t, err, ErrLockNotHeld)
 	require.Equal(t, "existingLock", lock.lockId)
 }
 
@@ -575,16 +677,22 @@ func TestLock_Refresh_Error(t *testing.T) {
 	pk := &types.AttributeValueMemberS{Value: "PK"}
 
 	dynamodbClient := mocks.NewDynamodbClient(t)
-	dynamodbClient.EXPECT().PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: &tableName,
-		Item: map[string]types.AttributeValue{
-			pkName:               pk,
-			attributeNameLockId:  &types.AttributeValueMemberS{Value: "UniqueID"},
-			attributeNameTimeout: &types.AttributeValueMemberN{Value: "100000000"},
+	dynamodbClient.EXPECT().UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &tableName,
+		Key:                 map[string]types.AttributeValue{pkName: pk},
+		UpdateExpression:    aws.String("SET #A0 = :a0, #A1 = :a1"),
+		ConditionExpression: aws.String("attribute_exists(#LockId) AND #LockId = :lockId"),
+		ExpressionAttributeNames: map[string]string{
+			"#LockId": attributeNameLockId,
+			"#A0":     attributeNameLockId,
+			"#A1":     attributeNameTimeout,
 		},
-		ConditionExpression:       aws.String("attribute_not_exists(#PK) OR #LockID = :lockid"),
-		ExpressionAttributeNames:  map[string]string{"#LockID": attributeNameLockId, "#PK": pkName},
-		ExpressionAttributeValues: map[string]types.AttributeValue{":lockid": &types.AttributeValueMemberS{Value: "existingLock"}},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lockId": &types.AttributeValueMemberS{Value: "existingLock"},
+			":a0":     &types.AttributeValueMemberS{Value: "UniqueID"},
+			":a1":     &types.AttributeValueMemberN{Value: "100000000"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 	}).Return(nil, errors.New("boom"))
 
 	handler := New(dynamodbClient, tableName, pkName, WithTimeout(time.Millisecond*100), MockIdGenerator(t, "UniqueID"))
@@ -603,6 +711,126 @@ func TestLock_Refresh_Error(t *testing.T) {
 	require.Equal(t, "existingLock", lock.lockId)
 }
 
+func TestLock_Release_AlreadyRemoved(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().DeleteItem(ctx, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName")
+
+	lock := Lock{lockId: "UniqueID", partition: pk, repository: handler}
+
+	// When
+	err := lock.Release(ctx)
+	secondErr := lock.Release(ctx)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, secondErr)
+}
+
+func TestLock_Release_TakenOver(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().DeleteItem(ctx, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		"pkName":            pk,
+		attributeNameLockId: &types.AttributeValueMemberS{Value: "OtherLock"},
+	}}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName")
+
+	lock := Lock{lockId: "UniqueID", partition: pk, repository: handler}
+
+	// When
+	err := lock.Release(ctx)
+	refreshErr := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockTakenOver)
+	require.ErrorIs(t, err, ErrLockUpdate)
+	require.ErrorIs(t, refreshErr, ErrLockNotHeld)
+}
+
+func TestLock_Refresh_TakenOver(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{Item: map[string]types.AttributeValue{
+		"pkName":            pk,
+		attributeNameLockId: &types.AttributeValueMemberS{Value: "OtherLock"},
+	}}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	lock := Lock{lockId: "existingLock", partition: pk, repository: handler}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockTakenOver)
+	require.Equal(t, "existingLock", lock.lockId)
+}
+
+func TestLock_Refresh_AfterRelease(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	// The lock item was removed, e.g. by a Release of another Lock instance with the same lockId
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "attribute_exists(#LockId) AND #LockId = :lockId"
+	})).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	lock := Lock{lockId: "existingLock", partition: pk, repository: handler}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, ErrLockNotHeld)
+	require.Equal(t, "existingLock", lock.lockId)
+}
+
+func TestLock_Refresh_DroppedTransactionCallback(t *testing.T) {
+	// Given
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancelFn()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+
+	handler := New(dynamodbClient, "tableName", "pkName", MockIdGenerator(t, "UniqueID"))
+
+	lock := Lock{lockId: "existingLock", partition: pk, repository: handler}
+
+	// The callback is never called, e.g. because of an early return before TransactWriteItems
+	_, _ = lock.TransactionWithRefresh()
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, "existingLock", lock.LockId())
+}
+
 func MockIdGenerator(t *testing.T, id string) func(options *Options) {
 	t.Helper()
 
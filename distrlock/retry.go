@@ -0,0 +1,79 @@
+package distrlock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const defaultConflictRetryAttempts = 5
+
+// errTransactionConflict is returned to retryConflicts if a transaction was cancelled by a conflicting transaction without a TransactionConflictException
+var errTransactionConflict = errors.New("transaction cancelled by a conflicting transaction")
+
+var defaultConflictRetryWait = ExponentialWait{Base: time.Millisecond * 15, Max: time.Millisecond * 250, Jitter: true}
+
+// WithConflictRetry Specifies how often Release and Refresh are attempted if the request conflicts with an ongoing transaction,
+// and the strategy to determine the time between two attempts.
+// By default, 5 attempts are executed with an exponential backoff starting at 15ms.
+func WithConflictRetry(maxAttempts int, waitStrategy WaitStrategy) func(options *Options) {
+	return func(options *Options) {
+		options.ConflictRetryAttempts = &maxAttempts
+		options.ConflictRetryWait = waitStrategy
+	}
+}
+
+// retryConflicts executes fn until it does not return a TransactionConflictException or the maximum number of attempts is reached
+func (h *RepositoryLockHandler) retryConflicts(ctx context.Context, fn func() error) error {
+	waitStrategy := h.ConflictRetryWait
+	if waitStrategy == nil {
+		waitStrategy = defaultConflictRetryWait
+	}
+
+	previousDelay := time.Duration(0)
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isTransactionConflict(err) || attempt >= h.ConflictRetryAttempts {
+			return err
+		}
+
+		previousDelay = waitStrategy.Wait(attempt, previousDelay)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(previousDelay):
+		}
+	}
+}
+
+func isTransactionConflict(err error) bool {
+	var transactionConflictException *types.TransactionConflictException
+
+	return errors.As(err, &transactionConflictException) || errors.Is(err, errTransactionConflict)
+}
+
+// isRetryable checks if err is a transient error, e.g. throttling, a transaction conflict or an internal server error, after which the request can be retried
+func isRetryable(err error) bool {
+	var internalServerError *types.InternalServerError
+	if isTransactionConflict(err) || errors.As(err, &internalServerError) {
+		return true
+	}
+
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// conditionalCheckFailure returns the ConditionalCheckFailedException wrapped in err, or nil if err is caused by something else
+func conditionalCheckFailure(err error) *types.ConditionalCheckFailedException {
+	var conditionalCheckFailedException *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckFailedException) {
+		return conditionalCheckFailedException
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package distrlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func TestLock_Release_RetryConflict(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().DeleteItem(ctx, mock.Anything).Return(nil, &types.TransactionConflictException{}).Twice()
+	dynamodbClient.EXPECT().DeleteItem(ctx, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithConflictRetry(3, FixedWait{Interval: time.Millisecond}))
+
+	lock := Lock{lockId: "UniqueID", partition: pk, repository: handler}
+
+	// When
+	err := lock.Release(ctx)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestLock_Refresh_RetryConflict_MaxAttempts(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	pk := &types.AttributeValueMemberS{Value: "PK"}
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().UpdateItem(ctx, mock.Anything).Return(nil, &types.TransactionConflictException{}).Times(3)
+
+	handler := New(dynamodbClient, "tableName", "pkName", WithConflictRetry(3, FixedWait{Interval: time.Millisecond}), MockIdGenerator(t, "UniqueID"))
+
+	lock := Lock{lockId: "existingLock", partition: pk, repository: handler}
+
+	// When
+	err := lock.Refresh(ctx)
+
+	// Then
+	var transactionConflictException *types.TransactionConflictException
+	require.ErrorAs(t, err, &transactionConflictException)
+	require.Equal(t, "existingLock", lock.lockId)
+}
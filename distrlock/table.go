@@ -0,0 +1,294 @@
+package distrlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+//go:generate go run github.com/vektra/mockery/v2 --name=TableClient --with-expecter
+type TableClient interface {
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+type CreateTableOptions struct {
+	// PartitionKeyType type of the partition key
+	PartitionKeyType types.ScalarAttributeType
+
+	// MaxWait maximum time to wait until the table is active
+	MaxWait time.Duration
+}
+
+// WithTTL Specifies that lock items store an expiration time in the given attribute, as epoch seconds.
+// Items expire ttl after they were last refreshed. Enable DynamoDB TTL on the attribute to remove abandoned locks.
+// The ttl should be significantly larger than the lock timeout, as DynamoDB may take a while to remove expired items.
+func WithTTL(attributeName string, ttl time.Duration) func(options *Options) {
+	return func(options *Options) {
+		options.TTLAttributeName = &attributeName
+		options.TTL = &ttl
+	}
+}
+
+// WithPartitionKeyType Specifies the type of the partition key of the created table. Default value is string.
+func WithPartitionKeyType(partitionKeyType types.ScalarAttributeType) func(options *CreateTableOptions) {
+	return func(options *CreateTableOptions) {
+		options.PartitionKeyType = partitionKeyType
+	}
+}
+
+// WithMaxWait Specifies the maximum time to wait until the created table is active. Default value is 5 minutes.
+func WithMaxWait(maxWait time.Duration) func(options *CreateTableOptions) {
+	return func(options *CreateTableOptions) {
+		options.MaxWait = maxWait
+	}
+}
+
+// ValidateTable verifies that the lock table exists and matches the configuration of the handler:
+// the key schema and key types, the list index and the TTL setting.
+// All problems are reported in a single error that wraps ErrTableConfiguration.
+func (h *RepositoryLockHandler) ValidateTable(ctx context.Context, client TableClient) error {
+	output, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &h.TableName})
+	if err != nil {
+		var resourceNotFoundException *types.ResourceNotFoundException
+		if errors.As(err, &resourceNotFoundException) {
+			return fmt.Errorf("%w: table %q does not exist", ErrTableConfiguration, h.TableName)
+		}
+
+		return err
+	}
+
+	problems := h.tableProblems(output.Table)
+
+	if h.TTLAttributeName != nil {
+		ttlProblem, ttlErr := h.ttlProblem(ctx, client)
+		if ttlErr != nil {
+			return ttlErr
+		}
+
+		if ttlProblem != "" {
+			problems = append(problems, ttlProblem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrTableConfiguration, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// CreateTable creates the lock table with the key schema, list index and TTL setting of the handler and waits until the table is active.
+// If the table already exists, it is only validated: its TTL setting is not modified.
+func (h *RepositoryLockHandler) CreateTable(ctx context.Context, client TableClient, optFns ...func(options *CreateTableOptions)) error {
+	options := CreateTableOptions{
+		PartitionKeyType: types.ScalarAttributeTypeS,
+		MaxWait:          time.Minute * 5,
+	}
+
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	created := true
+
+	_, err := client.CreateTable(ctx, h.createTableInput(options.PartitionKeyType))
+	if err != nil {
+		var resourceInUseException *types.ResourceInUseException
+		if !errors.As(err, &resourceInUseException) {
+			return err
+		}
+
+		created = false
+	}
+
+	err = dynamodb.NewTableExistsWaiter(client, func(waiterOptions *dynamodb.TableExistsWaiterOptions) {
+		waiterOptions.MinDelay = time.Second
+		waiterOptions.MaxDelay = time.Second * 10
+	}).Wait(ctx, &dynamodb.DescribeTableInput{TableName: &h.TableName}, options.MaxWait)
+	if err != nil {
+		return err
+	}
+
+	if created && h.TTLAttributeName != nil {
+		ttlProblem, ttlErr := h.ttlProblem(ctx, client)
+		if ttlErr != nil {
+			return ttlErr
+		}
+
+		if ttlProblem != "" {
+			_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+				TableName: &h.TableName,
+				TimeToLiveSpecification: &types.TimeToLiveSpecification{
+					AttributeName: h.TTLAttributeName,
+					Enabled:       aws.Bool(true),
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return h.ValidateTable(ctx, client)
+}
+
+func (h *RepositoryLockHandler) createTableInput(partitionKeyType types.ScalarAttributeType) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:   &h.TableName,
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: &h.PartitionKeyName, KeyType: types.KeyTypeHash},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: &h.PartitionKeyName, AttributeType: partitionKeyType},
+		},
+	}
+
+	if h.SortKeyName != nil {
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{AttributeName: h.SortKeyName, KeyType: types.KeyTypeRange})
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{AttributeName: h.SortKeyName, AttributeType: attributeValueType(h.SortKeyValue)})
+
+		if h.ListIndexName != nil {
+			input.GlobalSecondaryIndexes = []types.GlobalSecondaryIndex{
+				{
+					IndexName:  h.ListIndexName,
+					KeySchema:  []types.KeySchemaElement{{AttributeName: h.SortKeyName, KeyType: types.KeyTypeHash}},
+					Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+				},
+			}
+		}
+	}
+
+	return input
+}
+
+// tableProblems returns a description of every difference between the table and the configuration of the handler
+func (h *RepositoryLockHandler) tableProblems(table *types.TableDescription) []string {
+	if table == nil {
+		return []string{fmt.Sprintf("table %q could not be described", h.TableName)}
+	}
+
+	var problems []string
+
+	if table.TableStatus != types.TableStatusActive && table.TableStatus != types.TableStatusUpdating {
+		problems = append(problems, fmt.Sprintf("table %q is %s", h.TableName, table.TableStatus))
+	}
+
+	attributeTypes := make(map[string]types.ScalarAttributeType, len(table.AttributeDefinitions))
+	for _, definition := range table.AttributeDefinitions {
+		attributeTypes[aws.ToString(definition.AttributeName)] = definition.AttributeType
+	}
+
+	hashKey, rangeKey := keyNames(table.KeySchema)
+
+	if hashKey != h.PartitionKeyName {
+		problems = append(problems, fmt.Sprintf("partition key is %q, but handler uses %q", hashKey, h.PartitionKeyName))
+	}
+
+	switch {
+	case h.SortKeyName == nil && rangeKey != "":
+		problems = append(problems, fmt.Sprintf("table has sort key %q, but handler has no sort key configured", rangeKey))
+	case h.SortKeyName != nil && rangeKey != *h.SortKeyName:
+		problems = append(problems, fmt.Sprintf("sort key is %q, but handler uses %q", rangeKey, *h.SortKeyName))
+	case h.SortKeyName != nil:
+		expectedType := attributeValueType(h.SortKeyValue)
+		if attributeTypes[rangeKey] != expectedType {
+			problems = append(problems, fmt.Sprintf("sort key %q is of type %s, but sort key value is of type %s", rangeKey, attributeTypes[rangeKey], expectedType))
+		}
+	}
+
+	if h.FairQueue && h.SortKeyName != nil && attributeTypes[rangeKey] != types.ScalarAttributeTypeS {
+		problems = append(problems, "fair queueing requires a sort key of type S")
+	}
+
+	if h.ListIndexName != nil {
+		problems = append(problems, h.listIndexProblems(table.GlobalSecondaryIndexes)...)
+	}
+
+	return problems
+}
+
+func (h *RepositoryLockHandler) listIndexProblems(indexes []types.GlobalSecondaryIndexDescription) []string {
+	for _, index := range indexes {
+		if aws.ToString(index.IndexName) != *h.ListIndexName {
+			continue
+		}
+
+		hashKey, _ := keyNames(index.KeySchema)
+		if h.SortKeyName == nil || hashKey != *h.SortKeyName {
+			return []string{fmt.Sprintf("list index %q should have the sort key as partition key, but has %q", *h.ListIndexName, hashKey)}
+		}
+
+		return nil
+	}
+
+	return []string{fmt.Sprintf("list index %q does not exist", *h.ListIndexName)}
+}
+
+// ttlProblem returns a description of the problem if TTL is not enabled on the TTL attribute of the handler
+func (h *RepositoryLockHandler) ttlProblem(ctx context.Context, client TableClient) (string, error) {
+	output, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: &h.TableName})
+	if err != nil {
+		return "", err
+	}
+
+	if output.TimeToLiveDescription == nil {
+		return fmt.Sprintf("TTL is not enabled on attribute %q", *h.TTLAttributeName), nil
+	}
+
+	status := output.TimeToLiveDescription.TimeToLiveStatus
+	attributeName := aws.ToString(output.TimeToLiveDescription.AttributeName)
+
+	if (status != types.TimeToLiveStatusEnabled && status != types.TimeToLiveStatusEnabling) || attributeName != *h.TTLAttributeName {
+		return fmt.Sprintf("TTL is not enabled on attribute %q (status %s, attribute %q)", *h.TTLAttributeName, status, attributeName), nil
+	}
+
+	return "", nil
+}
+
+// expirationAttribute sets the TTL attribute of a lock item if TTL is configured
+func (h *RepositoryLockHandler) expirationAttribute(item map[string]types.AttributeValue) {
+	if h.TTLAttributeName == nil {
+		return
+	}
+
+	item[*h.TTLAttributeName] = &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(h.TTL).Unix(), 10)}
+}
+
+func keyNames(keySchema []types.KeySchemaElement) (string, string) {
+	var hashKey, rangeKey string
+
+	for _, element := range keySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			hashKey = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			rangeKey = aws.ToString(element.AttributeName)
+		}
+	}
+
+	return hashKey, rangeKey
+}
+
+func attributeValueType(value types.AttributeValue) types.ScalarAttributeType {
+	switch value.(type) {
+	case *types.AttributeValueMemberN:
+		return types.ScalarAttributeTypeN
+	case *types.AttributeValueMemberB:
+		return types.ScalarAttributeTypeB
+	case *types.AttributeValueMemberS:
+		return types.ScalarAttributeTypeS
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,188 @@
+package distrlock
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
+)
+
+func lockTableDescription(sortKeyType types.ScalarAttributeType) *types.TableDescription {
+	return &types.TableDescription{
+		TableName:   aws.String("tableName"),
+		TableStatus: types.TableStatusActive,
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("PK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("SK"), AttributeType: sortKeyType},
+		},
+	}
+}
+
+func TestRepositoryLockHandler_ValidateTable_Valid(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableClient := mocks.NewTableClient(t)
+	tableClient.EXPECT().DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("tableName")}).Return(&dynamodb.DescribeTableOutput{Table: lockTableDescription(types.ScalarAttributeTypeS)}, nil).Once()
+	tableClient.EXPECT().DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String("tableName")}).Return(&dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &types.TimeToLiveDescription{AttributeName: aws.String("expiresAt"), TimeToLiveStatus: types.TimeToLiveStatusEnabled},
+	}, nil).Once()
+
+	handler := New(mocks.NewDynamodbClient(t), "tableName", "PK", WithSortKey("SK"), WithTTL("expiresAt", time.Hour))
+
+	// When
+	err := handler.ValidateTable(ctx, tableClient)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestRepositoryLockHandler_ValidateTable_Misconfigured(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableClient := mocks.NewTableClient(t)
+	tableClient.EXPECT().DescribeTable(ctx, mock.Anything).Return(&dynamodb.DescribeTableOutput{Table: lockTableDescription(types.ScalarAttributeTypeN)}, nil).Once()
+	tableClient.EXPECT().DescribeTimeToLive(ctx, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
+	}, nil).Once()
+
+	handler := New(mocks.NewDynamodbClient(t), "tableName", "PK", WithSortKey("SK"), WithTTL("expiresAt", time.Hour), WithListIndex("listIndex"))
+
+	// When
+	err := handler.ValidateTable(ctx, tableClient)
+
+	// Then
+	require.ErrorIs(t, err, ErrTableConfiguration)
+	require.ErrorContains(t, err, `sort key "SK" is of type N, but sort key value is of type S`)
+	require.ErrorContains(t, err, `list index "listIndex" does not exist`)
+	require.ErrorContains(t, err, `TTL is not enabled on attribute "expiresAt"`)
+}
+
+func TestRepositoryLockHandler_ValidateTable_NotFound(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableClient := mocks.NewTableClient(t)
+	tableClient.EXPECT().DescribeTable(ctx, mock.Anything).Return(nil, &types.ResourceNotFoundException{}).Once()
+
+	handler := New(mocks.NewDynamodbClient(t), "tableName", "PK")
+
+	// When
+	err := handler.ValidateTable(ctx, tableClient)
+
+	// Then
+	require.ErrorIs(t, err, ErrTableConfiguration)
+	require.ErrorContains(t, err, `table "tableName" does not exist`)
+}
+
+func TestRepositoryLockHandler_CreateTable(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableClient := mocks.NewTableClient(t)
+	tableClient.EXPECT().CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String("tableName"),
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("PK"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("SK"), AttributeType: types.ScalarAttributeTypeS},
+		},
+	}).Return(&dynamodb.CreateTableOutput{}, nil).Once()
+
+	// Waiter
+	tableClient.EXPECT().DescribeTable(mock.Anything, mock.Anything, mock.Anything).Return(&dynamodb.DescribeTableOutput{Table: lockTableDescription(types.ScalarAttributeTypeS)}, nil).Once()
+
+	tableClient.EXPECT().DescribeTimeToLive(ctx, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
+	}, nil).Once()
+	tableClient.EXPECT().UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName:               aws.String("tableName"),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{AttributeName: aws.String("expiresAt"), Enabled: aws.Bool(true)},
+	}).Return(&dynamodb.UpdateTimeToLiveOutput{}, nil).Once()
+
+	// Validation
+	tableClient.EXPECT().DescribeTable(ctx, mock.Anything).Return(&dynamodb.DescribeTableOutput{Table: lockTableDescription(types.ScalarAttributeTypeS)}, nil).Once()
+	tableClient.EXPECT().DescribeTimeToLive(ctx, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &types.TimeToLiveDescription{AttributeName: aws.String("expiresAt"), TimeToLiveStatus: types.TimeToLiveStatusEnabling},
+	}, nil).Once()
+
+	handler := New(mocks.NewDynamodbClient(t), "tableName", "PK", WithSortKey("SK"), WithTTL("expiresAt", time.Hour))
+
+	// When
+	err := handler.CreateTable(ctx, tableClient)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestRepositoryLockHandler_CreateTable_ExistingTable(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	tableClient := mocks.NewTableClient(t)
+	tableClient.EXPECT().CreateTable(ctx, mock.Anything).Return(nil, &types.ResourceInUseException{}).Once()
+
+	// Waiter and validation
+	tableClient.EXPECT().DescribeTable(mock.Anything, mock.Anything, mock.Anything).Return(&dynamodb.DescribeTableOutput{Table: lockTableDescription(types.ScalarAttributeTypeS)}, nil).Once()
+	tableClient.EXPECT().DescribeTable(ctx, mock.Anything).Return(&dynamodb.DescribeTableOutput{Table: lockTableDescription(types.ScalarAttributeTypeS)}, nil).Once()
+	tableClient.EXPECT().DescribeTimeToLive(ctx, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &types.TimeToLiveDescription{AttributeName: aws.String("otherAttribute"), TimeToLiveStatus: types.TimeToLiveStatusEnabled},
+	}, nil).Once()
+
+	handler := New(mocks.NewDynamodbClient(t), "tableName", "PK", WithSortKey("SK"), WithTTL("expiresAt", time.Hour))
+
+	// When
+	err := handler.CreateTable(ctx, tableClient)
+
+	// Then
+	require.ErrorIs(t, err, ErrTableConfiguration)
+	tableClient.AssertNotCalled(t, "UpdateTimeToLive", mock.Anything, mock.Anything)
+}
+
+func TestLock_TryLock_WithTTL(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	dynamodbClient := mocks.NewDynamodbClient(t)
+	dynamodbClient.EXPECT().PutItem(ctx, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		return isExpiration(input.Item["expiresAt"], time.Hour)
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	handler := New(dynamodbClient, "tableName", "PK", WithTTL("expiresAt", time.Hour), MockIdGenerator(t, "UniqueID"))
+
+	// When
+	_, success, err := handler.TryLock(ctx, &types.AttributeValueMemberS{Value: "PK"})
+
+	// Then
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// isExpiration returns true if value is the TTL attribute of a lock item that was written just now with the given TTL
+func isExpiration(value types.AttributeValue, ttl time.Duration) bool {
+	expiresAt, found := value.(*types.AttributeValueMemberN)
+	if !found {
+		return false
+	}
+
+	expected := time.Now().Add(ttl).Unix()
+
+	return expiresAt.Value == strconv.FormatInt(expected, 10) || expiresAt.Value == strconv.FormatInt(expected-1, 10)
+}
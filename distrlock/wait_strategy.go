@@ -0,0 +1,115 @@
+package distrlock
+
+import (
+	"math/rand"
+	"time"
+)
+
+// WaitStrategy determines the time to wait between two attempts to acquire a lock
+type WaitStrategy interface {
+	// Wait returns the delay before the next attempt. Attempt is the number of failed attempts so far, starting at 1.
+	// Previous is the delay returned for the previous attempt, or zero for the first attempt.
+	Wait(attempt int, previous time.Duration) time.Duration
+}
+
+// FixedWait waits a fixed interval with a random variance between two attempts. This is the default strategy.
+type FixedWait struct {
+	Interval time.Duration
+	Variance time.Duration
+}
+
+func (w FixedWait) Wait(_ int, _ time.Duration) time.Duration {
+	return w.Interval + randomVariance(w.Variance)
+}
+
+const (
+	// defaultWaitBase is the base delay of ExponentialWait and DecorrelatedJitterWait if Base is not set
+	defaultWaitBase = time.Millisecond * 200
+
+	// defaultWaitMax is the maximum delay of ExponentialWait and DecorrelatedJitterWait if Max is smaller than Base
+	defaultWaitMax = time.Second * 10
+)
+
+// ExponentialWait doubles the delay after every failed attempt, starting from Base, until Max is reached.
+// If Jitter is true, a random delay between zero and the computed delay is used.
+// If Base is not set, it defaults to 200ms. If Max is smaller than Base, e.g. because it is not set, it defaults to 10s or Base if that is larger.
+type ExponentialWait struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter bool
+}
+
+func (w ExponentialWait) Wait(attempt int, _ time.Duration) time.Duration {
+	base, maxDelay := waitBounds(w.Base, w.Max)
+
+	delay := base
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, maxDelay)
+
+	if w.Jitter && delay > 0 {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+
+	return delay
+}
+
+// DecorrelatedJitterWait picks a random delay between Base and three times the previous delay, capped at Max.
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+// If Base is not set, it defaults to 200ms. If Max is smaller than Base, e.g. because it is not set, it defaults to 10s or Base if that is larger.
+type DecorrelatedJitterWait struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (w DecorrelatedJitterWait) Wait(_ int, previous time.Duration) time.Duration {
+	base, maxDelay := waitBounds(w.Base, w.Max)
+
+	previous = max(previous, base)
+
+	delay := base + time.Duration(rand.Int63n(int64(previous*3-base)))
+
+	return min(delay, maxDelay)
+}
+
+// waitBounds returns the base and maximum delay of a backoff strategy, so a misconfigured strategy never returns a zero delay
+func waitBounds(base time.Duration, maxDelay time.Duration) (time.Duration, time.Duration) {
+	if base <= 0 {
+		base = defaultWaitBase
+	}
+
+	if maxDelay < base {
+		maxDelay = max(base, defaultWaitMax)
+	}
+
+	return base, maxDelay
+}
+
+// WithWaitStrategy Specifies the strategy used to determine the time between two lock poll requests.
+// By default, a FixedWait strategy based on the refresh interval and refresh variance is used.
+func WithWaitStrategy(waitStrategy WaitStrategy) func(options *Options) {
+	return func(options *Options) {
+		options.WaitStrategy = waitStrategy
+	}
+}
+
+func (h *RepositoryLockHandler) waitStrategy() WaitStrategy {
+	if h.WaitStrategy != nil {
+		return h.WaitStrategy
+	}
+
+	return FixedWait{Interval: h.RefreshInterval, Variance: h.RefreshVariance}
+}
+
+func randomVariance(delayVariance time.Duration) time.Duration {
+	if delayVariance.Milliseconds() <= 0 {
+		return 0
+	}
+
+	varianceMilliSecs := rand.Int63n(delayVariance.Milliseconds()*2) - delayVariance.Milliseconds()
+
+	return time.Millisecond * time.Duration(varianceMilliSecs)
+}
@@ -0,0 +1,117 @@
+package distrlock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedWait_Wait(t *testing.T) {
+	// Given
+	strategy := FixedWait{Interval: time.Millisecond * 100, Variance: time.Millisecond * 10}
+
+	for attempt := 1; attempt < 20; attempt++ {
+		// When
+		delay := strategy.Wait(attempt, 0)
+
+		// Then
+		require.GreaterOrEqual(t, delay, time.Millisecond*90)
+		require.Less(t, delay, time.Millisecond*110)
+	}
+}
+
+func TestExponentialWait_Wait(t *testing.T) {
+	// Given
+	strategy := ExponentialWait{Base: time.Millisecond * 10, Max: time.Millisecond * 100}
+
+	// When
+	delays := make([]time.Duration, 0, 6)
+	for attempt := 1; attempt <= 6; attempt++ {
+		delays = append(delays, strategy.Wait(attempt, 0))
+	}
+
+	// Then
+	require.Equal(t, []time.Duration{
+		time.Millisecond * 10,
+		time.Millisecond * 20,
+		time.Millisecond * 40,
+		time.Millisecond * 80,
+		time.Millisecond * 100,
+		time.Millisecond * 100,
+	}, delays)
+}
+
+func TestExponentialWait_Wait_Jitter(t *testing.T) {
+	// Given
+	strategy := ExponentialWait{Base: time.Millisecond * 10, Max: time.Millisecond * 100, Jitter: true}
+
+	for attempt := 1; attempt < 20; attempt++ {
+		// When
+		delay := strategy.Wait(attempt, 0)
+
+		// Then
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.LessOrEqual(t, delay, time.Millisecond*100)
+	}
+}
+
+func TestDecorrelatedJitterWait_Wait(t *testing.T) {
+	// Given
+	strategy := DecorrelatedJitterWait{Base: time.Millisecond * 10, Max: time.Millisecond * 100}
+
+	previous := time.Duration(0)
+
+	for attempt := 1; attempt < 20; attempt++ {
+		// When
+		delay := strategy.Wait(attempt, previous)
+
+		// Then
+		require.GreaterOrEqual(t, delay, time.Millisecond*10)
+		require.LessOrEqual(t, delay, min(max(previous, time.Millisecond*10)*3, time.Millisecond*100))
+
+		previous = delay
+	}
+}
+
+func TestExponentialWait_Wait_MaxNotSet(t *testing.T) {
+	// Given
+	strategy := ExponentialWait{Base: time.Millisecond * 10}
+
+	// When
+	first := strategy.Wait(1, 0)
+	last := strategy.Wait(20, 0)
+
+	// Then
+	require.Equal(t, time.Millisecond*10, first)
+	require.Equal(t, defaultWaitMax, last)
+}
+
+func TestExponentialWait_Wait_NotConfigured(t *testing.T) {
+	// Given
+	strategy := ExponentialWait{}
+
+	// When
+	delay := strategy.Wait(1, 0)
+
+	// Then
+	require.Equal(t, defaultWaitBase, delay)
+}
+
+func TestDecorrelatedJitterWait_Wait_MaxNotSet(t *testing.T) {
+	// Given
+	strategy := DecorrelatedJitterWait{Base: time.Millisecond * 10}
+
+	previous := time.Duration(0)
+
+	for attempt := 1; attempt < 20; attempt++ {
+		// When
+		delay := strategy.Wait(attempt, previous)
+
+		// Then
+		require.GreaterOrEqual(t, delay, time.Millisecond*10)
+		require.LessOrEqual(t, delay, defaultWaitMax)
+
+		previous = delay
+	}
+}
@@ -39,6 +39,16 @@ type Options struct {
 	// Lock if not nil Lock is refreshed after every call
 	// Note there are no guarantees that retrieved data is still locked by the lock
 	Lock Lock
+
+	// PageEndMarkers if true a PageEnd is published after the elements of every page
+	PageEndMarkers bool
+}
+
+// PageEnd is published on the channel after all elements of a page if the execution is configured WithPageEndMarkers.
+// Once a PageEnd is received, all elements of the page are received as well.
+type PageEnd struct {
+	// LastEvaluatedKey of the page. Empty if this was the last page
+	LastEvaluatedKey map[string]types.AttributeValue
 }
 
 // New creates a new DynamoDB query/scan executor. The executor will execute on the DynamodbClient given as client parameter.
@@ -51,8 +61,9 @@ func (e *Executor) Query(ctx context.Context, query *dynamodb.QueryInput, optFns
 	executionFn := e.queryExecution
 	getItemFn := e.queryGetItems
 	nextPageFn := e.queryNextPage
+	lastEvaluatedKeyFn := e.queryLastEvaluatedKey
 
-	return execute(ctx, query, optFns, executionFn, getItemFn, nextPageFn)
+	return execute(ctx, query, optFns, executionFn, getItemFn, nextPageFn, lastEvaluatedKeyFn)
 }
 
 // Scan executes a DynamoDB scan. The method returns a channel containing the objects or errors if the execution or unmarshalling fails
@@ -60,8 +71,9 @@ func (e *Executor) Scan(ctx context.Context, scan *dynamodb.ScanInput, optFns ..
 	executionFn := e.scanExecution
 	getItemFn := e.scanGetItems
 	nextPageFn := e.scanNextPage
+	lastEvaluatedKeyFn := e.scanLastEvaluatedKey
 
-	return execute(ctx, scan, optFns, executionFn, getItemFn, nextPageFn)
+	return execute(ctx, scan, optFns, executionFn, getItemFn, nextPageFn, lastEvaluatedKeyFn)
 }
 
 // WithMapFn returns an options modifier function that sets an unmarshalling method of a Scan or Query execution
@@ -90,6 +102,14 @@ func WithLock(lock Lock) func(options *Options) {
 	}
 }
 
+// WithPageEndMarkers will publish a PageEnd after the elements of every page. Can be used to keep track of the progress of an execution.
+// The returned options modifier function can be used in a Query or Scan execution
+func WithPageEndMarkers() func(options *Options) {
+	return func(options *Options) {
+		options.PageEndMarkers = true
+	}
+}
+
 func defaultMapFn(m map[string]types.AttributeValue) (interface{}, error) {
 	return m, nil
 }
@@ -103,7 +123,8 @@ type executionOutput interface {
 }
 
 func execute[I executionInput, R executionOutput](ctx context.Context, operation *I, optFns []func(options *Options),
-	executionFn func(context.Context, *I) (*R, error), getItemsFn func(*R) []map[string]types.AttributeValue, nextPageFn func(*I, *R) (*I, bool)) <-chan interface{} {
+	executionFn func(context.Context, *I) (*R, error), getItemsFn func(*R) []map[string]types.AttributeValue, nextPageFn func(*I, *R) (*I, bool),
+	lastEvaluatedKeyFn func(*R) map[string]types.AttributeValue) <-chan interface{} {
 	outputChannel := make(chan interface{}, 1)
 
 	go func() {
@@ -160,6 +181,13 @@ func execute[I executionInput, R executionOutput](ctx context.Context, operation
 				}
 			}
 
+			if options.PageEndMarkers {
+				success := publishOnChannel(PageEnd{LastEvaluatedKey: lastEvaluatedKeyFn(result)})
+				if !success {
+					return
+				}
+			}
+
 			var loadNextPage bool
 			operation, loadNextPage = nextPageFn(operation, result)
 
@@ -276,6 +276,51 @@ func TestExecutor_Scan_NoMapping(t *testing.T) {
 	requireChannelWithData(t, cancelFn, items, outputChannel)
 }
 
+func TestExecutor_Scan_PageEndMarkers(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctx, cancelFn := context.WithCancel(ctx)
+
+	tableName := "tablename"
+
+	items := marshalElements(t, []ElementStruct{
+		{PK: "PK1", SK: "SK1"},
+		{PK: "PK1", SK: "SK2"},
+		{PK: "PK1", SK: "SK3"},
+	})
+
+	lastEvaluatedKey := map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "PK1"},
+		"SK": &types.AttributeValueMemberS{Value: "SK2"},
+	}
+
+	initialQuery := dynamodb.ScanInput{
+		TableName: &tableName,
+	}
+
+	dynamodbClientMock := mocks.NewDynamodbClient(t)
+	dynamodbClientMock.EXPECT().Scan(ctx, &initialQuery).Return(&dynamodb.ScanOutput{Items: items[0:2], LastEvaluatedKey: lastEvaluatedKey}, nil).Once()
+
+	dynamodbClientMock.EXPECT().Scan(ctx, &dynamodb.ScanInput{
+		TableName:         &tableName,
+		ExclusiveStartKey: lastEvaluatedKey,
+	}).Return(&dynamodb.ScanOutput{Items: items[2:3]}, nil).Once()
+
+	executor := New(dynamodbClientMock)
+
+	// When
+	outputChannel := executor.Scan(ctx, &initialQuery, WithPageEndMarkers())
+
+	// Then
+	requireChannelWithData(t, cancelFn, []interface{}{
+		items[0],
+		items[1],
+		PageEnd{LastEvaluatedKey: lastEvaluatedKey},
+		items[2],
+		PageEnd{},
+	}, outputChannel)
+}
+
 func TestExecute_ErrorOnExecute(t *testing.T) {
 	// Given
 	ctx := context.Background()
@@ -287,7 +332,7 @@ func TestExecute_ErrorOnExecute(t *testing.T) {
 	}
 
 	// When
-	outputChannel := execute(ctx, &operation, nil, executeFn, nil, nil)
+	outputChannel := execute(ctx, &operation, nil, executeFn, nil, nil, nil)
 
 	// Then
 	requireChannelWithData(t, cancelFn, []error{errors.New("boom")}, outputChannel)
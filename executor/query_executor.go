@@ -24,3 +24,7 @@ func (e *Executor) queryNextPage(query *dynamodb.QueryInput, output *dynamodb.Qu
 
 	return nil, false
 }
+
+func (e *Executor) queryLastEvaluatedKey(output *dynamodb.QueryOutput) map[string]types.AttributeValue {
+	return output.LastEvaluatedKey
+}
@@ -24,3 +24,7 @@ func (e *Executor) scanNextPage(query *dynamodb.ScanInput, output *dynamodb.Scan
 
 	return nil, false
 }
+
+func (e *Executor) scanLastEvaluatedKey(output *dynamodb.ScanOutput) map[string]types.AttributeValue {
+	return output.LastEvaluatedKey
+}
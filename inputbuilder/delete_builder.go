@@ -0,0 +1,153 @@
+package inputbuilder
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/expressionutils"
+)
+
+// DeleteBuilder is a builder to create dynamodb.DeleteItemInput and types.Delete objects
+type DeleteBuilder struct {
+	TableName string
+	Key       map[string]interface{}
+
+	ConditionExpression conditionexpression.ExpressionItem
+
+	Lock Lock
+}
+
+// NewDeleteBuilder creates a new and empty DeleteBuilder
+func NewDeleteBuilder() *DeleteBuilder {
+	return &DeleteBuilder{
+		Key: make(map[string]interface{}),
+	}
+}
+
+// WithTableName sets the table name on the Delete Input object
+func (b *DeleteBuilder) WithTableName(tableName string) {
+	b.TableName = tableName
+}
+
+// WithKeyMap sets the key on the Delete Input object.
+// The key of the map represents the key attribute names. The values of the map represents the attribute values.
+// The values of the map are marshalled when the delete object is build.
+// If a value is of type types.AttributeValue, the marshaling will be skipped and the value is directly used instead.
+func (b *DeleteBuilder) WithKeyMap(key map[string]interface{}) {
+	b.Key = key
+}
+
+// WithKey appends the given key and value pair to the key defining the item to delete
+// value will be marshalled during when the delete object is build.
+// If the value is of type types.AttributeValue, the marshaling will be skipped and the value is directly used instead.
+func (b *DeleteBuilder) WithKey(attribute expressionutils.AttributePath, value interface{}) {
+	b.Key[string(attribute)] = value
+}
+
+// WithConditionExpression sets the condition expression on the Delete Input object
+func (b *DeleteBuilder) WithConditionExpression(conditionExpression conditionexpression.ExpressionItem) {
+	b.ConditionExpression = conditionExpression
+}
+
+// WithLock sets the lock that guards the delete. Use BuildLockedTransactWriteItemsInput to build a transaction that checks and refreshes the lock.
+func (b *DeleteBuilder) WithLock(lock Lock) {
+	b.Lock = lock
+}
+
+func (b *DeleteBuilder) build(tableName **string, key *map[string]types.AttributeValue, conditionExpression **string, expressionAttributeNames *map[string]string, expressionAttributeValues *map[string]types.AttributeValue) error {
+	if b.TableName == "" && *tableName == nil {
+		return errors.New("tableName may not be empty")
+	}
+
+	if len(b.Key) == 0 && len(*key) == 0 {
+		return errors.New("key may not be empty")
+	}
+
+	if b.TableName != "" {
+		*tableName = &b.TableName
+	}
+
+	if *key == nil {
+		*key = make(map[string]types.AttributeValue)
+	}
+
+	for keyAttributeName, v := range b.Key {
+		if value, ok := v.(types.AttributeValue); ok {
+			(*key)[keyAttributeName] = value
+		} else {
+			value, err := attributevalue.Marshal(v)
+			if err != nil {
+				return err
+			}
+
+			(*key)[keyAttributeName] = value
+		}
+	}
+
+	if b.ConditionExpression == nil {
+		return nil
+	}
+
+	names := make(map[string]string)
+	values := make(map[string]types.AttributeValue)
+
+	expression, err := conditionexpression.Marshal(expressionutils.EmptyPath(), b.ConditionExpression, names, values)
+	if err != nil {
+		return err
+	}
+
+	*conditionExpression = expression
+	*expressionAttributeNames = names
+
+	if len(values) > 0 {
+		*expressionAttributeValues = values
+	}
+
+	return nil
+}
+
+// BuildDeleteItemInput builds a dynamodb.DeleteItemInput object
+func (b *DeleteBuilder) BuildDeleteItemInput(input *dynamodb.DeleteItemInput) error {
+	return b.build(&input.TableName, &input.Key, &input.ConditionExpression, &input.ExpressionAttributeNames, &input.ExpressionAttributeValues)
+}
+
+// BuildDeleteTransactItem builds a types.Delete object that can be used in a dynamodb.TransactWriteItemsInput object
+func (b *DeleteBuilder) BuildDeleteTransactItem(input *types.Delete) error {
+	return b.build(&input.TableName, &input.Key, &input.ConditionExpression, &input.ExpressionAttributeNames, &input.ExpressionAttributeValues)
+}
+
+// BuildLockedTransactWriteItemsInput builds a dynamodb.TransactWriteItemsInput object that executes the delete only if the lock is still held, and refreshes the lock.
+// If the delete removes the lock item itself, the lock check is embedded in the delete. As the lock item is removed, the lock is not held anymore afterwards.
+// Otherwise, a refresh of the lock is added as separate transaction item.
+// The callback function returned as first argument should be called with the return types of the TransactWriteItems call.
+// The callback updates the lock and returns an error wrapping distrlock.ErrLockTakenOver if the transaction failed because the lock was lost.
+func (b *DeleteBuilder) BuildLockedTransactWriteItemsInput(input *dynamodb.TransactWriteItemsInput) (func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), error) {
+	if b.Lock == nil {
+		return nil, errors.New("lock may not be empty")
+	}
+
+	deleteItem := types.Delete{}
+
+	err := b.BuildDeleteTransactItem(&deleteItem)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendLockedWrite(b.Lock, input, types.TransactWriteItem{Delete: &deleteItem}, *deleteItem.TableName, deleteItem.Key, func(guardedWrite *distrlock.GuardedWrite) error {
+		err := guardExpressionAttributes(&deleteItem.ExpressionAttributeNames, &deleteItem.ExpressionAttributeValues, guardedWrite.ConditionAttributeNames, guardedWrite.ConditionAttributeValues)
+		if err != nil {
+			return err
+		}
+
+		guardCondition(&deleteItem.ConditionExpression, guardedWrite)
+
+		deleteItem.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+
+		return nil
+	})
+}
@@ -0,0 +1,125 @@
+package inputbuilder
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+)
+
+func TestDeleteBuilder_BuildDeleteItemInput(t *testing.T) {
+	// Given
+	b := NewDeleteBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.WithConditionExpression(conditionexpression.Exists("attribute1"))
+
+	input := &dynamodb.DeleteItemInput{}
+
+	// When
+	err := b.BuildDeleteItemInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, &dynamodb.DeleteItemInput{
+		TableName:                aws.String("tableName"),
+		Key:                      map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: "key"}},
+		ConditionExpression:      aws.String("attribute_exists(#attribute1)"),
+		ExpressionAttributeNames: map[string]string{"#attribute1": "attribute1"},
+	}, input)
+}
+
+func TestDeleteBuilder_BuildDeleteTransactItem_EmptyKey(t *testing.T) {
+	// Given
+	b := NewDeleteBuilder()
+	b.WithTableName("tableName")
+
+	// When
+	err := b.BuildDeleteTransactItem(&types.Delete{})
+
+	// Then
+	require.Error(t, err)
+}
+
+func TestDeleteBuilder_BuildLockedTransactWriteItemsInput(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "lockKey")
+
+	b := NewDeleteBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []types.TransactWriteItem{
+		{
+			Delete: &types.Delete{
+				TableName: aws.String("tableName"),
+				Key:       map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: "key"}},
+			},
+		},
+		lockRefresh("lockKey"),
+	}, input.TransactItems)
+
+	_, err = callback(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("None")}, {Code: aws.String("ConditionalCheckFailed")}},
+	})
+	require.ErrorIs(t, err, distrlock.ErrLockTakenOver)
+}
+
+func TestDeleteBuilder_BuildLockedTransactWriteItemsInput_LockItem(t *testing.T) {
+	// Given
+	b := NewDeleteBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.WithConditionExpression(conditionexpression.Exists("attribute1"))
+	b.WithLock(newTestLock(t, "key"))
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []types.TransactWriteItem{
+		{
+			Delete: &types.Delete{
+				TableName:                           aws.String("tableName"),
+				Key:                                 map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: "key"}},
+				ConditionExpression:                 aws.String("(attribute_exists(#attribute1)) AND #LockId = :lockId"),
+				ExpressionAttributeNames:            map[string]string{"#attribute1": "attribute1", "#LockId": "lockId"},
+				ExpressionAttributeValues:           map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: "lockId"}},
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+		},
+	}, input.TransactItems)
+
+	_, err = callback(&dynamodb.TransactWriteItemsOutput{}, nil)
+	require.NoError(t, err)
+}
+
+func TestDeleteBuilder_BuildLockedTransactWriteItemsInput_NoLock(t *testing.T) {
+	// Given
+	b := NewDeleteBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(&dynamodb.TransactWriteItemsInput{})
+
+	// Then
+	require.Error(t, err)
+	require.Nil(t, callback)
+}
@@ -0,0 +1,90 @@
+package inputbuilder
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock"
+)
+
+// Lock is a held distributed lock that guards a write
+type Lock interface {
+	GuardTransactWrite(tableName string, key map[string]types.AttributeValue, index int) (*distrlock.GuardedWrite, func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), bool)
+	TransactionWithRefresh() (types.TransactWriteItem, func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error))
+}
+
+var _ Lock = (*distrlock.Lock)(nil)
+
+// appendLockedWrite appends the write to the transaction, guarded by the lock.
+// If the write targets the lock item itself, guard embeds the lock check and the lease extension in the write. Otherwise, a refresh of the lock is appended as separate transaction item.
+func appendLockedWrite(lock Lock, input *dynamodb.TransactWriteItemsInput, write types.TransactWriteItem, tableName string, key map[string]types.AttributeValue, guard func(guardedWrite *distrlock.GuardedWrite) error) (func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), error) {
+	writeIndex := len(input.TransactItems)
+
+	guardedWrite, guardCallback, ok := lock.GuardTransactWrite(tableName, key, writeIndex)
+	if ok {
+		err := guard(guardedWrite)
+		if err != nil {
+			_, _ = guardCallback(nil, err)
+
+			return nil, err
+		}
+
+		input.TransactItems = append(input.TransactItems, write)
+
+		return guardCallback, nil
+	}
+
+	lockItem, lockCallback := lock.TransactionWithRefresh()
+
+	lockIndex := writeIndex + 1
+	input.TransactItems = append(input.TransactItems, write, lockItem)
+
+	return func(output *dynamodb.TransactWriteItemsOutput, err error) (*dynamodb.TransactWriteItemsOutput, error) {
+		output, err = lockCallback(output, err)
+
+		return output, distrlock.TransactionLockError(err, lockIndex)
+	}, nil
+}
+
+// guardCondition combines the condition expression of the write with the lock condition
+func guardCondition(conditionExpression **string, guardedWrite *distrlock.GuardedWrite) {
+	if *conditionExpression == nil {
+		*conditionExpression = aws.String(guardedWrite.ConditionExpression)
+
+		return
+	}
+
+	*conditionExpression = aws.String("(" + **conditionExpression + ") AND " + guardedWrite.ConditionExpression)
+}
+
+// guardExpressionAttributes adds the expression attribute names and values of the lock to the write
+func guardExpressionAttributes(expressionAttributeNames *map[string]string, expressionAttributeValues *map[string]types.AttributeValue, lockAttributeNames map[string]string, lockAttributeValues map[string]types.AttributeValue) error {
+	if *expressionAttributeNames == nil {
+		*expressionAttributeNames = make(map[string]string)
+	}
+
+	if *expressionAttributeValues == nil {
+		*expressionAttributeValues = make(map[string]types.AttributeValue)
+	}
+
+	for name, attributeName := range lockAttributeNames {
+		if _, found := (*expressionAttributeNames)[name]; found {
+			return fmt.Errorf("expression attribute name %s is used by the lock", name)
+		}
+
+		(*expressionAttributeNames)[name] = attributeName
+	}
+
+	for name, value := range lockAttributeValues {
+		if _, found := (*expressionAttributeValues)[name]; found {
+			return fmt.Errorf("expression attribute value %s is used by the lock", name)
+		}
+
+		(*expressionAttributeValues)[name] = value
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package inputbuilder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/expressionutils"
+)
+
+// PutBuilder is a builder to create dynamodb.PutItemInput and types.Put objects
+type PutBuilder struct {
+	TableName string
+	Item      map[string]interface{}
+
+	ConditionExpression conditionexpression.ExpressionItem
+
+	Lock Lock
+}
+
+// NewPutBuilder creates a new and empty PutBuilder
+func NewPutBuilder() *PutBuilder {
+	return &PutBuilder{
+		Item: make(map[string]interface{}),
+	}
+}
+
+// WithTableName sets the table name on the Put Input object
+func (b *PutBuilder) WithTableName(tableName string) {
+	b.TableName = tableName
+}
+
+// WithItem sets the item to put.
+// The key of the map represents the attribute names. The values of the map represents the attribute values.
+// The values of the map are marshalled when the put object is build.
+// If a value is of type types.AttributeValue, the marshaling will be skipped and the value is directly used instead.
+func (b *PutBuilder) WithItem(item map[string]interface{}) {
+	b.Item = item
+}
+
+// WithAttribute appends the given attribute and value pair to the item to put
+// value will be marshalled during when the put object is build.
+// If the value is of type types.AttributeValue, the marshaling will be skipped and the value is directly used instead.
+func (b *PutBuilder) WithAttribute(attribute expressionutils.AttributePath, value interface{}) {
+	b.Item[string(attribute)] = value
+}
+
+// WithConditionExpression sets the condition expression on the Put Input object
+func (b *PutBuilder) WithConditionExpression(conditionExpression conditionexpression.ExpressionItem) {
+	b.ConditionExpression = conditionExpression
+}
+
+// WithLock sets the lock that guards the put. Use BuildLockedTransactWriteItemsInput to build a transaction that checks and refreshes the lock.
+func (b *PutBuilder) WithLock(lock Lock) {
+	b.Lock = lock
+}
+
+func (b *PutBuilder) build(tableName **string, item *map[string]types.AttributeValue, conditionExpression **string, expressionAttributeNames *map[string]string, expressionAttributeValues *map[string]types.AttributeValue) error {
+	if b.TableName == "" && *tableName == nil {
+		return errors.New("tableName may not be empty")
+	}
+
+	if len(b.Item) == 0 && len(*item) == 0 {
+		return errors.New("item may not be empty")
+	}
+
+	if b.TableName != "" {
+		*tableName = &b.TableName
+	}
+
+	if *item == nil {
+		*item = make(map[string]types.AttributeValue)
+	}
+
+	for attributeName, v := range b.Item {
+		if value, ok := v.(types.AttributeValue); ok {
+			(*item)[attributeName] = value
+		} else {
+			value, err := attributevalue.Marshal(v)
+			if err != nil {
+				return err
+			}
+
+			(*item)[attributeName] = value
+		}
+	}
+
+	if b.ConditionExpression == nil {
+		return nil
+	}
+
+	names := make(map[string]string)
+	values := make(map[string]types.AttributeValue)
+
+	expression, err := conditionexpression.Marshal(expressionutils.EmptyPath(), b.ConditionExpression, names, values)
+	if err != nil {
+		return err
+	}
+
+	*conditionExpression = expression
+	*expressionAttributeNames = names
+
+	if len(values) > 0 {
+		*expressionAttributeValues = values
+	}
+
+	return nil
+}
+
+// BuildPutItemInput builds a dynamodb.PutItemInput object
+func (b *PutBuilder) BuildPutItemInput(input *dynamodb.PutItemInput) error {
+	return b.build(&input.TableName, &input.Item, &input.ConditionExpression, &input.ExpressionAttributeNames, &input.ExpressionAttributeValues)
+}
+
+// BuildPutTransactItem builds a types.Put object that can be used in a dynamodb.TransactWriteItemsInput object
+func (b *PutBuilder) BuildPutTransactItem(input *types.Put) error {
+	return b.build(&input.TableName, &input.Item, &input.ConditionExpression, &input.ExpressionAttributeNames, &input.ExpressionAttributeValues)
+}
+
+// BuildLockedTransactWriteItemsInput builds a dynamodb.TransactWriteItemsInput object that executes the put only if the lock is still held, and refreshes the lock.
+// If the put replaces the lock item itself, the lock check is embedded in the put and the item includes the refreshed lock attributes. The lock item of a reentrant lock can not be replaced.
+// Otherwise, a refresh of the lock is added as separate transaction item.
+// The callback function returned as first argument should be called with the return types of the TransactWriteItems call.
+// The callback updates the lock and returns an error wrapping distrlock.ErrLockTakenOver if the transaction failed because the lock was lost.
+func (b *PutBuilder) BuildLockedTransactWriteItemsInput(input *dynamodb.TransactWriteItemsInput) (func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), error) {
+	if b.Lock == nil {
+		return nil, errors.New("lock may not be empty")
+	}
+
+	put := types.Put{}
+
+	err := b.BuildPutTransactItem(&put)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendLockedWrite(b.Lock, input, types.TransactWriteItem{Put: &put}, *put.TableName, put.Item, func(guardedWrite *distrlock.GuardedWrite) error {
+		if guardedWrite.Attributes == nil {
+			return errors.New("the lock item of a reentrant lock can not be replaced")
+		}
+
+		for attributeName, value := range guardedWrite.Attributes {
+			if _, found := put.Item[attributeName]; found {
+				return fmt.Errorf("attribute %s is written by the lock", attributeName)
+			}
+
+			put.Item[attributeName] = value
+		}
+
+		err := guardExpressionAttributes(&put.ExpressionAttributeNames, &put.ExpressionAttributeValues, guardedWrite.ConditionAttributeNames, guardedWrite.ConditionAttributeValues)
+		if err != nil {
+			return err
+		}
+
+		guardCondition(&put.ConditionExpression, guardedWrite)
+
+		put.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+
+		return nil
+	})
+}
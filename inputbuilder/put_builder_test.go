@@ -0,0 +1,172 @@
+package inputbuilder
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+)
+
+func TestPutBuilder_BuildPutItemInput(t *testing.T) {
+	// Given
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+	b.WithAttribute("key", "key")
+	b.WithAttribute("attribute1", 5)
+	b.WithConditionExpression(conditionexpression.NotExists("key"))
+
+	input := &dynamodb.PutItemInput{}
+
+	// When
+	err := b.BuildPutItemInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, &dynamodb.PutItemInput{
+		TableName: aws.String("tableName"),
+		Item: map[string]types.AttributeValue{
+			"key":        &types.AttributeValueMemberS{Value: "key"},
+			"attribute1": &types.AttributeValueMemberN{Value: "5"},
+		},
+		ConditionExpression:      aws.String("attribute_not_exists(#key)"),
+		ExpressionAttributeNames: map[string]string{"#key": "key"},
+	}, input)
+}
+
+func TestPutBuilder_BuildPutTransactItem_EmptyItem(t *testing.T) {
+	// Given
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+
+	// When
+	err := b.BuildPutTransactItem(&types.Put{})
+
+	// Then
+	require.Error(t, err)
+}
+
+func TestPutBuilder_BuildLockedTransactWriteItemsInput(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "lockKey")
+
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+	b.WithAttribute("key", "key")
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []types.TransactWriteItem{
+		{
+			Put: &types.Put{
+				TableName: aws.String("tableName"),
+				Item:      map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: "key"}},
+			},
+		},
+		lockRefresh("lockKey"),
+	}, input.TransactItems)
+
+	_, err = callback(&dynamodb.TransactWriteItemsOutput{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "newLockId", lock.LockId())
+}
+
+func TestPutBuilder_BuildLockedTransactWriteItemsInput_LockItem(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "key")
+
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+	b.WithAttribute("key", "key")
+	b.WithAttribute("attribute1", "value1")
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, input.TransactItems, 1)
+
+	put := input.TransactItems[0].Put
+	require.Equal(t, &types.AttributeValueMemberS{Value: "value1"}, put.Item["attribute1"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "newLockId"}, put.Item["lockId"])
+	require.Contains(t, put.Item, "timeout")
+	require.Equal(t, "#LockId = :lockId", *put.ConditionExpression)
+	require.Equal(t, map[string]string{"#LockId": "lockId"}, put.ExpressionAttributeNames)
+	require.Equal(t, map[string]types.AttributeValue{":lockId": &types.AttributeValueMemberS{Value: "lockId"}}, put.ExpressionAttributeValues)
+	require.Equal(t, types.ReturnValuesOnConditionCheckFailureAllOld, put.ReturnValuesOnConditionCheckFailure)
+
+	_, err = callback(&dynamodb.TransactWriteItemsOutput{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "newLockId", lock.LockId())
+}
+
+func TestPutBuilder_BuildLockedTransactWriteItemsInput_LockAttribute(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "key")
+
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+	b.WithAttribute("key", "key")
+	b.WithAttribute("lockId", "value")
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.ErrorContains(t, err, "attribute lockId is written by the lock")
+	require.Nil(t, callback)
+	require.Empty(t, input.TransactItems)
+	require.Equal(t, "lockId", lock.LockId())
+}
+
+func TestPutBuilder_BuildLockedTransactWriteItemsInput_NoLock(t *testing.T) {
+	// Given
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+	b.WithAttribute("key", "key")
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(&dynamodb.TransactWriteItemsInput{})
+
+	// Then
+	require.Error(t, err)
+	require.Nil(t, callback)
+}
+
+func TestPutBuilder_BuildLockedTransactWriteItemsInput_LockLost(t *testing.T) {
+	// Given
+	b := NewPutBuilder()
+	b.WithTableName("tableName")
+	b.WithAttribute("key", "key")
+	b.WithLock(newTestLock(t, "key"))
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+	require.NoError(t, err)
+
+	// When
+	_, err = callback(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+	})
+
+	// Then
+	require.ErrorIs(t, err, distrlock.ErrLockTakenOver)
+}
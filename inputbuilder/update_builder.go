@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
+	"github.com/raito-io/go-dynamo-utils/distrlock"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/expressionutils"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/updateexpression"
@@ -27,6 +28,8 @@ type UpdateBuilder struct {
 	Remove []expressionutils.AttributePath
 
 	ConditionExpression conditionexpression.ExpressionItem
+
+	Lock Lock
 }
 
 // NewUpdateBuilder creates a new and empty UpdateBuilder
@@ -85,6 +88,11 @@ func (b *UpdateBuilder) WithConditionExpression(conditionExpression conditionexp
 	b.ConditionExpression = conditionExpression
 }
 
+// WithLock sets the lock that guards the update. Use BuildLockedTransactWriteItemsInput to build a transaction that checks and refreshes the lock.
+func (b *UpdateBuilder) WithLock(lock Lock) {
+	b.Lock = lock
+}
+
 func (b *UpdateBuilder) build(tableName **string, key *map[string]types.AttributeValue, updateExpression **string, conditionExpression **string, expressionAttributeNames *map[string]string, expressionAttributeValues *map[string]types.AttributeValue) error { //nolint:gocritic
 	if b.TableName == "" && *tableName == nil {
 		return errors.New("tableName may not be empty")
@@ -285,3 +293,49 @@ func (b *UpdateBuilder) BuildUpdateTransactItem(input *types.Update) error {
 
 	return err
 }
+
+// BuildLockedTransactWriteItemsInput builds a dynamodb.TransactWriteItemsInput object that executes the update only if the lock is still held, and refreshes the lock.
+// If the update targets the lock item itself, the lock check and refresh are embedded in the update. Otherwise, a refresh of the lock is added as separate transaction item.
+// The callback function returned as first argument should be called with the return types of the TransactWriteItems call.
+// The callback updates the lock and returns an error wrapping distrlock.ErrLockTakenOver if the transaction failed because the lock was lost.
+func (b *UpdateBuilder) BuildLockedTransactWriteItemsInput(input *dynamodb.TransactWriteItemsInput) (func(*dynamodb.TransactWriteItemsOutput, error) (*dynamodb.TransactWriteItemsOutput, error), error) {
+	if b.Lock == nil {
+		return nil, errors.New("lock may not be empty")
+	}
+
+	update := types.Update{}
+
+	err := b.BuildUpdateTransactItem(&update)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendLockedWrite(b.Lock, input, types.TransactWriteItem{Update: &update}, *update.TableName, update.Key, func(guardedWrite *distrlock.GuardedWrite) error {
+		err := guardExpressionAttributes(&update.ExpressionAttributeNames, &update.ExpressionAttributeValues, guardedWrite.ConditionAttributeNames, guardedWrite.ConditionAttributeValues)
+		if err != nil {
+			return err
+		}
+
+		err = guardExpressionAttributes(&update.ExpressionAttributeNames, &update.ExpressionAttributeValues, guardedWrite.SetAttributeNames, guardedWrite.SetAttributeValues)
+		if err != nil {
+			return err
+		}
+
+		guardCondition(&update.ConditionExpression, guardedWrite)
+
+		setOperations := "SET " + strings.Join(guardedWrite.SetOperations, ", ")
+
+		switch updateExpression := aws.ToString(update.UpdateExpression); {
+		case updateExpression == "":
+			update.UpdateExpression = &setOperations
+		case strings.HasPrefix(updateExpression, "SET "):
+			update.UpdateExpression = aws.String(setOperations + ", " + strings.TrimPrefix(updateExpression, "SET "))
+		default:
+			update.UpdateExpression = aws.String(setOperations + " " + updateExpression)
+		}
+
+		update.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+
+		return nil
+	})
+}
@@ -1,14 +1,18 @@
 package inputbuilder
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/raito-io/go-dynamo-utils/distrlock"
+	"github.com/raito-io/go-dynamo-utils/distrlock/mocks"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/expressionutils"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/updateexpression"
@@ -223,3 +227,224 @@ func Test_marshalList(t *testing.T) {
 		})
 	}
 }
+
+// newTestLock acquires a distrlock.Lock on the item with the given key in table tableName, of which the key attribute is named key
+func newTestLock(t *testing.T, key string) *distrlock.Lock {
+	t.Helper()
+
+	idGenerator := mocks.NewIdGenerator(t)
+	idGenerator.EXPECT().ID().Return("lockId").Once()
+	idGenerator.EXPECT().ID().Return("newLockId").Maybe()
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	handler := distrlock.New(client, "tableName", "key", func(options *distrlock.Options) {
+		options.IdGenerator = idGenerator
+	})
+
+	lock, success, err := handler.TryLock(context.Background(), &types.AttributeValueMemberS{Value: key})
+	require.NoError(t, err)
+	require.True(t, success)
+
+	return lock
+}
+
+// lockRefresh is the transaction item of a refresh of the lock created by newTestLock
+func lockRefresh(key string) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:                aws.String("tableName"),
+			Key:                      map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: key}},
+			ConditionExpression:      aws.String("#LockId = :lockId"),
+			UpdateExpression:         aws.String("SET #LockId = :newLockId"),
+			ExpressionAttributeNames: map[string]string{"#LockId": "lockId"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":lockId":    &types.AttributeValueMemberS{Value: "lockId"},
+				":newLockId": &types.AttributeValueMemberS{Value: "newLockId"},
+			},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureNone,
+		},
+	}
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "lockKey")
+
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.AppendSet(updateexpression.Set("attribute1", "value1"))
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:                 aws.String("tableName"),
+				Key:                       map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: "key"}},
+				ExpressionAttributeNames:  map[string]string{"#attribute1": "attribute1"},
+				ExpressionAttributeValues: map[string]types.AttributeValue{":set_attribute1": &types.AttributeValueMemberS{Value: "value1"}},
+				UpdateExpression:          aws.String("SET #attribute1 = :set_attribute1"),
+			},
+		},
+		lockRefresh("lockKey"),
+	}, input.TransactItems)
+
+	_, err = callback(&dynamodb.TransactWriteItemsOutput{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "newLockId", lock.LockId())
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput_LockItem(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "key")
+
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.AppendSet(updateexpression.Set("attribute1", "value1"))
+	b.AppendRemove("attribute2")
+	b.WithConditionExpression(conditionexpression.Exists("attribute1"))
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:                aws.String("tableName"),
+				Key:                      map[string]types.AttributeValue{"key": &types.AttributeValueMemberS{Value: "key"}},
+				ExpressionAttributeNames: map[string]string{"#attribute1": "attribute1", "#attribute2": "attribute2", "#LockId": "lockId", "#A0": "lockId"},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":set_attribute1": &types.AttributeValueMemberS{Value: "value1"},
+					":lockId":         &types.AttributeValueMemberS{Value: "lockId"},
+					":a0":             &types.AttributeValueMemberS{Value: "newLockId"},
+				},
+				UpdateExpression:                    aws.String("SET #A0 = :a0, #attribute1 = :set_attribute1 REMOVE #attribute2"),
+				ConditionExpression:                 aws.String("(attribute_exists(#attribute1)) AND #LockId = :lockId"),
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+		},
+	}, input.TransactItems)
+
+	_, err = callback(&dynamodb.TransactWriteItemsOutput{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "newLockId", lock.LockId())
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput_LockItemWithoutSet(t *testing.T) {
+	// Given
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.AppendAdd(updateexpression.Add("attribute1", 1))
+	b.WithLock(newTestLock(t, "key"))
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	// When
+	_, err := b.BuildLockedTransactWriteItemsInput(input)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, input.TransactItems, 1)
+	require.Equal(t, "SET #A0 = :a0 ADD #attribute1 :add_attribute1", *input.TransactItems[0].Update.UpdateExpression)
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput_LockLost(t *testing.T) {
+	// Given
+	lock := newTestLock(t, "lockKey")
+
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.AppendSet(updateexpression.Set("attribute1", "value1"))
+	b.WithLock(lock)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+	require.NoError(t, err)
+
+	// When
+	_, err = callback(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("None")}, {Code: aws.String("ConditionalCheckFailed")}},
+	})
+
+	// Then
+	require.ErrorIs(t, err, distrlock.ErrLockTakenOver)
+	require.Equal(t, "lockId", lock.LockId())
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput_LockItemLost(t *testing.T) {
+	// Given
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.AppendSet(updateexpression.Set("attribute1", "value1"))
+	b.WithLock(newTestLock(t, "key"))
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+	require.NoError(t, err)
+
+	// When
+	_, lostErr := callback(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{"lockId": &types.AttributeValueMemberS{Value: "otherLockId"}}}},
+	})
+
+	// Then
+	require.ErrorIs(t, lostErr, distrlock.ErrLockTakenOver)
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput_ConditionFailed(t *testing.T) {
+	// Given
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+	b.AppendSet(updateexpression.Set("attribute1", "value1"))
+	b.WithConditionExpression(conditionexpression.Exists("attribute1"))
+	b.WithLock(newTestLock(t, "key"))
+
+	input := &dynamodb.TransactWriteItemsInput{}
+
+	callback, err := b.BuildLockedTransactWriteItemsInput(input)
+	require.NoError(t, err)
+
+	// When
+	_, err = callback(nil, &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed"), Item: map[string]types.AttributeValue{"lockId": &types.AttributeValueMemberS{Value: "lockId"}}}},
+	})
+
+	// Then
+	require.Error(t, err)
+	require.NotErrorIs(t, err, distrlock.ErrLockTakenOver)
+}
+
+func TestUpdateBuilder_BuildLockedTransactWriteItemsInput_NoLock(t *testing.T) {
+	// Given
+	b := NewUpdateBuilder()
+	b.WithTableName("tableName")
+	b.WithKey("key", "key")
+
+	// When
+	callback, err := b.BuildLockedTransactWriteItemsInput(&dynamodb.TransactWriteItemsInput{})
+
+	// Then
+	require.Error(t, err)
+	require.Nil(t, callback)
+}
@@ -0,0 +1,217 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const attemptPKPrefix = "ATTEMPT#"
+
+// maxRecordedAttempts is the number of most recent attempts that is kept in the history of a migration, so the attempts item stays far below the maximum item size of DynamoDB
+const maxRecordedAttempts = 20
+
+// maxAttemptErrorLength is the maximum number of bytes of the error message that is recorded for an attempt
+const maxAttemptErrorLength = 4096
+
+type AttemptStatus string
+
+const (
+	AttemptStatusRunning   AttemptStatus = "running"
+	AttemptStatusSucceeded AttemptStatus = "succeeded"
+	AttemptStatusFailed    AttemptStatus = "failed"
+)
+
+// AttemptHistory configures the recording of migration attempts
+type AttemptHistory struct {
+	// Host that executes the migrations
+	Host string
+}
+
+// Attempt is a single execution attempt of a migration
+type Attempt struct {
+	ID             uint64
+	Attempt        int
+	Status         AttemptStatus
+	StartTime      time.Time
+	EndTime        *time.Time
+	Error          string
+	Host           string
+	ItemsProcessed int64
+
+	// ItemErrors number of items that failed and were skipped or collected according to the ErrorPolicy of the migration
+	ItemErrors int64
+}
+
+// WithAttemptHistory records every attempt to execute a migration in the migration metadata table: start and end time, status, error message, host and the number of processed items.
+// Only the 20 most recent attempts of a migration are kept and error messages are truncated to 4KB.
+// The host defaults to the hostname of the machine. Migrations can report processed items with AddItemsProcessed.
+func (m *Migrator) WithAttemptHistory() *Migrator {
+	host, _ := os.Hostname()
+
+	m.AttemptHistory = &AttemptHistory{Host: host}
+
+	return m
+}
+
+// Attempts returns the recorded attempts to execute the migration with the given ID, oldest first.
+// Only the most recent attempts are kept. They are loaded with a single read, as all attempts of a migration are stored in the same item.
+func (m *Migrator) Attempts(ctx context.Context, client DynamodbClient, id uint64) ([]Attempt, error) {
+	result, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &m.MigrationTableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: attemptPK(id)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var history attemptHistoryObject
+
+	err = attributevalue.UnmarshalMap(result.Item, &history)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]Attempt, 0)
+
+	for _, attempt := range history.History {
+		attempts = append(attempts, Attempt{
+			ID:             attempt.ID,
+			Attempt:        attempt.Attempt,
+			Status:         attempt.Status,
+			StartTime:      attempt.StartTime,
+			EndTime:        attempt.EndTime,
+			Error:          attempt.Error,
+			Host:           attempt.Host,
+			ItemsProcessed: attempt.ItemsProcessed,
+			ItemErrors:     attempt.ItemErrors,
+		})
+	}
+
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].Attempt < attempts[j].Attempt
+	})
+
+	return attempts, nil
+}
+
+// startAttempt records a running attempt. Nil is returned if attempt history is disabled.
+func (m *Migrator) startAttempt(ctx context.Context, client DynamodbClient, id uint64, startTime time.Time) (*attemptObject, error) {
+	if m.AttemptHistory == nil {
+		return nil, nil
+	}
+
+	result, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &m.MigrationTableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: attemptPK(id)},
+		},
+		UpdateExpression:          aws.String("ADD #attempts :one SET #history = if_not_exists(#history, :emptyHistory)"),
+		ExpressionAttributeNames:  map[string]string{"#attempts": "attempts", "#history": "history"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}, ":emptyHistory": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var history attemptHistoryObject
+
+	err = attributevalue.UnmarshalMap(result.Attributes, &history)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := &attemptObject{
+		ID:        id,
+		Attempt:   history.Attempts,
+		Status:    AttemptStatusRunning,
+		StartTime: startTime,
+		Host:      m.AttemptHistory.Host,
+	}
+
+	return attempt, m.putAttempt(ctx, client, attempt)
+}
+
+// finishAttempt records the result of an attempt. Nothing happens if attempt is nil.
+func (m *Migrator) finishAttempt(ctx context.Context, client DynamodbClient, attempt *attemptObject, run *migrationRun, migrationErr error) error {
+	if attempt == nil {
+		return nil
+	}
+
+	endTime := time.Now()
+
+	attempt.EndTime = &endTime
+	attempt.ItemsProcessed = run.processedItems()
+	attempt.ItemErrors = run.failedItems()
+	attempt.Status = AttemptStatusSucceeded
+
+	if migrationErr != nil {
+		attempt.Status = AttemptStatusFailed
+		attempt.Error = truncateError(migrationErr.Error())
+	}
+
+	// The attempt is recorded even if the context of the migration is cancelled
+	return m.putAttempt(context.WithoutCancel(ctx), client, attempt)
+}
+
+// putAttempt stores the attempt in the history of the attempts item of the migration.
+// The attempt that no longer belongs to the most recent attempts is removed from the history.
+func (m *Migrator) putAttempt(ctx context.Context, client DynamodbClient, attempt *attemptObject) error {
+	value, err := attributevalue.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	updateExpression := "SET #history.#attempt = :attempt"
+	expressionAttributeNames := map[string]string{"#history": "history", "#attempt": strconv.Itoa(attempt.Attempt)}
+
+	if attempt.Attempt > maxRecordedAttempts {
+		updateExpression += " REMOVE #history.#expired"
+		expressionAttributeNames["#expired"] = strconv.Itoa(attempt.Attempt - maxRecordedAttempts)
+	}
+
+	_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &m.MigrationTableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: attemptPK(attempt.ID)},
+		},
+		UpdateExpression:          &updateExpression,
+		ExpressionAttributeNames:  expressionAttributeNames,
+		ExpressionAttributeValues: map[string]types.AttributeValue{":attempt": value},
+	})
+
+	return err
+}
+
+// truncateError truncates the error message to maxAttemptErrorLength bytes without splitting a character
+func truncateError(message string) string {
+	const suffix = "... (truncated)"
+
+	if len(message) <= maxAttemptErrorLength {
+		return message
+	}
+
+	end := maxAttemptErrorLength - len(suffix)
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+
+	return message[:end] + suffix
+}
+
+func attemptPK(id uint64) string {
+	return fmt.Sprintf("%s%d", attemptPKPrefix, id)
+}
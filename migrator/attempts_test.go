@@ -0,0 +1,245 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func expectAttemptCounter(client *mocks.DynamodbClient, migrationTable string, pk string, attempts string) {
+	client.EXPECT().UpdateItem(mock.Anything, &dynamodb.UpdateItemInput{
+		TableName: &migrationTable,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+		},
+		UpdateExpression:          aws.String("ADD #attempts :one SET #history = if_not_exists(#history, :emptyHistory)"),
+		ExpressionAttributeNames:  map[string]string{"#attempts": "attempts", "#history": "history"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}, ":emptyHistory": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	}).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]types.AttributeValue{
+		"attempts": &types.AttributeValueMemberN{Value: attempts},
+		"history":  &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+	}}, nil).Once()
+}
+
+func expectAttempts(t *testing.T, client *mocks.DynamodbClient, migrationTable string, id uint64, attempts ...attemptObject) {
+	t.Helper()
+
+	var item map[string]types.AttributeValue
+
+	if len(attempts) > 0 {
+		history := make(map[string]types.AttributeValue, len(attempts))
+
+		for _, attempt := range attempts {
+			value, err := attributevalue.Marshal(attempt)
+			require.NoError(t, err)
+
+			history[strconv.Itoa(attempt.Attempt)] = value
+		}
+
+		item = map[string]types.AttributeValue{
+			"PK":       &types.AttributeValueMemberS{Value: fmt.Sprintf("ATTEMPT#%d", id)},
+			"attempts": &types.AttributeValueMemberN{Value: strconv.Itoa(len(attempts))},
+			"history":  &types.AttributeValueMemberM{Value: history},
+		}
+	}
+
+	client.EXPECT().GetItem(mock.Anything, &dynamodb.GetItemInput{
+		TableName:      &migrationTable,
+		Key:            map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("ATTEMPT#%d", id)}},
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+}
+
+// captureAttempts captures the attempts stored in the history of the attempts item
+func captureAttempts(client *mocks.DynamodbClient, times int) *[]*dynamodb.UpdateItemInput {
+	var updates []*dynamodb.UpdateItemInput
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.MatchedBy(func(params *dynamodb.UpdateItemInput) bool {
+		return strings.HasPrefix(aws.ToString(params.UpdateExpression), "SET #history.#attempt = :attempt")
+	})).Run(func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) {
+		updates = append(updates, params)
+	}).Return(&dynamodb.UpdateItemOutput{}, nil).Times(times)
+
+	return &updates
+}
+
+func unmarshalAttempts(t *testing.T, updates []*dynamodb.UpdateItemInput) []attemptObject {
+	t.Helper()
+
+	attempts := make([]attemptObject, 0, len(updates))
+
+	for _, update := range updates {
+		var attempt attemptObject
+		require.NoError(t, attributevalue.Unmarshal(update.ExpressionAttributeValues[":attempt"], &attempt))
+		require.Equal(t, strconv.Itoa(attempt.Attempt), update.ExpressionAttributeNames["#attempt"])
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts
+}
+
+func TestMigrator_Execute_RecordsSuccessfulAttempt(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectAttemptCounter(client, migrationTable, "ATTEMPT#1", "1")
+
+	updates := captureAttempts(client, 2)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name: "migration_1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			AddItemsProcessed(ctx, 42)
+
+			return nil
+		},
+	}).WithAttemptHistory()
+	migrator.AttemptHistory.Host = "host_1"
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+
+	attempts := unmarshalAttempts(t, *updates)
+	require.Len(t, attempts, 2)
+
+	require.Equal(t, 1, attempts[0].Attempt)
+	require.Equal(t, AttemptStatusRunning, attempts[0].Status)
+	require.Equal(t, "host_1", attempts[0].Host)
+	require.Nil(t, attempts[0].EndTime)
+
+	require.Equal(t, 1, attempts[1].Attempt)
+	require.Equal(t, AttemptStatusSucceeded, attempts[1].Status)
+	require.Equal(t, int64(42), attempts[1].ItemsProcessed)
+	require.NotNil(t, attempts[1].EndTime)
+	require.Empty(t, attempts[1].Error)
+}
+
+func TestMigrator_Execute_RecordsFailedAttempt(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectAttemptCounter(client, migrationTable, "ATTEMPT#1", "3")
+
+	updates := captureAttempts(client, 2)
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name: "migration_1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			ProgressReporterFromContext(ctx).AddFailed(2)
+
+			return errors.New("boom")
+		},
+	}).WithAttemptHistory()
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "running migration migration_1: boom")
+
+	attempts := unmarshalAttempts(t, *updates)
+	require.Len(t, attempts, 2)
+
+	require.Equal(t, 3, attempts[1].Attempt)
+	require.Equal(t, AttemptStatusFailed, attempts[1].Status)
+	require.Equal(t, "running migration migration_1: boom", attempts[1].Error)
+	require.Equal(t, int64(2), attempts[1].ItemErrors)
+}
+
+func TestMigrator_Execute_RemovesExpiredAttempt(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectAttemptCounter(client, migrationTable, "ATTEMPT#1", "25")
+
+	updates := captureAttempts(client, 2)
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name: "migration_1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			return errors.New(strings.Repeat("boom ", 2000))
+		},
+	}).WithAttemptHistory()
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.Error(t, err)
+	require.Len(t, *updates, 2)
+
+	for _, update := range *updates {
+		require.Equal(t, "SET #history.#attempt = :attempt REMOVE #history.#expired", aws.ToString(update.UpdateExpression))
+		require.Equal(t, "5", update.ExpressionAttributeNames["#expired"])
+	}
+
+	attempts := unmarshalAttempts(t, *updates)
+	require.Equal(t, 25, attempts[1].Attempt)
+	require.Len(t, attempts[1].Error, maxAttemptErrorLength)
+	require.True(t, strings.HasSuffix(attempts[1].Error, "(truncated)"))
+}
+
+func TestTruncateError(t *testing.T) {
+	// Given
+	message := strings.Repeat("é", maxAttemptErrorLength)
+
+	// When
+	truncated := truncateError(message)
+
+	// Then
+	require.LessOrEqual(t, len(truncated), maxAttemptErrorLength)
+	require.True(t, utf8.ValidString(truncated))
+	require.Equal(t, "short", truncateError("short"))
+}
+
+func TestMigrator_Attempts(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client := mocks.NewDynamodbClient(t)
+	expectAttempts(t, client, migrationTable, 2,
+		attemptObject{ID: 2, Attempt: 2, Status: AttemptStatusSucceeded, StartTime: startTime},
+		attemptObject{ID: 2, Attempt: 1, Status: AttemptStatusFailed, StartTime: startTime},
+	)
+
+	migrator := NewMigrator(migrationTable, 0)
+
+	// When
+	attempts, err := migrator.Attempts(context.Background(), client, 2)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []Attempt{
+		{ID: 2, Attempt: 1, Status: AttemptStatusFailed, StartTime: startTime},
+		{ID: 2, Attempt: 2, Status: AttemptStatusSucceeded, StartTime: startTime},
+	}, attempts)
+}
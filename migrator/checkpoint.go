@@ -0,0 +1,255 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const checkpointPKPrefix = "CHECKPOINT#"
+
+// segmentProgress is the progress of a single scan segment
+type segmentProgress struct {
+	LastEvaluatedKey map[string]types.AttributeValue
+	Done             bool
+
+	// Count number of items counted by the migration in the segment, up to the LastEvaluatedKey
+	Count int64
+}
+
+// scanCheckpoint keeps track of the progress of a scan migration and periodically stores it in the migration metadata table.
+// All methods are safe for concurrent use and no-ops on a nil scanCheckpoint.
+type scanCheckpoint struct {
+	run           *migrationRun
+	interval      time.Duration
+	totalSegments int
+
+	mutex     sync.Mutex
+	segments  map[int]*segmentProgress
+	lastSaved time.Time
+}
+
+// loadScanCheckpoint loads the stored progress of the migration that is being executed.
+// Nil is returned if the migration is not executed by a Migrator or checkpoints are disabled.
+// A stored checkpoint for a different number of segments is ignored.
+func loadScanCheckpoint(ctx context.Context, totalSegments int, interval time.Duration) (*scanCheckpoint, error) {
+	run := migrationRunFromContext(ctx)
+	if run == nil || interval <= 0 {
+		return nil, nil
+	}
+
+	checkpoint := &scanCheckpoint{
+		run:           run,
+		interval:      interval,
+		totalSegments: totalSegments,
+		segments:      make(map[int]*segmentProgress, totalSegments),
+		lastSaved:     time.Now(),
+	}
+
+	result, err := run.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &run.tableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: run.checkpointPK()},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading checkpoint: %w", err)
+	}
+
+	if result.Item == nil {
+		return checkpoint, nil
+	}
+
+	run.markCheckpointed()
+
+	if storedSegments, ok := result.Item["totalSegments"].(*types.AttributeValueMemberN); !ok || storedSegments.Value != strconv.Itoa(totalSegments) {
+		return checkpoint, nil
+	}
+
+	segments, _ := result.Item["segments"].(*types.AttributeValueMemberM)
+	if segments == nil {
+		return checkpoint, nil
+	}
+
+	for segmentKey, segmentValue := range segments.Value {
+		segment, segmentErr := strconv.Atoi(segmentKey)
+		if segmentErr != nil {
+			continue
+		}
+
+		segmentMap, ok := segmentValue.(*types.AttributeValueMemberM)
+		if !ok {
+			continue
+		}
+
+		progress := &segmentProgress{}
+
+		if done, isBool := segmentMap.Value["done"].(*types.AttributeValueMemberBOOL); isBool {
+			progress.Done = done.Value
+		}
+
+		if lastEvaluatedKey, isMap := segmentMap.Value["lastEvaluatedKey"].(*types.AttributeValueMemberM); isMap {
+			progress.LastEvaluatedKey = lastEvaluatedKey.Value
+		}
+
+		if count, isNumber := segmentMap.Value["count"].(*types.AttributeValueMemberN); isNumber {
+			progress.Count, _ = strconv.ParseInt(count.Value, 10, 64)
+		}
+
+		checkpoint.segments[segment] = progress
+	}
+
+	return checkpoint, nil
+}
+
+// startKey returns the key to resume the segment from and whether the segment is already done
+func (c *scanCheckpoint) startKey(segment int) (map[string]types.AttributeValue, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress, found := c.segments[segment]
+	if !found {
+		return nil, false
+	}
+
+	return progress.LastEvaluatedKey, progress.Done
+}
+
+// count returns the number of items counted in the segment up to the checkpoint
+func (c *scanCheckpoint) count(segment int) int64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if progress, found := c.segments[segment]; found {
+		return progress.Count
+	}
+
+	return 0
+}
+
+// totalCount returns the number of items counted in all segments up to the checkpoint
+func (c *scanCheckpoint) totalCount() int64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var total int64
+	for _, progress := range c.segments {
+		total += progress.Count
+	}
+
+	return total
+}
+
+// pageEnd records that all items of a page were processed and adds count to the number of items counted in the segment.
+// The checkpoint is stored if the interval elapsed since it was last stored.
+func (c *scanCheckpoint) pageEnd(ctx context.Context, segment int, lastEvaluatedKey map[string]types.AttributeValue, count int64) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress := c.progress(segment)
+	progress.Count += count
+
+	if len(lastEvaluatedKey) == 0 {
+		return nil
+	}
+
+	progress.LastEvaluatedKey = lastEvaluatedKey
+
+	if time.Since(c.lastSaved) < c.interval {
+		return nil
+	}
+
+	return c.save(ctx)
+}
+
+// segmentDone records that all items of a segment were processed. The checkpoint is stored if other segments are still in progress.
+func (c *scanCheckpoint) segmentDone(ctx context.Context, segment int) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	progress := c.progress(segment)
+	progress.LastEvaluatedKey = nil
+	progress.Done = true
+
+	for i := 0; i < c.totalSegments; i++ {
+		if other, found := c.segments[i]; !found || !other.Done {
+			return c.save(ctx)
+		}
+	}
+
+	return nil
+}
+
+// progress returns the progress of a segment. Should be called while the mutex is held.
+func (c *scanCheckpoint) progress(segment int) *segmentProgress {
+	progress, found := c.segments[segment]
+	if !found {
+		progress = &segmentProgress{}
+		c.segments[segment] = progress
+	}
+
+	return progress
+}
+
+// save stores the checkpoint. Should be called while the mutex is held.
+func (c *scanCheckpoint) save(ctx context.Context) error {
+	segments := make(map[string]types.AttributeValue, len(c.segments))
+
+	for segment, progress := range c.segments {
+		segmentValue := map[string]types.AttributeValue{
+			"done":  &types.AttributeValueMemberBOOL{Value: progress.Done},
+			"count": &types.AttributeValueMemberN{Value: strconv.FormatInt(progress.Count, 10)},
+		}
+
+		if len(progress.LastEvaluatedKey) > 0 {
+			segmentValue["lastEvaluatedKey"] = &types.AttributeValueMemberM{Value: progress.LastEvaluatedKey}
+		}
+
+		segments[strconv.Itoa(segment)] = &types.AttributeValueMemberM{Value: segmentValue}
+	}
+
+	_, err := c.run.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &c.run.tableName,
+		Item: map[string]types.AttributeValue{
+			"PK":            &types.AttributeValueMemberS{Value: c.run.checkpointPK()},
+			"totalSegments": &types.AttributeValueMemberN{Value: strconv.Itoa(c.totalSegments)},
+			"segments":      &types.AttributeValueMemberM{Value: segments},
+			"updatedAt":     &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("storing checkpoint: %w", err)
+	}
+
+	c.lastSaved = time.Now()
+	c.run.markCheckpointed()
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func TestMigrator_Execute_ScanMigrationResumesFromCheckpoint(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "table_to_migrate"
+
+	startKey := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "item_2"}}
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectMigrationRecord(client, migrationTable, "CHECKPOINT#1", map[string]types.AttributeValue{
+		"PK":            &types.AttributeValueMemberS{Value: "CHECKPOINT#1"},
+		"totalSegments": &types.AttributeValueMemberN{Value: "1"},
+		"segments": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"0": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"done":             &types.AttributeValueMemberBOOL{Value: false},
+				"lastEvaluatedKey": &types.AttributeValueMemberM{Value: startKey},
+			}},
+		}},
+	})
+
+	client.EXPECT().Scan(mock.Anything, &dynamodb.ScanInput{
+		TableName:         &table,
+		ConsistentRead:    aws.Bool(true),
+		ExclusiveStartKey: startKey,
+	}).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{
+		{"PK": &types.AttributeValueMemberS{Value: "item_3"}},
+	}}, nil).Once()
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	storeItems := make([]*dynamodb.TransactWriteItemsInput, 0, 1)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migration, err := NewScanAndUpdateMigration("scan", "scan migration", table, func(ctx context.Context, item map[string]types.AttributeValue) *dynamodb.UpdateItemInput {
+		return &dynamodb.UpdateItemInput{TableName: &table}
+	})
+	require.NoError(t, err)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, storeItems[0].TransactItems, 3)
+	require.Equal(t, &types.Delete{
+		TableName: &migrationTable,
+		Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "CHECKPOINT#1"}},
+	}, storeItems[0].TransactItems[2].Delete)
+}
+
+func TestMigrator_Execute_ScanMigrationStoresCheckpoint(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "table_to_migrate"
+
+	lastEvaluatedKey := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "item_1"}}
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectMigrationRecord(client, migrationTable, "CHECKPOINT#1", nil)
+
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "item_1"}}},
+		LastEvaluatedKey: lastEvaluatedKey,
+	}, nil).Once()
+
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+	var checkpoints []*dynamodb.PutItemInput
+
+	client.EXPECT().PutItem(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) {
+		checkpoints = append(checkpoints, params)
+	}).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	migration, err := NewScanAndUpdateMigration("scan", "scan migration", table, func(ctx context.Context, item map[string]types.AttributeValue) *dynamodb.UpdateItemInput {
+		return nil
+	}, ScanAndUpdateMigrationWithCheckpointInterval(time.Nanosecond))
+	require.NoError(t, err)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "running migration scan: boom")
+	require.Len(t, checkpoints, 1)
+
+	require.Equal(t, &types.AttributeValueMemberS{Value: "CHECKPOINT#1"}, checkpoints[0].Item["PK"])
+	require.Equal(t, &types.AttributeValueMemberN{Value: "1"}, checkpoints[0].Item["totalSegments"])
+	require.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"0": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"done":             &types.AttributeValueMemberBOOL{Value: false},
+			"count":            &types.AttributeValueMemberN{Value: "1"},
+			"lastEvaluatedKey": &types.AttributeValueMemberM{Value: lastEvaluatedKey},
+		}},
+	}}, checkpoints[0].Item["segments"])
+}
+
+func TestLoadScanCheckpoint_NotExecutedByMigrator(t *testing.T) {
+	// When
+	checkpoint, err := loadScanCheckpoint(context.Background(), 1, time.Second)
+
+	// Then
+	require.NoError(t, err)
+	require.Nil(t, checkpoint)
+
+	startKey, done := checkpoint.startKey(0)
+	require.Nil(t, startKey)
+	require.False(t, done)
+	require.NoError(t, checkpoint.pageEnd(context.Background(), 0, map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "PK"}}, 1))
+	require.Zero(t, checkpoint.count(0))
+	require.NoError(t, checkpoint.segmentDone(context.Background(), 0))
+}
@@ -0,0 +1,139 @@
+package migrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrChecksumMismatch is returned if the checksum of an executed migration no longer matches the recorded checksum
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+type ChecksumMode int
+
+const (
+	// ChecksumModeFail fails the execution if the checksum of an executed migration changed
+	ChecksumModeFail ChecksumMode = iota
+
+	// ChecksumModeWarn reports changed checksums to the OnMismatch callback and continues the execution
+	ChecksumModeWarn
+)
+
+// ChecksumMismatch describes an executed migration of which the checksum changed
+type ChecksumMismatch struct {
+	ID               uint64
+	Name             string
+	RecordedChecksum string
+	Checksum         string
+}
+
+// ChecksumValidation configures how changed checksums of executed migrations are handled
+type ChecksumValidation struct {
+	Mode ChecksumMode
+
+	// OnMismatch is called for every executed migration of which the checksum changed. Optional
+	OnMismatch func(mismatch ChecksumMismatch)
+}
+
+// Checksum returns a SHA-256 checksum of the given content, e.g. the source or a version of the migration
+func Checksum(content ...string) string {
+	hash := sha256.New()
+
+	for _, c := range content {
+		hash.Write([]byte(c))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// WithChecksumValidation Specifies how changed checksums of executed migrations are handled. By default, the execution fails.
+func (m *Migrator) WithChecksumValidation(mode ChecksumMode, onMismatch func(mismatch ChecksumMismatch)) *Migrator {
+	m.ChecksumValidation = &ChecksumValidation{Mode: mode, OnMismatch: onMismatch}
+
+	return m
+}
+
+// validateChecksums compares the checksum of every executed migration that declares a checksum with the checksum recorded on success.
+// Migrations that were executed without checksum are not validated.
+func (m *Migrator) validateChecksums(ctx context.Context, client DynamodbClient, metadata *metadataObject) error {
+	validation := ChecksumValidation{Mode: ChecksumModeFail}
+	if m.ChecksumValidation != nil {
+		validation = *m.ChecksumValidation
+	}
+
+	var problems []string
+
+	for i := range m.Migrations {
+		migration := &m.Migrations[i]
+
+		migrationId := m.MigrationIdOffset + uint64(i) + 1
+		if migrationId > metadata.LastJobId {
+			break
+		}
+
+		if migration.Checksum == "" {
+			continue
+		}
+
+		record, err := m.getMigrationObject(ctx, client, migrationId)
+		if err != nil {
+			return fmt.Errorf("loading migration %d: %w", migrationId, err)
+		}
+
+		if record == nil || record.Checksum == "" || record.Checksum == migration.Checksum {
+			continue
+		}
+
+		if validation.OnMismatch != nil {
+			validation.OnMismatch(ChecksumMismatch{
+				ID:               migrationId,
+				Name:             migration.Name,
+				RecordedChecksum: record.Checksum,
+				Checksum:         migration.Checksum,
+			})
+		}
+
+		problems = append(problems, fmt.Sprintf("migration %d (%s) was executed with checksum %s, but has checksum %s", migrationId, migration.Name, record.Checksum, migration.Checksum))
+	}
+
+	if len(problems) > 0 && validation.Mode == ChecksumModeFail {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func (m *Migrator) getMigrationObject(ctx context.Context, client DynamodbClient, id uint64) (*migrationObject, error) {
+	result, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &m.MigrationTableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("MIGRATION#%d", id)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var record migrationObject
+
+	err = attributevalue.UnmarshalMap(result.Item, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,140 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func expectMigrationRecord(client *mocks.DynamodbClient, migrationTable string, pk string, item map[string]types.AttributeValue) {
+	client.EXPECT().GetItem(mock.Anything, &dynamodb.GetItemInput{
+		TableName: &migrationTable,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+		},
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+}
+
+func TestChecksum(t *testing.T) {
+	require.Equal(t, Checksum("a", "b"), Checksum("a", "b"))
+	require.NotEqual(t, Checksum("a", "b"), Checksum("ab"))
+	require.Len(t, Checksum("a"), 64)
+}
+
+func TestMigrator_Execute_RecordsChecksum(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+
+	storeItems := make([]*dynamodb.TransactWriteItemsInput, 0, 1)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name:       "migration_1",
+		Checksum:   "v1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil },
+	})
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "v1"}, storeItems[0].TransactItems[1].Put.Item["checksum"])
+}
+
+func TestMigrator_Execute_ChecksumValidation(t *testing.T) {
+	tests := []struct {
+		name             string
+		recordedChecksum string
+		mode             ChecksumMode
+		expectError      bool
+		expectMismatch   bool
+	}{
+		{
+			name:             "matching checksum",
+			recordedChecksum: "v1",
+		},
+		{
+			name: "executed without checksum",
+		},
+		{
+			name:             "changed checksum fails",
+			recordedChecksum: "v0",
+			expectError:      true,
+			expectMismatch:   true,
+		},
+		{
+			name:             "changed checksum warns",
+			recordedChecksum: "v0",
+			mode:             ChecksumModeWarn,
+			expectMismatch:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			migrationTable := "migration_table"
+
+			client := mocks.NewDynamodbClient(t)
+			expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+				"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+			})
+
+			record := map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: "MIGRATION#1"},
+				"id": &types.AttributeValueMemberN{Value: "1"},
+			}
+
+			if tt.recordedChecksum != "" {
+				record["checksum"] = &types.AttributeValueMemberS{Value: tt.recordedChecksum}
+			}
+
+			expectMigrationRecord(client, migrationTable, "MIGRATION#1", record)
+
+			migrator := NewMigrator(migrationTable, 0, Migration{
+				Name:       "migration_1",
+				Checksum:   "v1",
+				MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil },
+			})
+
+			var mismatches []ChecksumMismatch
+
+			onMismatch := func(mismatch ChecksumMismatch) {
+				mismatches = append(mismatches, mismatch)
+			}
+
+			migrator.WithChecksumValidation(tt.mode, onMismatch)
+
+			// When
+			err := migrator.Execute(context.Background(), client)
+
+			// Then
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrChecksumMismatch)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.expectMismatch {
+				require.Equal(t, []ChecksumMismatch{{ID: 1, Name: "migration_1", RecordedChecksum: tt.recordedChecksum, Checksum: "v1"}}, mismatches)
+			} else {
+				require.Empty(t, mismatches)
+			}
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/executor"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+)
+
+const maxBatchWriteSize = 25
+const maxBatchWriteAttempts = 8
+
+// ErrCountMismatch is returned by a copy migration if the number of items in the destination table does not match the number of copied items
+var ErrCountMismatch = errors.New("item count mismatch")
+
+type CopyMigrationOptions struct {
+	FilterExpression   conditionexpression.ExpressionItem
+	Metadata           map[string]interface{}
+	CheckpointInterval *time.Duration
+
+	// TotalSegments number of segments of the source table that are scanned in parallel
+	TotalSegments int32
+
+	// WritesPerSecond maximum number of written and deleted items per second. Zero means unlimited
+	WritesPerSecond float64
+
+	// DeleteSourceKeys if not empty, copied items are deleted from the source table. Contains the key attributes of the source table
+	DeleteSourceKeys []string
+
+	// VerifyCount if true, the number of items in the destination table is compared with the number of copied items at the end of the migration
+	VerifyCount bool
+}
+
+type CopyOptionFn func(*CopyMigrationOptions)
+
+// NewCopyMigration create a migration that copies all items of the source table to the destination table.
+// Every item is passed through the transformFn, which returns zero or more items to write to the destination table, e.g. with a different key schema.
+// The items are written in batches with BatchWriteItem once all items of a scanned page are transformed. The progress of the scan is checkpointed if the migration is executed by a Migrator.
+// Optionally, the copied items are deleted from the source table and the number of items in the destination table is verified at the end.
+func NewCopyMigration(name string, description string, sourceTable string, destinationTable string, transformFn func(ctx context.Context, item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error), optFn ...CopyOptionFn) (*Migration, error) {
+	options := CopyMigrationOptions{
+		TotalSegments: 1,
+	}
+
+	for _, opt := range optFn {
+		opt(&options)
+	}
+
+	switch {
+	case options.TotalSegments < 1:
+		return nil, fmt.Errorf("total segments should be at least 1, got %d", options.TotalSegments)
+	case options.WritesPerSecond < 0:
+		return nil, fmt.Errorf("writes per second should not be negative, got %f", options.WritesPerSecond)
+	case sourceTable == destinationTable:
+		return nil, errors.New("source and destination table should be different")
+	}
+
+	scanBuilder := inputbuilder.NewScanBuilder()
+	scanBuilder.WithTableName(sourceTable)
+	scanBuilder.WithConsistentRead(true)
+
+	if options.FilterExpression != nil {
+		scanBuilder.WithFilterExpression(options.FilterExpression)
+	}
+
+	scanInput := &dynamodb.ScanInput{}
+
+	err := scanBuilder.Build(scanInput)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{"table": sourceTable, "destinationTable": destinationTable}
+
+	for key, value := range options.Metadata {
+		metadata[key] = value
+	}
+
+	migration := &copyMigration{
+		scanInput:        scanInput,
+		sourceTable:      sourceTable,
+		destinationTable: destinationTable,
+		transformFn:      transformFn,
+		options:          options,
+	}
+
+	return &Migration{
+		Name:        name,
+		Description: description,
+		MigratorFn:  migration.migrate,
+		JobMetadata: metadata,
+	}, nil
+}
+
+// CopyMigrationWithFilterExpression set filter condition on the scan of the source table of CopyMigration
+func CopyMigrationWithFilterExpression(filter conditionexpression.ExpressionItem) CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.FilterExpression = filter
+	}
+}
+
+// CopyMigrationWithMetadata add metadata to CopyMigration
+func CopyMigrationWithMetadata(metadata map[string]interface{}) CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.Metadata = metadata
+	}
+}
+
+// CopyMigrationWithCheckpointInterval set the minimal time between two stored checkpoints of the scan progress on CopyMigration.
+// Default value is 30 seconds. A zero interval disables checkpoints.
+func CopyMigrationWithCheckpointInterval(interval time.Duration) CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.CheckpointInterval = &interval
+	}
+}
+
+// CopyMigrationWithParallelScan scan the source table in totalSegments segments in parallel on CopyMigration. Default value is 1.
+func CopyMigrationWithParallelScan(totalSegments int32) CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.TotalSegments = totalSegments
+	}
+}
+
+// CopyMigrationWithWritesPerSecond throttle the writes and deletes of CopyMigration to at most writesPerSecond items per second. Default value is unlimited.
+func CopyMigrationWithWritesPerSecond(writesPerSecond float64) CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.WritesPerSecond = writesPerSecond
+	}
+}
+
+// CopyMigrationWithSourceDeletion delete copied items from the source table on CopyMigration. The key attributes of the source table are required to delete the items.
+// Items are only deleted once all items of the page are written to the destination table. The migration fails if a scanned item lacks one of the key attributes.
+func CopyMigrationWithSourceDeletion(keyAttributes ...string) CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.DeleteSourceKeys = keyAttributes
+	}
+}
+
+// CopyMigrationWithCountVerification verify at the end of CopyMigration that the destination table contains exactly as many items as were copied.
+// If not, ErrCountMismatch is returned. The destination table should be empty before the migration starts. The count is not verified in a dry run.
+func CopyMigrationWithCountVerification() CopyOptionFn {
+	return func(options *CopyMigrationOptions) {
+		options.VerifyCount = true
+	}
+}
+
+// copyMigration executes a CopyMigration
+type copyMigration struct {
+	scanInput        *dynamodb.ScanInput
+	sourceTable      string
+	destinationTable string
+	transformFn      func(ctx context.Context, item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error)
+	options          CopyMigrationOptions
+}
+
+// copyRun is the state of a single execution of a CopyMigration
+type copyRun struct {
+	*copyMigration
+
+	client     DynamodbClient
+	checkpoint *scanCheckpoint
+	limiter    *rateLimiter
+
+	// destinationKeys key attributes of the destination table, or nil if they are unknown
+	destinationKeys []string
+
+	mutex  sync.Mutex
+	copied int64
+}
+
+func (c *copyMigration) migrate(ctx context.Context, client DynamodbClient) error {
+	checkpointInterval := defaultCheckpointInterval
+	if c.options.CheckpointInterval != nil {
+		checkpointInterval = *c.options.CheckpointInterval
+	}
+
+	checkpoint, err := loadScanCheckpoint(ctx, int(c.options.TotalSegments), checkpointInterval)
+	if err != nil {
+		return err
+	}
+
+	estimateTotalItems(ctx, client, c.sourceTable, checkpoint.totalCount())
+
+	run := &copyRun{
+		copyMigration: c,
+		client:        client,
+		checkpoint:    checkpoint,
+		limiter:       newRateLimiter(c.options.WritesPerSecond),
+
+		destinationKeys: newKeySchemas(client).tableKeyAttributes(ctx, c.destinationTable),
+	}
+
+	segmentCtx, cancelFn := context.WithCancelCause(ctx)
+	defer cancelFn(nil)
+
+	var wg sync.WaitGroup
+
+	for segment := int32(0); segment < c.options.TotalSegments; segment++ {
+		wg.Add(1)
+
+		go func(segment int32) {
+			defer wg.Done()
+
+			segmentErr := run.copySegment(segmentCtx, segment)
+			if segmentErr != nil {
+				cancelFn(segmentErr)
+			}
+		}(segment)
+	}
+
+	wg.Wait()
+
+	err = context.Cause(segmentCtx)
+	if err != nil {
+		return err
+	}
+
+	// A dry run does not write the copied items, so the count can not be verified
+	if c.options.VerifyCount && !IsDryRun(ctx) {
+		return run.verifyCount(ctx)
+	}
+
+	return nil
+}
+
+// copySegment copies a single segment page by page. The checkpoint is updated once all items of a page are written.
+func (r *copyRun) copySegment(ctx context.Context, segment int32) error {
+	copied := r.checkpoint.count(int(segment))
+
+	defer func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.copied += copied
+	}()
+
+	startKey, done := r.checkpoint.startKey(int(segment))
+	if done {
+		return nil
+	}
+
+	input := *r.scanInput
+	input.ExclusiveStartKey = startKey
+
+	if r.options.TotalSegments > 1 {
+		input.Segment = aws.Int32(segment)
+		input.TotalSegments = aws.Int32(r.options.TotalSegments)
+	}
+
+	var page []map[string]types.AttributeValue
+
+	exec := executor.New(r.client)
+	items := exec.Scan(ctx, &input, executor.WithPageEndMarkers())
+
+	for item := range items {
+		switch v := item.(type) {
+		case error:
+			return v
+		case executor.PageEnd:
+			pageCopied, err := r.copyPage(ctx, page)
+			if err != nil {
+				return err
+			}
+
+			copied += pageCopied
+
+			AddItemsProcessed(ctx, int64(len(page)))
+
+			err = r.checkpoint.pageEnd(ctx, int(segment), v.LastEvaluatedKey, pageCopied)
+			if err != nil {
+				return err
+			}
+
+			page = page[:0]
+		case map[string]types.AttributeValue:
+			page = append(page, v)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return r.checkpoint.segmentDone(ctx, int(segment))
+}
+
+// copyPage transforms the items of a page and writes the target items to the destination table.
+// If configured, the source items are deleted afterwards. The number of written items is returned.
+func (r *copyRun) copyPage(ctx context.Context, items []map[string]types.AttributeValue) (int64, error) {
+	var deletes []types.WriteRequest
+
+	// The keys are validated before anything is written, so no items are copied that can not be deleted
+	if len(r.options.DeleteSourceKeys) > 0 {
+		deletes = make([]types.WriteRequest, 0, len(items))
+
+		for _, item := range items {
+			key := make(map[string]types.AttributeValue, len(r.options.DeleteSourceKeys))
+
+			for _, keyAttribute := range r.options.DeleteSourceKeys {
+				value, found := item[keyAttribute]
+				if !found {
+					return 0, fmt.Errorf("deleting from %s: item has no key attribute %q", r.sourceTable, keyAttribute)
+				}
+
+				key[keyAttribute] = value
+			}
+
+			deletes = append(deletes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+		}
+	}
+
+	var puts []types.WriteRequest
+
+	var updated, skipped int64
+
+	for _, item := range items {
+		targets, err := r.transformFn(ctx, item)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(targets) == 0 {
+			skipped++
+		} else {
+			updated++
+		}
+
+		for _, target := range targets {
+			puts = append(puts, types.WriteRequest{PutRequest: &types.PutRequest{Item: target}})
+		}
+	}
+
+	err := batchWrite(ctx, r.client, r.limiter, r.destinationTable, puts, r.destinationKeys)
+	if err != nil {
+		return 0, fmt.Errorf("writing to %s: %w", r.destinationTable, err)
+	}
+
+	if len(deletes) > 0 {
+		err = batchWrite(ctx, r.client, r.limiter, r.sourceTable, deletes, r.options.DeleteSourceKeys)
+		if err != nil {
+			return 0, fmt.Errorf("deleting from %s: %w", r.sourceTable, err)
+		}
+	}
+
+	progress := ProgressReporterFromContext(ctx)
+	progress.AddUpdated(updated)
+	progress.AddSkipped(skipped)
+
+	return int64(len(puts)), nil
+}
+
+// batchWrite executes the write requests in batches of at most 25 requests. Unprocessed items are retried with an exponential backoff.
+// A new batch is started if an item is written again, as BatchWriteItem rejects duplicate keys. Puts are identified by their key attributes, or by the complete item if keyAttributes is empty.
+func batchWrite(ctx context.Context, client DynamodbClient, limiter *rateLimiter, table string, requests []types.WriteRequest, keyAttributes []string) error {
+	for _, batch := range writeRequestBatches(requests, keyAttributes) {
+		err := limiter.wait(ctx, len(batch))
+		if err != nil {
+			return err
+		}
+
+		delay := 50 * time.Millisecond
+
+		for attempt := 1; len(batch) > 0; attempt++ {
+			if attempt > maxBatchWriteAttempts {
+				return fmt.Errorf("%d items still unprocessed after %d attempts", len(batch), maxBatchWriteAttempts)
+			}
+
+			output, batchErr := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{table: batch},
+			})
+			if batchErr != nil {
+				return batchErr
+			}
+
+			batch = output.UnprocessedItems[table]
+			if len(batch) == 0 {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+
+			delay *= 2
+		}
+	}
+
+	return nil
+}
+
+// writeRequestBatches splits the write requests in batches of at most 25 requests that write every item at most once, keeping the order of the requests
+func writeRequestBatches(requests []types.WriteRequest, keyAttributes []string) [][]types.WriteRequest {
+	var batches [][]types.WriteRequest
+
+	var batch []types.WriteRequest
+
+	batchKeys := make(map[string]struct{})
+
+	for _, request := range requests {
+		var itemKey string
+
+		if request.PutRequest != nil {
+			itemKey = attributeValuesKey(projectKey(request.PutRequest.Item, keyAttributes))
+		} else if request.DeleteRequest != nil {
+			itemKey = attributeValuesKey(request.DeleteRequest.Key)
+		}
+
+		if _, found := batchKeys[itemKey]; found || len(batch) == maxBatchWriteSize {
+			batches = append(batches, batch)
+			batch = nil
+			batchKeys = make(map[string]struct{})
+		}
+
+		batch = append(batch, request)
+		batchKeys[itemKey] = struct{}{}
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// verifyCount compares the number of items in the destination table with the number of copied items
+func (r *copyRun) verifyCount(ctx context.Context) error {
+	var count int64
+
+	input := &dynamodb.ScanInput{
+		TableName:      &r.destinationTable,
+		Select:         types.SelectCount,
+		ConsistentRead: aws.Bool(true),
+	}
+
+	for {
+		output, err := r.client.Scan(ctx, input)
+		if err != nil {
+			return fmt.Errorf("counting items in %s: %w", r.destinationTable, err)
+		}
+
+		count += int64(output.Count)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	if count != r.copied {
+		return fmt.Errorf("%w: %d items copied, but %s contains %d items", ErrCountMismatch, r.copied, r.destinationTable, count)
+	}
+
+	return nil
+}
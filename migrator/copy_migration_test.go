@@ -0,0 +1,208 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func splitTransform(_ context.Context, item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	pk := item["PK"].(*types.AttributeValueMemberS).Value
+
+	return []map[string]types.AttributeValue{
+		{"PK": &types.AttributeValueMemberS{Value: pk}, "SK": &types.AttributeValueMemberS{Value: "A"}},
+		{"PK": &types.AttributeValueMemberS{Value: pk}, "SK": &types.AttributeValueMemberS{Value: "B"}},
+	}, nil
+}
+
+func sourceItems(pks ...string) []map[string]types.AttributeValue {
+	items := make([]map[string]types.AttributeValue, 0, len(pks))
+	for _, pk := range pks {
+		items = append(items, map[string]types.AttributeValue{
+			"PK":   &types.AttributeValueMemberS{Value: pk},
+			"data": &types.AttributeValueMemberS{Value: "data_" + pk},
+		})
+	}
+
+	return items
+}
+
+func TestNewCopyMigration(t *testing.T) {
+	// Given
+	source := "source_table"
+	destination := "destination_table"
+
+	m, err := NewCopyMigration("copy", "Copy migration", source, destination, splitTransform,
+		CopyMigrationWithSourceDeletion("PK"),
+		CopyMigrationWithCountVerification(),
+		CopyMigrationWithMetadata(map[string]interface{}{"foo": "bar"}))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"table": source, "destinationTable": destination, "foo": "bar"}, m.JobMetadata)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, &dynamodb.ScanInput{
+		TableName:      &source,
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.ScanOutput{Items: sourceItems("1", "2")}, nil).Once()
+
+	var batches []*dynamodb.BatchWriteItemInput
+
+	client.EXPECT().BatchWriteItem(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) {
+		batches = append(batches, params)
+	}).Return(&dynamodb.BatchWriteItemOutput{}, nil).Twice()
+
+	client.EXPECT().Scan(mock.Anything, &dynamodb.ScanInput{
+		TableName:      &destination,
+		Select:         types.SelectCount,
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.ScanOutput{Count: 4}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+
+	require.Len(t, batches[0].RequestItems[destination], 4)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "B"}, batches[0].RequestItems[destination][3].PutRequest.Item["SK"])
+
+	require.Equal(t, []types.WriteRequest{
+		{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "1"}}}},
+		{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "2"}}}},
+	}, batches[1].RequestItems[source])
+}
+
+func TestNewCopyMigration_RetriesUnprocessedItems(t *testing.T) {
+	// Given
+	source := "source_table"
+	destination := "destination_table"
+
+	m, err := NewCopyMigration("copy", "Copy migration", source, destination, splitTransform)
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: sourceItems("1")}, nil).Once()
+
+	var batches []int
+
+	client.EXPECT().BatchWriteItem(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) {
+		batches = append(batches, len(params.RequestItems[destination]))
+	}).RunAndReturn(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+		requests := params.RequestItems[destination]
+		if len(requests) > 1 {
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{destination: requests[1:]}}, nil
+		}
+
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	}).Twice()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 1}, batches)
+}
+
+func TestNewCopyMigration_CountMismatch(t *testing.T) {
+	// Given
+	source := "source_table"
+	destination := "destination_table"
+
+	m, err := NewCopyMigration("copy", "Copy migration", source, destination, splitTransform, CopyMigrationWithCountVerification())
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, &dynamodb.ScanInput{
+		TableName:      &source,
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.ScanOutput{Items: sourceItems("1")}, nil).Once()
+
+	client.EXPECT().BatchWriteItem(mock.Anything, mock.Anything).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	client.EXPECT().Scan(mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.TableName == destination && input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{Count: 2, LastEvaluatedKey: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "1"}}}, nil).Once()
+
+	client.EXPECT().Scan(mock.Anything, mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.TableName == destination && input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{Count: 1}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.ErrorIs(t, err, ErrCountMismatch)
+	require.ErrorContains(t, err, "2 items copied, but destination_table contains 3 items")
+}
+
+func TestNewCopyMigration_DuplicateTargetKey(t *testing.T) {
+	// Given
+	source := "source_table"
+	destination := "destination_table"
+
+	// Both source items are merged into the same target item
+	mergeTransform := func(_ context.Context, item map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+		return []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "merged"}, "data": item["data"]}}, nil
+	}
+
+	m, err := NewCopyMigration("copy", "Copy migration", source, destination, mergeTransform)
+	require.NoError(t, err)
+
+	client := mocks.NewSchemaClient(t)
+	client.EXPECT().DescribeTable(mock.Anything, &dynamodb.DescribeTableInput{TableName: &destination}).Return(&dynamodb.DescribeTableOutput{Table: &types.TableDescription{
+		KeySchema: []types.KeySchemaElement{{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash}},
+	}}, nil).Once()
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: sourceItems("1", "2")}, nil).Once()
+
+	var batches []*dynamodb.BatchWriteItemInput
+
+	client.EXPECT().BatchWriteItem(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) {
+		batches = append(batches, params)
+	}).Return(&dynamodb.BatchWriteItemOutput{}, nil).Twice()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0].RequestItems[destination], 1)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "data_1"}, batches[0].RequestItems[destination][0].PutRequest.Item["data"])
+	require.Len(t, batches[1].RequestItems[destination], 1)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "data_2"}, batches[1].RequestItems[destination][0].PutRequest.Item["data"])
+}
+
+func TestNewCopyMigration_MissingDeleteKeyAttribute(t *testing.T) {
+	// Given
+	source := "source_table"
+	destination := "destination_table"
+
+	m, err := NewCopyMigration("copy", "Copy migration", source, destination, splitTransform, CopyMigrationWithSourceDeletion("PK", "SK"))
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: sourceItems("1")}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, `deleting from source_table: item has no key attribute "SK"`)
+}
+
+func TestNewCopyMigration_InvalidOptions(t *testing.T) {
+	_, err := NewCopyMigration("copy", "Copy migration", "table", "table", splitTransform)
+	require.Error(t, err)
+
+	_, err = NewCopyMigration("copy", "Copy migration", "source", "destination", splitTransform, CopyMigrationWithParallelScan(0))
+	require.Error(t, err)
+}
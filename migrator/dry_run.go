@@ -0,0 +1,231 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+const (
+	OperationPutItem            = "PutItem"
+	OperationUpdateItem         = "UpdateItem"
+	OperationDeleteItem         = "DeleteItem"
+	OperationTransactWriteItems = "TransactWriteItems"
+	OperationBatchWriteItem     = "BatchWriteItem"
+	OperationUpdateTable        = "UpdateTable"
+	OperationUpdateTimeToLive   = "UpdateTimeToLive"
+)
+
+// Interface validation check
+var _ SchemaClient = (*RecordingClient)(nil)
+
+type dryRunContextKey struct{}
+
+func withDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunContextKey{}, true)
+}
+
+// IsDryRun returns true if the migration is executed by Migrator.DryRun. Writes are recorded instead of executed,
+// so migrations should skip steps that depend on their own writes, e.g. waiting for or verifying them.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunContextKey{}).(bool)
+
+	return dryRun
+}
+
+// RecordedWrite is a write request that was captured instead of executed
+type RecordedWrite struct {
+	// Operation name of the DynamoDB operation, e.g. OperationPutItem
+	Operation string
+
+	// Input of the operation, e.g. *dynamodb.PutItemInput
+	Input interface{}
+}
+
+// RecordingClient is a DynamodbClient that passes reads to the wrapped client and records writes instead of executing them.
+// Schema changes are recorded as well. Describing a table requires the wrapped client to be a SchemaClient.
+type RecordingClient struct {
+	Client DynamodbClient
+
+	mutex  sync.Mutex
+	writes []RecordedWrite
+}
+
+// NewRecordingClient creates a new RecordingClient that reads from the given client
+func NewRecordingClient(client DynamodbClient) *RecordingClient {
+	return &RecordingClient{Client: client}
+}
+
+// Writes returns all recorded writes in the order they were requested
+func (c *RecordingClient) Writes() []RecordedWrite {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	writes := make([]RecordedWrite, len(c.writes))
+	copy(writes, c.writes)
+
+	return writes
+}
+
+func (c *RecordingClient) record(operation string, input interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.writes = append(c.writes, RecordedWrite{Operation: operation, Input: input})
+}
+
+func (c *RecordingClient) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(options *dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	c.record(OperationPutItem, params)
+
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *RecordingClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return c.Client.GetItem(ctx, params, optFns...)
+}
+
+func (c *RecordingClient) DeleteItem(_ context.Context, params *dynamodb.DeleteItemInput, _ ...func(options *dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	c.record(OperationDeleteItem, params)
+
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (c *RecordingClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return c.Client.Scan(ctx, params, optFns...)
+}
+
+func (c *RecordingClient) TransactWriteItems(_ context.Context, params *dynamodb.TransactWriteItemsInput, _ ...func(options *dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.record(OperationTransactWriteItems, params)
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (c *RecordingClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return c.Client.Query(ctx, params, optFns...)
+}
+
+func (c *RecordingClient) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(options *dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	c.record(OperationUpdateItem, params)
+
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (c *RecordingClient) BatchWriteItem(_ context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(options *dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	c.record(OperationBatchWriteItem, params)
+
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (c *RecordingClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	schemaClient, ok := c.Client.(SchemaClient)
+	if !ok {
+		return nil, ErrSchemaClientRequired
+	}
+
+	return schemaClient.DescribeTable(ctx, params, optFns...)
+}
+
+func (c *RecordingClient) UpdateTable(_ context.Context, params *dynamodb.UpdateTableInput, _ ...func(options *dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+	c.record(OperationUpdateTable, params)
+
+	return &dynamodb.UpdateTableOutput{}, nil
+}
+
+func (c *RecordingClient) DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	schemaClient, ok := c.Client.(SchemaClient)
+	if !ok {
+		return nil, ErrSchemaClientRequired
+	}
+
+	return schemaClient.DescribeTimeToLive(ctx, params, optFns...)
+}
+
+func (c *RecordingClient) UpdateTimeToLive(_ context.Context, params *dynamodb.UpdateTimeToLiveInput, _ ...func(options *dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	c.record(OperationUpdateTimeToLive, params)
+
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+// DryRunReport contains the intended writes of all pending migrations
+type DryRunReport struct {
+	Migrations []MigrationDryRun
+}
+
+// MigrationDryRun contains the intended writes of a single migration
+type MigrationDryRun struct {
+	ID     uint64
+	Name   string
+	Writes []RecordedWrite
+
+	// SkipReason is set if the migration would be skipped
+	SkipReason string
+}
+
+// DryRun executes all pending migrations without modifying any data. Reads are executed, writes are recorded and returned in the report.
+// The metadata table is left untouched.
+// Note that every migration reads the data as it is before any migration is executed, as the writes of previous migrations are not applied.
+// Migrations can check IsDryRun to skip steps that depend on their own writes.
+// If a migration fails, the report of the already executed migrations is returned together with the error.
+// Migrations that would be skipped by Execute with the same options are not executed, but are listed in the report with the reason.
+func (m *Migrator) DryRun(ctx context.Context, client DynamodbClient, optFn ...ExecuteOptionFn) (*DryRunReport, error) {
+	options := newExecuteOptions(optFn)
+
+	metadata, err := m.getMetadataObject(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("loading metadata: %w", err)
+	}
+
+	err = m.verifyHistory(&metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.validateChecksums(ctx, client, &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DryRunReport{}
+
+	for i := range m.Migrations {
+		migration := &m.Migrations[i]
+
+		migrationId := m.MigrationIdOffset + uint64(i) + 1
+		if migrationId <= metadata.LastJobId {
+			continue
+		}
+
+		selected, reason, err := m.isSelected(ctx, client, migration, options)
+		if err != nil {
+			return report, fmt.Errorf("evaluating condition of migration %s: %w", migration.Name, err)
+		}
+
+		if !selected {
+			report.Migrations = append(report.Migrations, MigrationDryRun{
+				ID:         migrationId,
+				Name:       migration.Name,
+				SkipReason: reason,
+			})
+
+			continue
+		}
+
+		recordingClient := NewRecordingClient(client)
+
+		err = migration.MigratorFn(withDryRun(ctx), recordingClient)
+
+		report.Migrations = append(report.Migrations, MigrationDryRun{
+			ID:     migrationId,
+			Name:   migration.Name,
+			Writes: recordingClient.Writes(),
+		})
+
+		if err != nil {
+			return report, fmt.Errorf("running migration %s: %w", migration.Name, err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,177 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func TestMigrator_DryRun(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, &dynamodb.GetItemInput{
+		TableName: &migrationTable,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "#METADATA"},
+		},
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+	}}, nil).Once()
+
+	scanInput := &dynamodb.ScanInput{TableName: aws.String("data")}
+	client.EXPECT().Scan(mock.Anything, scanInput).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{
+		{"PK": &types.AttributeValueMemberS{Value: "item1"}},
+	}}, nil).Once()
+
+	putInput := &dynamodb.PutItemInput{TableName: aws.String("data")}
+	deleteInput := &dynamodb.DeleteItemInput{TableName: aws.String("data")}
+
+	migrator := NewMigrator(migrationTable, 0,
+		Migration{
+			Name: "migration_1",
+			MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+				return errors.New("already executed")
+			},
+		},
+		Migration{
+			Name: "migration_2",
+			MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+				output, err := client.Scan(ctx, scanInput)
+				if err != nil {
+					return err
+				}
+
+				require.Len(t, output.Items, 1)
+
+				_, err = client.PutItem(ctx, putInput)
+				if err != nil {
+					return err
+				}
+
+				_, err = client.DeleteItem(ctx, deleteInput)
+
+				return err
+			},
+		},
+		Migration{
+			Name: "migration_3",
+			MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+				return nil
+			},
+		},
+	)
+
+	// When
+	report, err := migrator.DryRun(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, &DryRunReport{Migrations: []MigrationDryRun{
+		{
+			ID:   2,
+			Name: "migration_2",
+			Writes: []RecordedWrite{
+				{Operation: OperationPutItem, Input: putInput},
+				{Operation: OperationDeleteItem, Input: deleteInput},
+			},
+		},
+		{
+			ID:     3,
+			Name:   "migration_3",
+			Writes: []RecordedWrite{},
+		},
+	}}, report)
+}
+
+func TestMigrator_DryRun_FailedMigration(t *testing.T) {
+	// Given
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	updateInput := &dynamodb.UpdateItemInput{TableName: aws.String("data")}
+
+	migrator := NewMigrator("migration_table", 0,
+		Migration{
+			Name: "migration_1",
+			MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+				_, err := client.UpdateItem(ctx, updateInput)
+				if err != nil {
+					return err
+				}
+
+				return errors.New("boom")
+			},
+		},
+	)
+
+	// When
+	report, err := migrator.DryRun(context.Background(), client)
+
+	// Then
+	require.Error(t, err)
+	require.Equal(t, []RecordedWrite{{Operation: OperationUpdateItem, Input: updateInput}}, report.Migrations[0].Writes)
+}
+
+func TestMigrator_DryRun_SkippedMigration(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 0, taggedMigration("seed-data", &executed, "staging"))
+
+	// When
+	report, err := migrator.DryRun(context.Background(), client, ExecuteWithTags("production"))
+
+	// Then
+	require.NoError(t, err)
+	require.Empty(t, executed)
+	require.Equal(t, []MigrationDryRun{{ID: 1, Name: "seed-data", SkipReason: "tags [staging] do not match the selected tags [production]"}}, report.Migrations)
+}
+
+func TestMigrator_DryRun_CopyMigrationWithCountVerification(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	source := "source_table"
+	destination := "destination_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	client.EXPECT().Scan(mock.Anything, &dynamodb.ScanInput{
+		TableName:      &source,
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.ScanOutput{Items: sourceItems("1")}, nil).Once()
+
+	migration, err := NewCopyMigration("copy", "Copy migration", source, destination, splitTransform, CopyMigrationWithCountVerification())
+	require.NoError(t, err)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	report, err := migrator.DryRun(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, report.Migrations, 1)
+	require.Len(t, report.Migrations[0].Writes, 1)
+	require.Equal(t, OperationBatchWriteItem, report.Migrations[0].Writes[0].Operation)
+}
+
+func TestIsDryRun(t *testing.T) {
+	require.False(t, IsDryRun(context.Background()))
+	require.True(t, IsDryRun(withDryRun(context.Background())))
+}
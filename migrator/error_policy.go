@@ -0,0 +1,106 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrorPolicy determines how a migration handles an error returned for a single item
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyAbort fails the migration on the first item error
+	ErrorPolicyAbort ErrorPolicy = "abort"
+
+	// ErrorPolicySkip skips the failed items. The migration succeeds and the number of failed items is recorded
+	ErrorPolicySkip ErrorPolicy = "skip"
+
+	// ErrorPolicyCollect processes all items and fails the migration with an *ItemErrors if any item failed.
+	// The failed items are processed again in the next execution
+	ErrorPolicyCollect ErrorPolicy = "collect"
+)
+
+const maxCollectedItemErrors = 100
+
+// validErrorPolicy returns an error if the policy is unknown
+func validErrorPolicy(policy ErrorPolicy) error {
+	if policy != ErrorPolicyAbort && policy != ErrorPolicySkip && policy != ErrorPolicyCollect {
+		return fmt.Errorf("unknown error policy %q", policy)
+	}
+
+	return nil
+}
+
+// ItemErrors is returned by a migration with ErrorPolicyCollect if the processing of one or more items failed
+type ItemErrors struct {
+	// Count number of failed items
+	Count int64
+
+	// Errors of the first failed items
+	Errors []error
+}
+
+func (e *ItemErrors) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("%d items failed", e.Count)
+	}
+
+	return fmt.Sprintf("%d items failed, first error: %s", e.Count, e.Errors[0])
+}
+
+func (e *ItemErrors) Unwrap() []error {
+	return e.Errors
+}
+
+// itemErrorCollector collects the item errors of a migration with ErrorPolicyCollect. It is safe for concurrent use.
+type itemErrorCollector struct {
+	mutex  sync.Mutex
+	count  int64
+	errors []error
+}
+
+func (c *itemErrorCollector) add(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.count++
+
+	if len(c.errors) < maxCollectedItemErrors {
+		c.errors = append(c.errors, err)
+	}
+}
+
+// err returns an *ItemErrors if any error was collected
+func (c *itemErrorCollector) err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.count == 0 {
+		return nil
+	}
+
+	return &ItemErrors{Count: c.count, Errors: c.errors}
+}
+
+// handleItemError handles an error returned for a single item according to the policy. Returns an error if the migration should be aborted.
+// The optional onItemError is called for every failed item that does not abort the migration.
+func handleItemError(ctx context.Context, policy ErrorPolicy, onItemError func(item map[string]types.AttributeValue, err error), collector *itemErrorCollector, item map[string]types.AttributeValue, err error) error {
+	if policy == ErrorPolicyAbort {
+		return err
+	}
+
+	ProgressReporterFromContext(ctx).AddFailed(1)
+
+	if onItemError != nil {
+		onItemError(item, err)
+	}
+
+	if policy == ErrorPolicyCollect {
+		collector.add(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemErrorCollector(t *testing.T) {
+	// Given
+	collector := &itemErrorCollector{}
+	firstErr := errors.New("first")
+
+	// When
+	for i := 0; i < maxCollectedItemErrors+10; i++ {
+		if i == 0 {
+			collector.add(firstErr)
+		} else {
+			collector.add(fmt.Errorf("error %d", i))
+		}
+	}
+
+	// Then
+	err := collector.err()
+
+	var itemErrors *ItemErrors
+
+	require.ErrorAs(t, err, &itemErrors)
+	require.Equal(t, int64(maxCollectedItemErrors+10), itemErrors.Count)
+	require.Len(t, itemErrors.Errors, maxCollectedItemErrors)
+	require.ErrorIs(t, err, firstErr)
+	require.EqualError(t, err, "110 items failed, first error: first")
+}
+
+func TestItemErrorCollector_NoErrors(t *testing.T) {
+	// Given
+	collector := &itemErrorCollector{}
+
+	// When
+	err := collector.err()
+
+	// Then
+	require.NoError(t, err)
+}
@@ -0,0 +1,115 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrHistoryMismatch is returned if the recorded migration history does not match the configured migrations
+var ErrHistoryMismatch = errors.New("migration history does not match the configured migrations")
+
+// VerifyHistory verifies the recorded migration history against the configured migrations.
+// Keys of executed migrations should still be configured with the same ID. Migrations that were renamed, removed or reordered are reported in a single error that wraps ErrHistoryMismatch.
+// Migrations that were executed before keys were introduced have no recorded key and are not verified.
+// A keyed migration that is configured after the first recorded key, with an ID up to the last executed migration, should be recorded. If not, it was inserted and would never be executed.
+// Execute, DryRun and RollbackTo verify the history before executing any migration.
+func (m *Migrator) VerifyHistory(ctx context.Context, client DynamodbClient) error {
+	metadata, err := m.getMetadataObject(ctx, client)
+	if err != nil {
+		return fmt.Errorf("loading metadata: %w", err)
+	}
+
+	return m.verifyHistory(&metadata)
+}
+
+func (m *Migrator) verifyHistory(metadata *metadataObject) error {
+	configuredIds := make(map[string]uint64, len(m.Migrations))
+	configuredKeys := make(map[uint64]string, len(m.Migrations))
+
+	var problems []string
+
+	for i := range m.Migrations {
+		key := m.Migrations[i].Key
+		if key == "" {
+			continue
+		}
+
+		migrationId := m.MigrationIdOffset + uint64(i) + 1
+
+		if otherId, found := configuredIds[key]; found {
+			problems = append(problems, fmt.Sprintf("key %q is configured for migration %d and %d", key, otherId, migrationId))
+
+			continue
+		}
+
+		configuredIds[key] = migrationId
+		configuredKeys[migrationId] = key
+	}
+
+	recordedKeys := make([]string, 0, len(metadata.MigrationKeys))
+	for key := range metadata.MigrationKeys {
+		recordedKeys = append(recordedKeys, key)
+	}
+
+	sort.Slice(recordedKeys, func(i, j int) bool {
+		return metadata.MigrationKeys[recordedKeys[i]] < metadata.MigrationKeys[recordedKeys[j]]
+	})
+
+	recordedIds := make(map[uint64]struct{}, len(recordedKeys))
+
+	for _, key := range recordedKeys {
+		recordedId := metadata.MigrationKeys[key]
+		recordedIds[recordedId] = struct{}{}
+
+		if recordedId > metadata.LastJobId {
+			problems = append(problems, fmt.Sprintf("migration %q is recorded with ID %d, after the last executed migration %d", key, recordedId, metadata.LastJobId))
+
+			continue
+		}
+
+		configuredId, found := configuredIds[key]
+
+		switch {
+		case found && configuredId != recordedId:
+			problems = append(problems, fmt.Sprintf("migration %q was executed with ID %d, but is configured with ID %d", key, recordedId, configuredId))
+		case !found && configuredKeys[recordedId] != "":
+			problems = append(problems, fmt.Sprintf("migration %d was executed as %q, but is configured as %q", recordedId, key, configuredKeys[recordedId]))
+		case !found && recordedId > m.MigrationIdOffset:
+			problems = append(problems, fmt.Sprintf("migration %q was executed with ID %d, but is not configured", key, recordedId))
+		}
+	}
+
+	// Keys are recorded since the first recorded key, so a keyed migration after it without recorded key was inserted after the following migrations were executed
+	if len(recordedKeys) > 0 {
+		firstRecordedId := metadata.MigrationKeys[recordedKeys[0]]
+
+		for i := range m.Migrations {
+			key := m.Migrations[i].Key
+			migrationId := m.MigrationIdOffset + uint64(i) + 1
+
+			if key == "" || migrationId <= firstRecordedId || migrationId > metadata.LastJobId {
+				continue
+			}
+
+			if _, recorded := metadata.MigrationKeys[key]; recorded {
+				continue
+			}
+
+			// Another migration was executed with this ID, which is reported above
+			if _, found := recordedIds[migrationId]; found {
+				continue
+			}
+
+			problems = append(problems, fmt.Sprintf("migration %q is configured with ID %d, but was not executed before the last executed migration %d", key, migrationId, metadata.LastJobId))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrHistoryMismatch, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
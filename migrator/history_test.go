@@ -0,0 +1,255 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func keyedMigration(key string, executed *[]string) Migration {
+	return Migration{
+		Key:  key,
+		Name: key,
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			*executed = append(*executed, key)
+
+			return nil
+		},
+	}
+}
+
+func expectMetadata(client *mocks.DynamodbClient, migrationTable string, item map[string]types.AttributeValue) {
+	client.EXPECT().GetItem(mock.Anything, &dynamodb.GetItemInput{
+		TableName: &migrationTable,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: metadataPK},
+		},
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+}
+
+func TestMigrator_Execute_RecordsKeys(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+		"migrationKeys": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"create-users": &types.AttributeValueMemberN{Value: "1"},
+		}},
+	})
+
+	storeItems := make([]*dynamodb.TransactWriteItemsInput, 0, 2)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Twice()
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 0, keyedMigration("create-users", &executed), keyedMigration("add-email", &executed), keyedMigration("add-phone", &executed))
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []string{"add-email", "add-phone"}, executed)
+	require.Len(t, storeItems, 2)
+
+	update := storeItems[1].TransactItems[0].Update
+	require.Equal(t, "SET #lastJobId = :lastJobId, #migrationKeys = :migrationKeys", *update.UpdateExpression)
+	require.Equal(t, map[string]string{"#lastJobId": "lastJobId", "#migrationKeys": "migrationKeys"}, update.ExpressionAttributeNames)
+	require.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"create-users": &types.AttributeValueMemberN{Value: "1"},
+		"add-email":    &types.AttributeValueMemberN{Value: "2"},
+		"add-phone":    &types.AttributeValueMemberN{Value: "3"},
+	}}, update.ExpressionAttributeValues[":migrationKeys"])
+
+	require.Equal(t, &types.AttributeValueMemberS{Value: "add-phone"}, storeItems[1].TransactItems[1].Put.Item["key"])
+}
+
+func TestMigrator_Execute_LegacyHistoryWithoutKeys(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+	})
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 0, keyedMigration("create-users", &executed), keyedMigration("add-email", &executed))
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []string{"add-email"}, executed)
+}
+
+func TestMigrator_Execute_HistoryMismatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		offset        uint64
+		lastJobId     string
+		recordedKeys  map[string]string
+		configured    []string
+		expectedError string
+	}{
+		{
+			name:          "reordered",
+			lastJobId:     "2",
+			recordedKeys:  map[string]string{"create-users": "1", "add-email": "2"},
+			configured:    []string{"add-email", "create-users", "add-phone"},
+			expectedError: `migration "create-users" was executed with ID 1, but is configured with ID 2`,
+		},
+		{
+			name:          "renamed",
+			lastJobId:     "2",
+			recordedKeys:  map[string]string{"create-users": "1", "add-email": "2"},
+			configured:    []string{"create-users", "add-mail", "add-phone"},
+			expectedError: `migration 2 was executed as "add-email", but is configured as "add-mail"`,
+		},
+		{
+			name:          "removed",
+			lastJobId:     "3",
+			recordedKeys:  map[string]string{"create-users": "1", "add-email": "2", "add-phone": "3"},
+			configured:    []string{"create-users", "add-phone"},
+			expectedError: `migration "add-phone" was executed with ID 3, but is configured with ID 2`,
+		},
+		{
+			name:          "removed at the end",
+			lastJobId:     "2",
+			recordedKeys:  map[string]string{"create-users": "1", "add-email": "2"},
+			configured:    []string{"create-users"},
+			expectedError: `migration "add-email" was executed with ID 2, but is not configured`,
+		},
+		{
+			name:          "gap",
+			lastJobId:     "1",
+			recordedKeys:  map[string]string{"create-users": "1", "add-email": "2"},
+			configured:    []string{"create-users", "add-email"},
+			expectedError: `migration "add-email" is recorded with ID 2, after the last executed migration 1`,
+		},
+		{
+			name:          "inserted",
+			lastJobId:     "2",
+			recordedKeys:  map[string]string{"create-users": "1"},
+			configured:    []string{"create-users", "add-phone", ""},
+			expectedError: `migration "add-phone" is configured with ID 2, but was not executed before the last executed migration 2`,
+		},
+		{
+			name:          "inserted before keyed migrations",
+			lastJobId:     "2",
+			recordedKeys:  map[string]string{"create-users": "1", "add-email": "2"},
+			configured:    []string{"create-users", "add-phone", "add-email"},
+			expectedError: `migration "add-email" was executed with ID 2, but is configured with ID 3`,
+		},
+		{
+			name:          "duplicate key",
+			lastJobId:     "0",
+			configured:    []string{"create-users", "create-users"},
+			expectedError: `key "create-users" is configured for migration 1 and 2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			migrationTable := "migration_table"
+
+			item := map[string]types.AttributeValue{
+				"lastJobId": &types.AttributeValueMemberN{Value: tt.lastJobId},
+			}
+
+			if tt.recordedKeys != nil {
+				keys := make(map[string]types.AttributeValue, len(tt.recordedKeys))
+				for key, id := range tt.recordedKeys {
+					keys[key] = &types.AttributeValueMemberN{Value: id}
+				}
+
+				item["migrationKeys"] = &types.AttributeValueMemberM{Value: keys}
+			}
+
+			client := mocks.NewDynamodbClient(t)
+			expectMetadata(client, migrationTable, item)
+
+			var executed []string
+
+			migrations := make([]Migration, 0, len(tt.configured))
+			for _, key := range tt.configured {
+				migrations = append(migrations, keyedMigration(key, &executed))
+			}
+
+			migrator := NewMigrator(migrationTable, tt.offset, migrations...)
+
+			// When
+			err := migrator.Execute(context.Background(), client)
+
+			// Then
+			require.ErrorIs(t, err, ErrHistoryMismatch)
+			require.ErrorContains(t, err, tt.expectedError)
+			require.Empty(t, executed)
+		})
+	}
+}
+
+func TestMigrator_VerifyHistory_PrunedMigrations(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "2"},
+		"migrationKeys": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"create-users": &types.AttributeValueMemberN{Value: "1"},
+			"add-email":    &types.AttributeValueMemberN{Value: "2"},
+		}},
+	})
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 1, keyedMigration("add-email", &executed))
+
+	// When
+	err := migrator.VerifyHistory(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestMigrator_VerifyHistory_KeysAddedToLegacyMigrations(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "3"},
+		"migrationKeys": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"add-phone": &types.AttributeValueMemberN{Value: "3"},
+		}},
+	})
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 0, keyedMigration("create-users", &executed), keyedMigration("add-email", &executed), keyedMigration("add-phone", &executed))
+
+	// When
+	err := migrator.VerifyHistory(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
@@ -2,16 +2,21 @@ package migrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/distrlock"
 )
 
 const metadataPK = "#METADATA"
+const lockPK = "#MIGRATION_LOCK"
 
 // Interface validation check
 var _ DynamodbClient = (*dynamodb.Client)(nil)
@@ -25,19 +30,41 @@ type DynamodbClient interface {
 	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
 	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.QueryOutput, error)
 	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
 }
 
 // Migration to execute
 type Migration struct {
+	// Key is an optional stable identity of the migration, e.g. a name or a declared version.
+	// Keys are recorded in the migration metadata table and verified against the configured migrations before executing them.
+	Key string
+
 	// Name of the migration
 	Name string
 
 	// Description of the migration
 	Description string
 
+	// Checksum is an optional fingerprint of the migration, e.g. a version or a Checksum of its source.
+	// It is recorded on success. If the checksum of an executed migration changes, the execution fails or warns, depending on the ChecksumValidation of the Migrator.
+	Checksum string
+
+	// Tags of the migration, e.g. the environments or tenants it applies to. Tagged migrations are skipped if they are not selected by the Selector passed to Execute.
+	Tags []string
+
+	// Condition is an optional predicate that is evaluated right before the migration is executed.
+	// If it returns false, the migration is skipped and the returned reason is recorded.
+	// The skip is permanent: the condition is never evaluated again, as the migrations after it are executed and the IDs must stay consistent.
+	// Only return false for conditions that will not change, e.g. the environment. If the condition is only temporarily false, e.g. a table that is not created yet,
+	// return an error instead: the execution stops before the migration and the condition is evaluated again by the next Execute.
+	Condition func(ctx context.Context, client DynamodbClient) (bool, string, error)
+
 	// Migration function
 	MigratorFn func(ctx context.Context, client DynamodbClient) error
 
+	// RollbackFn reverts the migration. Optional, but required to roll back the migration with RollbackTo
+	RollbackFn func(ctx context.Context, client DynamodbClient) error
+
 	// JobMetadata to store in the migration metadata table
 	JobMetadata map[string]interface{}
 }
@@ -54,6 +81,29 @@ type Migrator struct {
 	MigrationIdOffset  uint64
 	MigrationTableName string
 	Migrations         []Migration
+	Lock               *MigratorLock
+	ChecksumValidation *ChecksumValidation
+	AttemptHistory     *AttemptHistory
+	ProgressReporting  *ProgressReporting
+}
+
+type LockMode int
+
+const (
+	// LockModeWait waits until the instance holding the lock is done
+	LockModeWait LockMode = iota
+
+	// LockModeSkip skips the execution if another instance holds the lock
+	LockModeSkip
+)
+
+// ErrMigrationLocked is returned in LockModeSkip if another instance holds the lock, so no migration was executed
+var ErrMigrationLocked = errors.New("migrations are locked by another instance")
+
+// MigratorLock configures the distributed lock that guards the execution of migrations
+type MigratorLock struct {
+	Mode        LockMode
+	LockOptions []func(options *distrlock.Options)
 }
 
 // NewMigrator creates a new Migrator that can execute a migration.
@@ -72,34 +122,129 @@ func NewMigrator(migrationTableName string, migrationOffsetId uint64, migrations
 	}
 }
 
-func (m *Migrator) Execute(ctx context.Context, client DynamodbClient) error {
+// WithLock guards the execution of migrations with a distributed lock in the migration metadata table, so migrations are never executed concurrently by multiple instances.
+// The lock is kept alive while migrations are running. Depending on the mode, instances that lose the race wait or skip the execution.
+// The lock options are passed to the distrlock.RepositoryLockHandler. The lock timeout should be larger than the expected duration of a single DynamoDB request.
+func (m *Migrator) WithLock(mode LockMode, lockOptions ...func(options *distrlock.Options)) *Migrator {
+	m.Lock = &MigratorLock{Mode: mode, LockOptions: lockOptions}
+
+	return m
+}
+
+// Execute executes all migrations that were not executed successfully before.
+// If a lock is configured, the migrations are executed while the lock is held. In LockModeSkip, ErrMigrationLocked is returned without executing any migration if another instance holds the lock.
+// Migrations that are not selected by the Selector of the options or of which the Condition is not met are skipped. Skipped migrations are recorded with the reason and are not executed later on, so IDs stay consistent across environments.
+func (m *Migrator) Execute(ctx context.Context, client DynamodbClient, optFn ...ExecuteOptionFn) error {
+	options := newExecuteOptions(optFn)
+
+	return m.withLock(ctx, client, func(ctx context.Context, client DynamodbClient) error {
+		return m.execute(ctx, client, options)
+	})
+}
+
+// withLock executes fn while the lock is held, if a lock is configured
+func (m *Migrator) withLock(ctx context.Context, client DynamodbClient, fn func(ctx context.Context, client DynamodbClient) error) error {
+	if m.Lock == nil {
+		return fn(ctx, client)
+	}
+
+	var doOptions []func(options *distrlock.DoOptions)
+	if m.Lock.Mode == LockModeSkip {
+		doOptions = append(doOptions, distrlock.WithTryLock())
+	}
+
+	lockHandler := distrlock.New(client, m.MigrationTableName, "PK", m.Lock.LockOptions...)
+
+	err := lockHandler.Do(ctx, &types.AttributeValueMemberS{Value: lockPK}, func(ctx context.Context, _ *distrlock.Lock) error {
+		return fn(ctx, client)
+	}, doOptions...)
+	if errors.Is(err, distrlock.ErrLockNotAcquired) {
+		return fmt.Errorf("%w: %w", ErrMigrationLocked, err)
+	}
+
+	return err
+}
+
+func (m *Migrator) execute(ctx context.Context, client DynamodbClient, options ExecuteOptions) error {
 	metadata, err := m.getMetadataObject(ctx, client)
 	if err != nil {
 		return fmt.Errorf("loading metadata: %w", err)
 	}
 
-	for i, migration := range m.Migrations {
+	err = m.verifyHistory(&metadata)
+	if err != nil {
+		return err
+	}
+
+	err = m.validateChecksums(ctx, client, &metadata)
+	if err != nil {
+		return err
+	}
+
+	for i := range m.Migrations {
 		migrationId := m.MigrationIdOffset + uint64(i) + 1
 		if migrationId <= metadata.LastJobId {
 			continue
 		}
 
-		start := time.Now()
+		migration := &m.Migrations[i]
 
-		err = migration.MigratorFn(ctx, client)
+		selected, reason, err := m.isSelected(ctx, client, migration, options)
 		if err != nil {
-			return fmt.Errorf("running migration %s: %w", migration.Name, err)
+			return fmt.Errorf("evaluating condition of migration %s: %w", migration.Name, err)
 		}
 
-		err = m.annotateSuccessfulRun(ctx, client, migrationId, &m.Migrations[i], start, time.Now())
+		if !selected {
+			err = m.annotateSkippedRun(ctx, client, &metadata, migrationId, migration, reason)
+			if err != nil {
+				return fmt.Errorf("updating migration: %w", err)
+			}
+
+			continue
+		}
+
+		err = m.runMigration(ctx, client, &metadata, migrationId, migration)
 		if err != nil {
-			return fmt.Errorf("updating migration: %w", err)
+			return err
 		}
 	}
 
 	return nil
 }
 
+// runMigration executes a single migration and marks it as successful. If attempt history is enabled, the attempt is recorded.
+func (m *Migrator) runMigration(ctx context.Context, client DynamodbClient, metadata *metadataObject, migrationId uint64, migration *Migration) error {
+	start := time.Now()
+
+	run := &migrationRun{client: client, tableName: m.MigrationTableName, id: migrationId}
+
+	attempt, err := m.startAttempt(ctx, client, migrationId, start)
+	if err != nil {
+		return fmt.Errorf("recording attempt: %w", err)
+	}
+
+	stopProgressReporting := m.startProgressReporting(run, migration, start)
+
+	err = migration.MigratorFn(withMigrationRun(ctx, run), client)
+	if err != nil {
+		err = fmt.Errorf("running migration %s: %w", migration.Name, err)
+	} else {
+		err = m.annotateSuccessfulRun(ctx, client, metadata, migrationId, migration, run, start, time.Now())
+		if err != nil {
+			err = fmt.Errorf("updating migration: %w", err)
+		}
+	}
+
+	stopProgressReporting(err)
+
+	finishErr := m.finishAttempt(ctx, client, attempt, run, err)
+	if finishErr != nil {
+		return errors.Join(err, fmt.Errorf("recording attempt: %w", finishErr))
+	}
+
+	return err
+}
+
 func (m *Migrator) getMetadataObject(ctx context.Context, client DynamodbClient) (metadata metadataObject, err error) {
 	result, err := client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: &m.MigrationTableName,
@@ -118,44 +263,99 @@ func (m *Migrator) getMetadataObject(ctx context.Context, client DynamodbClient)
 	return
 }
 
-func (m *Migrator) annotateSuccessfulRun(ctx context.Context, client DynamodbClient, id uint64, migration *Migration, startTime time.Time, endTime time.Time) error {
+func (m *Migrator) annotateSuccessfulRun(ctx context.Context, client DynamodbClient, metadata *metadataObject, id uint64, migration *Migration, run *migrationRun, startTime time.Time, endTime time.Time) error {
 	migrationResult := migrationObject{
 		PK:          fmt.Sprintf("MIGRATION#%d", id),
 		ID:          id,
+		Key:         migration.Key,
 		Name:        migration.Name,
 		Description: migration.Description,
+		Checksum:    migration.Checksum,
+		ItemErrors:  run.failedItems(),
 		StartTime:   startTime,
 		EndTime:     endTime,
 	}
 
+	return m.storeMigrationResult(ctx, client, metadata, migration, &migrationResult, run.recordedMetadata(), run.hasCheckpoint())
+}
+
+// storeMigrationResult marks the migration as done: lastJobId is increased and the migration record is stored in a single transaction.
+// The run metadata and the job metadata are added to the migration record. If clearCheckpoint is true, the checkpoint of the migration is removed in the same transaction.
+func (m *Migrator) storeMigrationResult(ctx context.Context, client DynamodbClient, metadata *metadataObject, migration *Migration, migrationResult *migrationObject, runMetadata map[string]interface{}, clearCheckpoint bool) error {
+	id := migrationResult.ID
+
 	item, err := attributevalue.MarshalMap(migrationResult)
 	if err != nil {
 		return err
 	}
 
-	for k, v := range migration.JobMetadata {
-		if _, found := item[k]; !found {
-			av, avErr := attributevalue.Marshal(v)
-			if avErr != nil {
-				return avErr
+	for _, additionalMetadata := range []map[string]interface{}{runMetadata, migration.JobMetadata} {
+		for k, v := range additionalMetadata {
+			if _, found := item[k]; !found {
+				av, avErr := attributevalue.Marshal(v)
+				if avErr != nil {
+					return avErr
+				}
+
+				item[k] = av
 			}
+		}
+	}
+
+	metadataUpdate := &types.Update{
+		TableName: &m.MigrationTableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: metadataPK},
+		},
+		UpdateExpression:          aws.String("SET #lastJobId = :lastJobId"),
+		ExpressionAttributeNames:  map[string]string{"#lastJobId": "lastJobId"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":lastJobId": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id)}},
+	}
+
+	var migrationKeys map[string]uint64
+
+	if migration.Key != "" {
+		migrationKeys = make(map[string]uint64, len(metadata.MigrationKeys)+1)
+		for key, keyId := range metadata.MigrationKeys {
+			migrationKeys[key] = keyId
+		}
+
+		migrationKeys[migration.Key] = id
 
-			item[k] = av
+		keysAv, keysErr := attributevalue.Marshal(migrationKeys)
+		if keysErr != nil {
+			return keysErr
 		}
+
+		metadataUpdate.UpdateExpression = aws.String(*metadataUpdate.UpdateExpression + ", #migrationKeys = :migrationKeys")
+		metadataUpdate.ExpressionAttributeNames["#migrationKeys"] = "migrationKeys"
+		metadataUpdate.ExpressionAttributeValues[":migrationKeys"] = keysAv
+	}
+
+	var skippedMigrations map[string]string
+
+	if migrationResult.Skipped {
+		skippedMigrations = make(map[string]string, len(metadata.SkippedMigrations)+1)
+		for skippedId, reason := range metadata.SkippedMigrations {
+			skippedMigrations[skippedId] = reason
+		}
+
+		skippedMigrations[strconv.FormatUint(id, 10)] = migrationResult.SkipReason
+
+		skippedAv, skippedErr := attributevalue.Marshal(skippedMigrations)
+		if skippedErr != nil {
+			return skippedErr
+		}
+
+		metadataUpdate.UpdateExpression = aws.String(*metadataUpdate.UpdateExpression + ", #skippedMigrations = :skippedMigrations")
+		metadataUpdate.ExpressionAttributeNames["#skippedMigrations"] = "skippedMigrations"
+		metadataUpdate.ExpressionAttributeValues[":skippedMigrations"] = skippedAv
 	}
 
 	transaction := dynamodb.TransactWriteItemsInput{
 		TransactItems: []types.TransactWriteItem{
 			{
-				Update: &types.Update{
-					TableName: &m.MigrationTableName,
-					Key: map[string]types.AttributeValue{
-						"PK": &types.AttributeValueMemberS{Value: metadataPK},
-					},
-					UpdateExpression:          aws.String("SET #lastJobId = :lastJobId"),
-					ExpressionAttributeNames:  map[string]string{"#lastJobId": "lastJobId"},
-					ExpressionAttributeValues: map[string]types.AttributeValue{":lastJobId": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id)}},
-				},
+				Update: metadataUpdate,
 			},
 			{
 				Put: &types.Put{
@@ -166,7 +366,31 @@ func (m *Migrator) annotateSuccessfulRun(ctx context.Context, client DynamodbCli
 		},
 	}
 
+	if clearCheckpoint {
+		transaction.TransactItems = append(transaction.TransactItems, types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName: &m.MigrationTableName,
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("%s%d", checkpointPKPrefix, id)},
+				},
+			},
+		})
+	}
+
 	_, err = client.TransactWriteItems(ctx, &transaction)
+	if err != nil {
+		return err
+	}
 
-	return err
+	metadata.LastJobId = id
+
+	if migrationKeys != nil {
+		metadata.MigrationKeys = migrationKeys
+	}
+
+	if skippedMigrations != nil {
+		metadata.SkippedMigrations = skippedMigrations
+	}
+
+	return nil
 }
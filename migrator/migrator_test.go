@@ -413,3 +413,61 @@ func TestMigrator_Execute_FailedToExecuteMigration(t *testing.T) {
 	require.Equal(t, &types.AttributeValueMemberS{Value: "migration_1"}, storeItems[0].TransactItems[1].Put.Item["name"])
 	require.Equal(t, &types.AttributeValueMemberS{Value: "description_1"}, storeItems[0].TransactItems[1].Put.Item["description"])
 }
+
+func isLockPut(params *dynamodb.PutItemInput) bool {
+	pk, ok := params.Item["PK"].(*types.AttributeValueMemberS)
+
+	return ok && pk.Value == lockPK && *params.TableName == "migration_table"
+}
+
+func TestMigrator_Execute_WithLock(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().PutItem(mock.Anything, mock.MatchedBy(isLockPut)).Return(&dynamodb.PutItemOutput{}, nil)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+	client.EXPECT().DeleteItem(mock.Anything, mock.MatchedBy(func(params *dynamodb.DeleteItemInput) bool {
+		return params.Key["PK"].(*types.AttributeValueMemberS).Value == lockPK
+	})).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	migrationExecuted := false
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name: "migration_1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			migrationExecuted = true
+
+			return nil
+		},
+	}).WithLock(LockModeWait)
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.True(t, migrationExecuted)
+}
+
+func TestMigrator_Execute_WithLock_Skip(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().PutItem(mock.Anything, mock.MatchedBy(isLockPut)).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name: "migration_1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			return errors.New("should not be executed")
+		},
+	}).WithLock(LockModeSkip)
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.ErrorIs(t, err, ErrMigrationLocked)
+}
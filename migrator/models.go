@@ -1,16 +1,53 @@
 package migrator
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 type metadataObject struct {
-	LastJobId uint64 `dynamodbav:"lastJobId"`
+	LastJobId     uint64            `dynamodbav:"lastJobId"`
+	MigrationKeys map[string]uint64 `dynamodbav:"migrationKeys,omitempty"`
+
+	// SkippedMigrations maps the IDs of skipped migrations to the reason they were skipped
+	SkippedMigrations map[string]string `dynamodbav:"skippedMigrations,omitempty"`
+}
+
+// isSkipped returns true if the migration with the given ID was skipped
+func (o *metadataObject) isSkipped(id uint64) bool {
+	_, skipped := o.SkippedMigrations[strconv.FormatUint(id, 10)]
+
+	return skipped
 }
 
 type migrationObject struct {
 	PK          string    `dynamodbav:"PK"`
 	ID          uint64    `dynamodbav:"id"`
+	Key         string    `dynamodbav:"key,omitempty"`
 	Name        string    `dynamodbav:"name"`
 	Description string    `dynamodbav:"description"`
+	Checksum    string    `dynamodbav:"checksum,omitempty"`
+	ItemErrors  int64     `dynamodbav:"itemErrors,omitempty"`
+	Skipped     bool      `dynamodbav:"skipped,omitempty"`
+	SkipReason  string    `dynamodbav:"skipReason,omitempty"`
 	StartTime   time.Time `dynamodbav:"startTime"`
 	EndTime     time.Time `dynamodbav:"endTime"`
 }
+
+type attemptHistoryObject struct {
+	PK       string                   `dynamodbav:"PK"`
+	Attempts int                      `dynamodbav:"attempts"`
+	History  map[string]attemptObject `dynamodbav:"history"`
+}
+
+type attemptObject struct {
+	ID             uint64        `dynamodbav:"id"`
+	Attempt        int           `dynamodbav:"attempt"`
+	Status         AttemptStatus `dynamodbav:"status"`
+	StartTime      time.Time     `dynamodbav:"startTime"`
+	EndTime        *time.Time    `dynamodbav:"endTime,omitempty"`
+	Error          string        `dynamodbav:"error,omitempty"`
+	Host           string        `dynamodbav:"host,omitempty"`
+	ItemsProcessed int64         `dynamodbav:"itemsProcessed"`
+	ItemErrors     int64         `dynamodbav:"itemErrors,omitempty"`
+}
@@ -0,0 +1,170 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// PlanExitCodeValid is the exit code of a valid plan
+	PlanExitCodeValid = 0
+
+	// PlanExitCodeInvalid is the exit code of a plan with problems
+	PlanExitCodeInvalid = 1
+)
+
+// ErrInvalidPlan is returned by Plan.Err if the migrations can not be executed as configured
+var ErrInvalidPlan = errors.New("invalid migration plan")
+
+// PlannedMigration is a pending migration that Execute would run
+type PlannedMigration struct {
+	ID   uint64
+	Key  string
+	Name string
+	Tags []string
+
+	// Conditional is true if the migration has a Condition, which is only evaluated when the migration is executed
+	Conditional bool
+
+	// SkipReason is set if the migration would be skipped, because it is not selected by the Selector of the options
+	SkipReason string
+}
+
+// Plan lists the migrations that Execute would run and the problems that would prevent it
+type Plan struct {
+	LastJobId uint64
+
+	// Pending migrations in the order they would be executed
+	Pending []PlannedMigration
+
+	// Problems found while validating the migrations against the migration metadata table
+	Problems []string
+}
+
+// Valid returns true if no problems were found
+func (p *Plan) Valid() bool {
+	return len(p.Problems) == 0
+}
+
+// ExitCode returns PlanExitCodeValid if the plan is valid and PlanExitCodeInvalid otherwise, to be used as exit code of a CI gate
+func (p *Plan) ExitCode() int {
+	if p.Valid() {
+		return PlanExitCodeValid
+	}
+
+	return PlanExitCodeInvalid
+}
+
+// Err returns an error that wraps ErrInvalidPlan and lists all problems, or nil if the plan is valid
+func (p *Plan) Err() error {
+	if p.Valid() {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidPlan, strings.Join(p.Problems, "; "))
+}
+
+// Plan returns the pending migrations that Execute would run with the same options, together with their IDs, without executing them.
+// The configured migrations are validated: names should be unique and present, every migration should have a migration function, the executed migrations should match the MigrationIdOffset,
+// and the history and checksums are verified like Execute does. Problems are listed in the plan instead of returned as error, so they can all be reported at once.
+// A missing migration metadata table is reported as a problem as well. An error is only returned if the metadata table could not be read.
+func (m *Migrator) Plan(ctx context.Context, client DynamodbClient, optFn ...ExecuteOptionFn) (*Plan, error) {
+	options := newExecuteOptions(optFn)
+
+	plan := &Plan{}
+	plan.Problems = append(plan.Problems, m.validateMigrations()...)
+
+	metadata, err := m.getMetadataObject(ctx, client)
+	if err != nil {
+		var notFoundErr *types.ResourceNotFoundException
+		if !errors.As(err, &notFoundErr) {
+			return nil, fmt.Errorf("loading metadata: %w", err)
+		}
+
+		plan.Problems = append(plan.Problems, fmt.Sprintf("migration metadata table %s does not exist", m.MigrationTableName))
+
+		return plan, nil
+	}
+
+	plan.LastJobId = metadata.LastJobId
+
+	configuredUntil := m.MigrationIdOffset + uint64(len(m.Migrations))
+
+	switch {
+	case metadata.LastJobId < m.MigrationIdOffset:
+		plan.Problems = append(plan.Problems, fmt.Sprintf("migration ID offset %d is larger than the last executed migration %d, so migrations %d to %d would never be executed", m.MigrationIdOffset, metadata.LastJobId, metadata.LastJobId+1, m.MigrationIdOffset))
+	case metadata.LastJobId > configuredUntil:
+		plan.Problems = append(plan.Problems, fmt.Sprintf("last executed migration %d is not configured, migrations are configured up to %d", metadata.LastJobId, configuredUntil))
+	}
+
+	err = m.verifyHistory(&metadata)
+	if errors.Is(err, ErrHistoryMismatch) {
+		plan.Problems = append(plan.Problems, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = m.validateChecksums(ctx, client, &metadata)
+	if errors.Is(err, ErrChecksumMismatch) {
+		plan.Problems = append(plan.Problems, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
+	for i := range m.Migrations {
+		migration := &m.Migrations[i]
+
+		migrationId := m.MigrationIdOffset + uint64(i) + 1
+		if migrationId <= metadata.LastJobId {
+			continue
+		}
+
+		planned := PlannedMigration{
+			ID:          migrationId,
+			Key:         migration.Key,
+			Name:        migration.Name,
+			Tags:        migration.Tags,
+			Conditional: migration.Condition != nil,
+		}
+
+		if options.Selector != nil {
+			if selected, reason := options.Selector(migration); !selected {
+				planned.SkipReason = reason
+			}
+		}
+
+		plan.Pending = append(plan.Pending, planned)
+	}
+
+	return plan, nil
+}
+
+// validateMigrations validates the configured migrations without the migration metadata table
+func (m *Migrator) validateMigrations() []string {
+	var problems []string
+
+	names := make(map[string]uint64, len(m.Migrations))
+
+	for i := range m.Migrations {
+		migration := &m.Migrations[i]
+		migrationId := m.MigrationIdOffset + uint64(i) + 1
+
+		if migration.Name == "" {
+			problems = append(problems, fmt.Sprintf("migration %d has no name", migrationId))
+		} else if otherId, found := names[migration.Name]; found {
+			problems = append(problems, fmt.Sprintf("name %q is configured for migration %d and %d", migration.Name, otherId, migrationId))
+		} else {
+			names[migration.Name] = migrationId
+		}
+
+		if migration.MigratorFn == nil {
+			problems = append(problems, fmt.Sprintf("migration %d (%s) has no migration function", migrationId, migration.Name))
+		}
+	}
+
+	return problems
+}
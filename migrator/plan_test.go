@@ -0,0 +1,179 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func TestMigrator_Plan(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "11"},
+	})
+
+	var executed []string
+
+	conditional := taggedMigration("migration_13", &executed)
+	conditional.Condition = func(ctx context.Context, client DynamodbClient) (bool, string, error) {
+		return true, "", nil
+	}
+
+	migrator := NewMigrator(migrationTable, 10,
+		taggedMigration("migration_11", &executed),
+		taggedMigration("migration_12", &executed, "staging"),
+		conditional,
+	)
+
+	// When
+	plan, err := migrator.Plan(context.Background(), client, ExecuteWithTags("production"))
+
+	// Then
+	require.NoError(t, err)
+	require.Empty(t, executed)
+	require.True(t, plan.Valid())
+	require.Equal(t, PlanExitCodeValid, plan.ExitCode())
+	require.NoError(t, plan.Err())
+	require.Equal(t, uint64(11), plan.LastJobId)
+	require.Equal(t, []PlannedMigration{
+		{ID: 12, Name: "migration_12", Tags: []string{"staging"}, SkipReason: "tags [staging] do not match the selected tags [production]"},
+		{ID: 13, Name: "migration_13", Conditional: true},
+	}, plan.Pending)
+}
+
+func TestMigrator_Plan_Problems(t *testing.T) {
+	tests := []struct {
+		name             string
+		offset           uint64
+		lastJobId        string
+		migrations       []Migration
+		expectedProblems []string
+	}{
+		{
+			name:      "duplicate names",
+			lastJobId: "0",
+			migrations: []Migration{
+				{Name: "add-email", MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil }},
+				{Name: "add-email", MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil }},
+			},
+			expectedProblems: []string{`name "add-email" is configured for migration 1 and 2`},
+		},
+		{
+			name:      "missing name and function",
+			lastJobId: "0",
+			migrations: []Migration{
+				{},
+			},
+			expectedProblems: []string{"migration 1 has no name", "migration 1 () has no migration function"},
+		},
+		{
+			name:      "offset larger than last executed migration",
+			offset:    5,
+			lastJobId: "3",
+			migrations: []Migration{
+				{Name: "migration_6", MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil }},
+			},
+			expectedProblems: []string{"migration ID offset 5 is larger than the last executed migration 3, so migrations 4 to 5 would never be executed"},
+		},
+		{
+			name:      "last executed migration not configured",
+			lastJobId: "3",
+			migrations: []Migration{
+				{Name: "migration_1", MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil }},
+			},
+			expectedProblems: []string{"last executed migration 3 is not configured, migrations are configured up to 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			migrationTable := "migration_table"
+
+			client := mocks.NewDynamodbClient(t)
+			expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+				"lastJobId": &types.AttributeValueMemberN{Value: tt.lastJobId},
+			})
+
+			migrator := NewMigrator(migrationTable, tt.offset, tt.migrations...)
+
+			// When
+			plan, err := migrator.Plan(context.Background(), client)
+
+			// Then
+			require.NoError(t, err)
+			require.False(t, plan.Valid())
+			require.Equal(t, PlanExitCodeInvalid, plan.ExitCode())
+			require.ErrorIs(t, plan.Err(), ErrInvalidPlan)
+			require.Equal(t, tt.expectedProblems, plan.Problems)
+		})
+	}
+}
+
+func TestMigrator_Plan_HistoryMismatch(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+		"migrationKeys": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"create-users": &types.AttributeValueMemberN{Value: "1"},
+		}},
+	})
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 0, keyedMigration("add-email", &executed), keyedMigration("create-users", &executed))
+
+	// When
+	plan, err := migrator.Plan(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, plan.Problems, 1)
+	require.Contains(t, plan.Problems[0], `migration "create-users" was executed with ID 1, but is configured with ID 2`)
+}
+
+func TestMigrator_Plan_MissingMetadataTable(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(nil, &types.ResourceNotFoundException{Message: aws.String("table not found")}).Once()
+
+	migrator := NewMigrator(migrationTable, 0, Migration{Name: "migration_1", MigratorFn: func(ctx context.Context, client DynamodbClient) error { return nil }})
+
+	// When
+	plan, err := migrator.Plan(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []string{"migration metadata table migration_table does not exist"}, plan.Problems)
+	require.Equal(t, PlanExitCodeInvalid, plan.ExitCode())
+}
+
+func TestMigrator_Plan_LoadingMetadataFails(t *testing.T) {
+	// Given
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, errors.New("boom")).Once()
+
+	migrator := NewMigrator("migration_table", 0)
+
+	// When
+	_, err := migrator.Plan(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "loading metadata: boom")
+}
@@ -0,0 +1,209 @@
+package migrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// ProgressReporter reports the progress of a migration. Migrations executed by a Migrator get a ProgressReporter through the context, see ProgressReporterFromContext.
+// All methods are safe for concurrent use.
+type ProgressReporter interface {
+	// AddScanned adds n items that were read by the migration
+	AddScanned(n int64)
+
+	// AddUpdated adds n items that were written by the migration
+	AddUpdated(n int64)
+
+	// AddSkipped adds n items that were read, but did not require a write
+	AddSkipped(n int64)
+
+	// AddFailed adds n items that could not be migrated
+	AddFailed(n int64)
+
+	// SetTotalItems sets the estimated number of items the migration still has to scan, used to estimate the remaining time
+	SetTotalItems(n int64)
+}
+
+// Progress is a snapshot of the progress of a migration
+type Progress struct {
+	ID   uint64
+	Name string
+
+	ItemsScanned int64
+	ItemsUpdated int64
+	ItemsSkipped int64
+	ItemsFailed  int64
+
+	// TotalItems estimated number of items to scan. Zero if unknown
+	TotalItems int64
+
+	// Elapsed time since the migration started
+	Elapsed time.Duration
+
+	// ItemsPerSecond number of scanned items per second
+	ItemsPerSecond float64
+
+	// ETA estimated remaining time. Zero if unknown
+	ETA time.Duration
+
+	// Done is true for the last report of the migration. Err is set if the migration failed
+	Done bool
+	Err  error
+}
+
+// ProgressReporting configures the periodic progress reports of the Migrator
+type ProgressReporting struct {
+	Interval   time.Duration
+	OnProgress func(progress Progress)
+}
+
+// WithProgressReporting calls onProgress with the progress of the running migration every interval, e.g. to log it or to publish metrics.
+// A last report with Done set is sent once a migration finished. If the interval is zero, only the last report is sent. Migrations report their progress with the ProgressReporter in their context.
+// The scan migration templates estimate the total number of items with DescribeTable if the client is a SchemaClient.
+func (m *Migrator) WithProgressReporting(interval time.Duration, onProgress func(progress Progress)) *Migrator {
+	m.ProgressReporting = &ProgressReporting{Interval: interval, OnProgress: onProgress}
+
+	return m
+}
+
+// ProgressReporterFromContext returns the ProgressReporter of the migration that is executed by the Migrator.
+// If the migration is not executed by a Migrator, a ProgressReporter that ignores all reports is returned.
+func ProgressReporterFromContext(ctx context.Context) ProgressReporter {
+	if run := migrationRunFromContext(ctx); run != nil {
+		return run
+	}
+
+	return noopProgressReporter{}
+}
+
+func (r *migrationRun) AddScanned(n int64) {
+	r.itemsProcessed.Add(n)
+}
+
+func (r *migrationRun) AddUpdated(n int64) {
+	r.itemsUpdated.Add(n)
+}
+
+func (r *migrationRun) AddSkipped(n int64) {
+	r.itemsSkipped.Add(n)
+}
+
+func (r *migrationRun) AddFailed(n int64) {
+	r.itemErrors.Add(n)
+}
+
+func (r *migrationRun) SetTotalItems(n int64) {
+	r.totalItems.Store(n)
+}
+
+// progress returns a snapshot of the progress of the run
+func (r *migrationRun) progress(name string, elapsed time.Duration) Progress {
+	progress := Progress{
+		ID:           r.id,
+		Name:         name,
+		ItemsScanned: r.itemsProcessed.Load(),
+		ItemsUpdated: r.itemsUpdated.Load(),
+		ItemsSkipped: r.itemsSkipped.Load(),
+		ItemsFailed:  r.itemErrors.Load(),
+		TotalItems:   r.totalItems.Load(),
+		Elapsed:      elapsed,
+	}
+
+	if elapsed > 0 {
+		progress.ItemsPerSecond = float64(progress.ItemsScanned) / elapsed.Seconds()
+	}
+
+	if progress.TotalItems > 0 && progress.ItemsPerSecond > 0 {
+		remaining := progress.TotalItems - progress.ItemsScanned
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		progress.ETA = time.Duration(float64(remaining) / progress.ItemsPerSecond * float64(time.Second))
+	}
+
+	return progress
+}
+
+// startProgressReporting reports the progress of the run every interval, if progress reporting is enabled.
+// The returned function stops the reporting and sends the last report.
+func (m *Migrator) startProgressReporting(run *migrationRun, migration *Migration, start time.Time) func(migrationErr error) {
+	if m.ProgressReporting == nil || m.ProgressReporting.OnProgress == nil {
+		return func(error) {}
+	}
+
+	run.reportsProgress = true
+
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	if m.ProgressReporting.Interval > 0 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ticker := time.NewTicker(m.ProgressReporting.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					m.ProgressReporting.OnProgress(run.progress(migration.Name, time.Since(start)))
+				}
+			}
+		}()
+	}
+
+	return func(migrationErr error) {
+		close(stop)
+		wg.Wait()
+
+		progress := run.progress(migration.Name, time.Since(start))
+		progress.Done = true
+		progress.Err = migrationErr
+
+		m.ProgressReporting.OnProgress(progress)
+	}
+}
+
+// estimateTotalItems sets the total number of items of the progress to the approximate item count of the table, minus the items that were already scanned in previous executions.
+// The estimate is only made if the progress is reported and the client is a SchemaClient. It is best effort, so errors are ignored.
+func estimateTotalItems(ctx context.Context, client DynamodbClient, table string, alreadyScanned int64) {
+	run := migrationRunFromContext(ctx)
+	if run == nil || !run.reportsProgress {
+		return
+	}
+
+	schemaClient, ok := client.(SchemaClient)
+	if !ok {
+		return
+	}
+
+	result, err := schemaClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &table})
+	if err != nil || result.Table == nil || result.Table.ItemCount == nil {
+		return
+	}
+
+	remaining := *result.Table.ItemCount - alreadyScanned
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	run.SetTotalItems(remaining)
+}
+
+// noopProgressReporter ignores all reports
+type noopProgressReporter struct{}
+
+func (noopProgressReporter) AddScanned(int64)    {}
+func (noopProgressReporter) AddUpdated(int64)    {}
+func (noopProgressReporter) AddSkipped(int64)    {}
+func (noopProgressReporter) AddFailed(int64)     {}
+func (noopProgressReporter) SetTotalItems(int64) {}
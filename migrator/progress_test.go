@@ -0,0 +1,152 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func TestMigrationRun_Progress(t *testing.T) {
+	// Given
+	run := &migrationRun{id: 3}
+	run.AddScanned(50)
+	run.AddUpdated(30)
+	run.AddSkipped(15)
+	run.AddFailed(5)
+	run.SetTotalItems(150)
+
+	// When
+	progress := run.progress("migration_3", 10*time.Second)
+
+	// Then
+	require.Equal(t, Progress{
+		ID:             3,
+		Name:           "migration_3",
+		ItemsScanned:   50,
+		ItemsUpdated:   30,
+		ItemsSkipped:   15,
+		ItemsFailed:    5,
+		TotalItems:     150,
+		Elapsed:        10 * time.Second,
+		ItemsPerSecond: 5,
+		ETA:            20 * time.Second,
+	}, progress)
+}
+
+func TestProgressReporterFromContext_NotExecutedByMigrator(t *testing.T) {
+	// When
+	reporter := ProgressReporterFromContext(context.Background())
+
+	// Then
+	require.Equal(t, noopProgressReporter{}, reporter)
+	reporter.AddScanned(1)
+}
+
+func TestMigrator_Execute_ReportsProgress(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+
+	var mutex sync.Mutex
+
+	var reports []Progress
+
+	migrator := NewMigrator(migrationTable, 0, Migration{
+		Name: "migration_1",
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			reporter := ProgressReporterFromContext(ctx)
+			reporter.AddScanned(2)
+			reporter.AddUpdated(1)
+			reporter.AddFailed(1)
+
+			time.Sleep(20 * time.Millisecond)
+
+			return errors.New("boom")
+		},
+	}).WithProgressReporting(time.Millisecond, func(progress Progress) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		reports = append(reports, progress)
+	})
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "running migration migration_1: boom")
+	require.Greater(t, len(reports), 1)
+
+	last := reports[len(reports)-1]
+	require.True(t, last.Done)
+	require.EqualError(t, last.Err, "running migration migration_1: boom")
+	require.Equal(t, uint64(1), last.ID)
+	require.Equal(t, int64(2), last.ItemsScanned)
+	require.Equal(t, int64(1), last.ItemsUpdated)
+	require.Equal(t, int64(1), last.ItemsFailed)
+
+	for _, report := range reports[:len(reports)-1] {
+		require.False(t, report.Done)
+	}
+}
+
+func TestMigrator_Execute_ScanMigrationEstimatesTotalItems(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "table_to_migrate"
+
+	client := mocks.NewSchemaClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Twice()
+	client.EXPECT().DescribeTable(mock.Anything, &dynamodb.DescribeTableInput{TableName: &table}).Return(&dynamodb.DescribeTableOutput{
+		Table: &types.TableDescription{ItemCount: aws.Int64(10)},
+	}, nil).Once()
+
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{
+			{"PK": &types.AttributeValueMemberS{Value: "item_1"}, "upgrade": &types.AttributeValueMemberBOOL{Value: true}},
+			{"PK": &types.AttributeValueMemberS{Value: "item_2"}},
+		},
+	}, nil).Once()
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migration, err := NewScanAndUpdateMigration("scan", "scan migration", table, func(ctx context.Context, item map[string]types.AttributeValue) *dynamodb.UpdateItemInput {
+		if _, found := item["upgrade"]; found {
+			return &dynamodb.UpdateItemInput{TableName: &table}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	var reports []Progress
+
+	migrator := NewMigrator(migrationTable, 0, *migration).WithProgressReporting(0, func(progress Progress) {
+		reports = append(reports, progress)
+	})
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.True(t, reports[0].Done)
+	require.Equal(t, int64(10), reports[0].TotalItems)
+	require.Equal(t, int64(2), reports[0].ItemsScanned)
+	require.Equal(t, int64(1), reports[0].ItemsUpdated)
+	require.Equal(t, int64(1), reports[0].ItemsSkipped)
+}
@@ -0,0 +1,247 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/executor"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder"
+)
+
+type QueryAndUpdateMigrationOptions struct {
+	Metadata           map[string]interface{}
+	CheckpointInterval *time.Duration
+
+	// WritesPerSecond maximum number of write operations per second. Zero means unlimited
+	WritesPerSecond float64
+
+	// ErrorPolicy determines how an error returned by the writeFn for a single item, or a failed write of a single item, is handled. Default value is ErrorPolicyAbort
+	ErrorPolicy ErrorPolicy
+
+	// OnItemError is called for every failed item if the ErrorPolicy does not abort the migration
+	OnItemError func(item map[string]types.AttributeValue, err error)
+}
+
+type QueryOptionFn func(*QueryAndUpdateMigrationOptions)
+
+// NewQueryAndUpdateMigration create a migration that will execute a query, e.g. on a global secondary index. For each returned item the writeFn will be executed.
+// All write operations returned by the writeFn will be executed, like NewScanAndWriteMigration. If the writeFn returns an error, the ErrorPolicy determines whether the migration fails.
+// Like NewScanAndUpdateMigration, the progress of the query is checkpointed if the migration is executed by a Migrator.
+func NewQueryAndUpdateMigration(name string, description string, queryBuilder *inputbuilder.QueryBuilder, writeFn func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error), optFn ...QueryOptionFn) (*Migration, error) {
+	options := QueryAndUpdateMigrationOptions{
+		ErrorPolicy: ErrorPolicyAbort,
+	}
+
+	for _, opt := range optFn {
+		opt(&options)
+	}
+
+	if options.WritesPerSecond < 0 {
+		return nil, fmt.Errorf("writes per second should not be negative, got %f", options.WritesPerSecond)
+	}
+
+	err := validErrorPolicy(options.ErrorPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	queryInput := &dynamodb.QueryInput{}
+
+	err = queryBuilder.Build(queryInput)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{"table": *queryInput.TableName}
+
+	if queryInput.IndexName != nil {
+		metadata["index"] = *queryInput.IndexName
+	}
+
+	if options.ErrorPolicy != ErrorPolicyAbort {
+		metadata["errorPolicy"] = string(options.ErrorPolicy)
+	}
+
+	for key, value := range options.Metadata {
+		metadata[key] = value
+	}
+
+	query := &queryAndUpdate{
+		queryInput: queryInput,
+		writeFn:    writeFn,
+		options:    options,
+	}
+
+	return &Migration{
+		Name:        name,
+		Description: description,
+		MigratorFn:  query.migrate,
+		JobMetadata: metadata,
+	}, nil
+}
+
+// QueryAndUpdateMigrationWithMetadata add metadata to QueryAndUpdateMigration
+func QueryAndUpdateMigrationWithMetadata(metadata map[string]interface{}) QueryOptionFn {
+	return func(options *QueryAndUpdateMigrationOptions) {
+		options.Metadata = metadata
+	}
+}
+
+// QueryAndUpdateMigrationWithCheckpointInterval set the minimal time between two stored checkpoints of the query progress on QueryAndUpdateMigration.
+// Default value is 30 seconds. A zero interval disables checkpoints.
+func QueryAndUpdateMigrationWithCheckpointInterval(interval time.Duration) QueryOptionFn {
+	return func(options *QueryAndUpdateMigrationOptions) {
+		options.CheckpointInterval = &interval
+	}
+}
+
+// QueryAndUpdateMigrationWithWritesPerSecond throttle the write operations of QueryAndUpdateMigration to at most writesPerSecond per second. Default value is unlimited.
+func QueryAndUpdateMigrationWithWritesPerSecond(writesPerSecond float64) QueryOptionFn {
+	return func(options *QueryAndUpdateMigrationOptions) {
+		options.WritesPerSecond = writesPerSecond
+	}
+}
+
+// QueryAndUpdateMigrationWithErrorPolicy set how errors returned by the writeFn of QueryAndUpdateMigration for a single item are handled. Default value is ErrorPolicyAbort.
+// The policy applies to failed writes of an item as well, e.g. a failed condition. The remaining write operations of a failed item are not executed.
+// The optional onItemError is called for every failed item if the policy does not abort the migration, e.g. to log the item.
+// The policy is recorded in the job metadata and the number of failed items in the migration metadata table.
+func QueryAndUpdateMigrationWithErrorPolicy(policy ErrorPolicy, onItemError func(item map[string]types.AttributeValue, err error)) QueryOptionFn {
+	return func(options *QueryAndUpdateMigrationOptions) {
+		options.ErrorPolicy = policy
+		options.OnItemError = onItemError
+	}
+}
+
+// queryAndUpdate executes a QueryAndUpdateMigration
+type queryAndUpdate struct {
+	queryInput *dynamodb.QueryInput
+	writeFn    func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error)
+	options    QueryAndUpdateMigrationOptions
+}
+
+func (q *queryAndUpdate) migrate(ctx context.Context, client DynamodbClient) error {
+	checkpointInterval := defaultCheckpointInterval
+	if q.options.CheckpointInterval != nil {
+		checkpointInterval = *q.options.CheckpointInterval
+	}
+
+	checkpoint, err := loadScanCheckpoint(ctx, 1, checkpointInterval)
+	if err != nil {
+		return err
+	}
+
+	startKey, done := checkpoint.startKey(0)
+	if done {
+		return nil
+	}
+
+	input := *q.queryInput
+	input.ExclusiveStartKey = startKey
+
+	limiter := newRateLimiter(q.options.WritesPerSecond)
+	itemErrors := &itemErrorCollector{}
+
+	var pageCount int64
+
+	// Once errors of items are collected, the checkpoint is no longer advanced, so the failed items are processed again in the next execution
+	queryFailed := false
+
+	progress := ProgressReporterFromContext(ctx)
+
+	exec := executor.New(client)
+	items := exec.Query(ctx, &input, executor.WithPageEndMarkers())
+
+	for item := range items {
+		switch v := item.(type) {
+		case error:
+			return v
+		case executor.PageEnd:
+			if !queryFailed {
+				err = checkpoint.pageEnd(ctx, 0, v.LastEvaluatedKey, pageCount)
+				if err != nil {
+					return err
+				}
+			}
+
+			pageCount = 0
+		case map[string]types.AttributeValue:
+			progress.AddScanned(1)
+
+			pageCount++
+
+			operations, writeErr := q.writeFn(ctx, v)
+			if writeErr != nil {
+				err = handleItemError(ctx, q.options.ErrorPolicy, q.options.OnItemError, itemErrors, v, writeErr)
+				if err != nil {
+					return err
+				}
+
+				queryFailed = queryFailed || q.options.ErrorPolicy == ErrorPolicyCollect
+
+				continue
+			}
+
+			updated := false
+			failed := false
+
+			for _, operation := range operations {
+				if operation == nil {
+					continue
+				}
+
+				err = limiter.wait(ctx, 1)
+				if err != nil {
+					return err
+				}
+
+				err = operation.execute(ctx, client)
+				if err != nil {
+					if ctx.Err() != nil {
+						return err
+					}
+
+					// The remaining operations of the failed item are not executed
+					err = handleItemError(ctx, q.options.ErrorPolicy, q.options.OnItemError, itemErrors, v, err)
+					if err != nil {
+						return err
+					}
+
+					queryFailed = queryFailed || q.options.ErrorPolicy == ErrorPolicyCollect
+					failed = true
+
+					break
+				}
+
+				updated = true
+			}
+
+			switch {
+			case failed:
+			case updated:
+				progress.AddUpdated(1)
+			default:
+				progress.AddSkipped(1)
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if queryFailed {
+		return itemErrors.err()
+	}
+
+	err = checkpoint.segmentDone(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	return itemErrors.err()
+}
@@ -0,0 +1,301 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/inputbuilder"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/expressionutils"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/updateexpression"
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func newTestQueryBuilder(table string) *inputbuilder.QueryBuilder {
+	queryBuilder := inputbuilder.NewQueryBuilder()
+	queryBuilder.WithTableName(table)
+	queryBuilder.WithIndexName("TypeIndex")
+	queryBuilder.WithHashKeyCondition(conditionexpression.Equal(expressionutils.AttributePath("Type"), "USER"))
+
+	return &queryBuilder
+}
+
+func TestNewQueryAndUpdateMigration(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		pk := item["PK"].(*types.AttributeValueMemberS).Value
+
+		switch pk {
+		case "USER#1":
+			update := inputbuilder.NewUpdateBuilder()
+			update.WithTableName(table)
+			update.WithKey("PK", pk)
+			update.AppendSet(updateexpression.Set("migrated", true))
+
+			return []*WriteOperation{UpdateOperation(update)}, nil
+		case "USER#2":
+			return []*WriteOperation{DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: item})}, nil
+		case "USER#3":
+			return []*WriteOperation{PutOperation(&dynamodb.PutItemInput{TableName: &table, Item: item})}, nil
+		default:
+			return nil, nil
+		}
+	}
+
+	// When
+	m, err := NewQueryAndUpdateMigration("query_migration", "Query migration", newTestQueryBuilder(table), writeFn,
+		QueryAndUpdateMigrationWithMetadata(map[string]interface{}{"foo": "bar"}))
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "query_migration", m.Name)
+	require.Equal(t, map[string]interface{}{"table": table, "index": "TypeIndex", "foo": "bar"}, m.JobMetadata)
+
+	lastEvaluatedKey := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+			{"PK": &types.AttributeValueMemberS{Value: "USER#2"}},
+		},
+		LastEvaluatedKey: lastEvaluatedKey,
+	}, nil).Once()
+
+	client.EXPECT().Query(mock.Anything, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{"PK": &types.AttributeValueMemberS{Value: "USER#3"}},
+			{"PK": &types.AttributeValueMemberS{Value: "USER#4"}},
+		},
+	}, nil).Once()
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == table && *input.UpdateExpression == "SET #migrated = :set_migrated"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	client.EXPECT().DeleteItem(mock.Anything, &dynamodb.DeleteItemInput{TableName: &table, Key: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}}).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+	client.EXPECT().PutItem(mock.Anything, &dynamodb.PutItemInput{TableName: &table, Item: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#3"}}}).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	err = m.MigratorFn(context.Background(), client)
+	require.NoError(t, err)
+}
+
+func TestNewQueryAndUpdateMigration_WriteFnError(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	m, err := NewQueryAndUpdateMigration("query_migration", "Query migration", newTestQueryBuilder(table), func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return nil, errors.New("boom")
+	})
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Query(mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}},
+	}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "boom")
+}
+
+func TestNewQueryAndUpdateMigration_ErrorPolicy(t *testing.T) {
+	itemErr := errors.New("invalid item")
+
+	tests := []struct {
+		name             string
+		policy           ErrorPolicy
+		expectedMetadata map[string]interface{}
+		expectedErr      func(t *testing.T, err error)
+	}{
+		{
+			name:             "skip",
+			policy:           ErrorPolicySkip,
+			expectedMetadata: map[string]interface{}{"table": "table_to_migrate", "index": "TypeIndex", "errorPolicy": "skip"},
+			expectedErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:             "collect",
+			policy:           ErrorPolicyCollect,
+			expectedMetadata: map[string]interface{}{"table": "table_to_migrate", "index": "TypeIndex", "errorPolicy": "collect"},
+			expectedErr: func(t *testing.T, err error) {
+				var itemErrors *ItemErrors
+
+				require.ErrorAs(t, err, &itemErrors)
+				require.Equal(t, int64(1), itemErrors.Count)
+				require.ErrorIs(t, err, itemErr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			table := "table_to_migrate"
+
+			writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+				if item["PK"].(*types.AttributeValueMemberS).Value == "USER#1" {
+					return nil, itemErr
+				}
+
+				return []*WriteOperation{DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: item})}, nil
+			}
+
+			var failedItems []map[string]types.AttributeValue
+
+			m, err := NewQueryAndUpdateMigration("query_migration", "Query migration", newTestQueryBuilder(table), writeFn,
+				QueryAndUpdateMigrationWithErrorPolicy(tt.policy, func(item map[string]types.AttributeValue, err error) {
+					failedItems = append(failedItems, item)
+				}))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedMetadata, m.JobMetadata)
+
+			client := mocks.NewDynamodbClient(t)
+			client.EXPECT().Query(mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+				Items: []map[string]types.AttributeValue{
+					{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+					{"PK": &types.AttributeValueMemberS{Value: "USER#2"}},
+				},
+			}, nil).Once()
+
+			client.EXPECT().DeleteItem(mock.Anything, &dynamodb.DeleteItemInput{TableName: &table, Key: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}}).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+			// When
+			err = m.MigratorFn(context.Background(), client)
+
+			// Then
+			tt.expectedErr(t, err)
+			require.Equal(t, []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}}, failedItems)
+		})
+	}
+}
+
+func TestNewQueryAndUpdateMigration_ErrorPolicy_FailedWrite(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+	archive := "archive"
+
+	user1 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}
+	user2 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{
+			PutOperation(&dynamodb.PutItemInput{TableName: &archive, Item: item}),
+			DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: item}),
+		}, nil
+	}
+
+	var failedItems []map[string]types.AttributeValue
+
+	m, err := NewQueryAndUpdateMigration("query_migration", "Query migration", newTestQueryBuilder(table), writeFn,
+		QueryAndUpdateMigrationWithErrorPolicy(ErrorPolicySkip, func(item map[string]types.AttributeValue, err error) {
+			failedItems = append(failedItems, item)
+		}))
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Query(mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{user1, user2}}, nil).Once()
+
+	// The item is not deleted if it could not be archived
+	client.EXPECT().PutItem(mock.Anything, &dynamodb.PutItemInput{TableName: &archive, Item: user1}).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+	client.EXPECT().PutItem(mock.Anything, &dynamodb.PutItemInput{TableName: &archive, Item: user2}).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.EXPECT().DeleteItem(mock.Anything, &dynamodb.DeleteItemInput{TableName: &table, Key: user2}).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []map[string]types.AttributeValue{user1}, failedItems)
+}
+
+func TestMigrator_Execute_QueryMigrationCollectedErrorsKeepCheckpoint(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "table_to_migrate"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectMigrationRecord(client, migrationTable, "CHECKPOINT#1", nil)
+
+	client.EXPECT().Query(mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+		Items:            []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}},
+		LastEvaluatedKey: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+	}, nil).Once()
+
+	client.EXPECT().Query(mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}},
+	}, nil).Once()
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	migration, err := NewQueryAndUpdateMigration("query", "query migration", newTestQueryBuilder(table), func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		if item["PK"].(*types.AttributeValueMemberS).Value == "USER#1" {
+			return nil, errors.New("invalid item")
+		}
+
+		return []*WriteOperation{UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item})}, nil
+	}, QueryAndUpdateMigrationWithErrorPolicy(ErrorPolicyCollect, nil), QueryAndUpdateMigrationWithCheckpointInterval(time.Nanosecond))
+	require.NoError(t, err)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	var itemErrors *ItemErrors
+
+	require.ErrorAs(t, err, &itemErrors)
+	require.EqualError(t, err, "running migration query: 1 items failed, first error: invalid item")
+	client.AssertNotCalled(t, "PutItem", mock.Anything, mock.Anything)
+}
+
+func TestNewQueryAndUpdateMigration_UnknownErrorPolicy(t *testing.T) {
+	// When
+	_, err := NewQueryAndUpdateMigration("query_migration", "Query migration", newTestQueryBuilder("table"), func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return nil, nil
+	}, QueryAndUpdateMigrationWithErrorPolicy("retry", nil))
+
+	// Then
+	require.Error(t, err)
+}
+
+func TestNewQueryAndUpdateMigration_InvalidQuery(t *testing.T) {
+	// Given
+	queryBuilder := inputbuilder.NewQueryBuilder()
+	queryBuilder.WithTableName("table")
+
+	// When
+	_, err := NewQueryAndUpdateMigration("query_migration", "Query migration", &queryBuilder, func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return nil, nil
+	})
+
+	// Then
+	require.Error(t, err)
+}
+
+func TestWriteOperation_Empty(t *testing.T) {
+	// When
+	err := (&WriteOperation{}).execute(context.Background(), mocks.NewDynamodbClient(t))
+
+	// Then
+	require.Error(t, err)
+}
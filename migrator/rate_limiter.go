@@ -0,0 +1,59 @@
+package migrator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spreads requests evenly over time, so at most a fixed number of units is consumed per second.
+// A nil rateLimiter does not limit.
+type rateLimiter struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time
+}
+
+// newRateLimiter creates a rateLimiter that allows unitsPerSecond units per second. Nil is returned if unitsPerSecond is zero.
+func newRateLimiter(unitsPerSecond float64) *rateLimiter {
+	if unitsPerSecond <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / unitsPerSecond)}
+}
+
+// wait blocks until the given number of units may be consumed or the context is Done
+func (l *rateLimiter) wait(ctx context.Context, units int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	start := l.next
+	l.next = l.next.Add(l.interval * time.Duration(units))
+
+	l.mutex.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
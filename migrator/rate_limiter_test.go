@@ -0,0 +1,52 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	// Given
+	limiter := newRateLimiter(100)
+
+	start := time.Now()
+
+	// When
+	for i := 0; i < 5; i++ {
+		require.NoError(t, limiter.wait(context.Background(), 2))
+	}
+
+	// Then
+	require.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+}
+
+func TestRateLimiter_Wait_Unlimited(t *testing.T) {
+	// Given
+	limiter := newRateLimiter(0)
+
+	// When
+	err := limiter.wait(context.Background(), 1000)
+
+	// Then
+	require.Nil(t, limiter)
+	require.NoError(t, err)
+}
+
+func TestRateLimiter_Wait_ContextDone(t *testing.T) {
+	// Given
+	limiter := newRateLimiter(1)
+
+	require.NoError(t, limiter.wait(context.Background(), 10))
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelFn()
+
+	// When
+	err := limiter.wait(ctx, 1)
+
+	// Then
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
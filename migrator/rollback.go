@@ -0,0 +1,153 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrNoRollback is returned by RollbackTo if a migration that should be rolled back has no RollbackFn
+var ErrNoRollback = errors.New("migration has no rollback")
+
+// RollbackTo rolls back all executed migrations with an ID larger than targetId, in reverse order.
+// After each rollback, lastJobId is decreased and the migration record and checkpoint are removed in a single transaction.
+// The recorded attempts are kept as an audit trail; attempts of a re-execution continue the numbering.
+// If any migration in the range has no RollbackFn, nothing is rolled back and ErrNoRollback is returned. Skipped migrations are not rolled back, only their record is removed.
+// If a lock is configured, the rollback is executed while the lock is held. In LockModeSkip, ErrMigrationLocked is returned if another instance holds the lock.
+func (m *Migrator) RollbackTo(ctx context.Context, client DynamodbClient, targetId uint64) error {
+	return m.withLock(ctx, client, func(ctx context.Context, client DynamodbClient) error {
+		return m.rollbackTo(ctx, client, targetId)
+	})
+}
+
+func (m *Migrator) rollbackTo(ctx context.Context, client DynamodbClient, targetId uint64) error {
+	metadata, err := m.getMetadataObject(ctx, client)
+	if err != nil {
+		return fmt.Errorf("loading metadata: %w", err)
+	}
+
+	err = m.verifyHistory(&metadata)
+	if err != nil {
+		return err
+	}
+
+	if targetId >= metadata.LastJobId {
+		return nil
+	}
+
+	if targetId < m.MigrationIdOffset {
+		return fmt.Errorf("unable to roll back to %d: migrations up to %d are not defined", targetId, m.MigrationIdOffset)
+	}
+
+	for id := metadata.LastJobId; id > targetId; id-- {
+		i := id - m.MigrationIdOffset - 1
+		if i >= uint64(len(m.Migrations)) {
+			return fmt.Errorf("unable to roll back to %d: migration %d is not defined", targetId, id)
+		}
+
+		if m.Migrations[i].RollbackFn == nil && !metadata.isSkipped(id) {
+			return fmt.Errorf("unable to roll back migration %d (%s): %w", id, m.Migrations[i].Name, ErrNoRollback)
+		}
+	}
+
+	for id := metadata.LastJobId; id > targetId; id-- {
+		migration := &m.Migrations[id-m.MigrationIdOffset-1]
+
+		if !metadata.isSkipped(id) {
+			err = migration.RollbackFn(ctx, client)
+			if err != nil {
+				return fmt.Errorf("rolling back migration %s: %w", migration.Name, err)
+			}
+		}
+
+		err = m.annotateRollback(ctx, client, &metadata, id, migration)
+		if err != nil {
+			return fmt.Errorf("updating migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) annotateRollback(ctx context.Context, client DynamodbClient, metadata *metadataObject, id uint64, migration *Migration) error {
+	metadataUpdate := &types.Update{
+		TableName: &m.MigrationTableName,
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: metadataPK},
+		},
+		UpdateExpression:    aws.String("SET #lastJobId = :previousJobId"),
+		ConditionExpression: aws.String("#lastJobId = :lastJobId"),
+		ExpressionAttributeNames: map[string]string{
+			"#lastJobId": "lastJobId",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lastJobId":     &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id)},
+			":previousJobId": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id-1)},
+		},
+	}
+
+	var removals []string
+
+	_, keyRecorded := metadata.MigrationKeys[migration.Key]
+	if migration.Key != "" && keyRecorded {
+		removals = append(removals, "#migrationKeys.#migrationKey")
+		metadataUpdate.ExpressionAttributeNames["#migrationKeys"] = "migrationKeys"
+		metadataUpdate.ExpressionAttributeNames["#migrationKey"] = migration.Key
+	}
+
+	skipped := metadata.isSkipped(id)
+	if skipped {
+		removals = append(removals, "#skippedMigrations.#skippedId")
+		metadataUpdate.ExpressionAttributeNames["#skippedMigrations"] = "skippedMigrations"
+		metadataUpdate.ExpressionAttributeNames["#skippedId"] = strconv.FormatUint(id, 10)
+	}
+
+	if len(removals) > 0 {
+		metadataUpdate.UpdateExpression = aws.String("SET #lastJobId = :previousJobId REMOVE " + strings.Join(removals, ", "))
+	}
+
+	// The checkpoint is removed as well, so the migration starts from scratch when it is executed again. The attempts are kept for auditing.
+	removedItems := []string{fmt.Sprintf("MIGRATION#%d", id), fmt.Sprintf("%s%d", checkpointPKPrefix, id)}
+
+	transaction := dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: metadataUpdate,
+			},
+		},
+	}
+
+	for _, pk := range removedItems {
+		transaction.TransactItems = append(transaction.TransactItems, types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName: &m.MigrationTableName,
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: pk},
+				},
+			},
+		})
+	}
+
+	_, err := client.TransactWriteItems(ctx, &transaction)
+	if err != nil {
+		return err
+	}
+
+	metadata.LastJobId = id - 1
+
+	if keyRecorded {
+		delete(metadata.MigrationKeys, migration.Key)
+	}
+
+	if skipped {
+		delete(metadata.SkippedMigrations, strconv.FormatUint(id, 10))
+	}
+
+	return nil
+}
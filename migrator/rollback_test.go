@@ -0,0 +1,177 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func TestMigrator_RollbackTo(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "13"},
+	}}, nil).Once()
+
+	storeItems := make([]*dynamodb.TransactWriteItemsInput, 0, 2)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Twice()
+
+	var rolledBack []string
+
+	rollbackFn := func(name string) func(ctx context.Context, client DynamodbClient) error {
+		return func(ctx context.Context, client DynamodbClient) error {
+			rolledBack = append(rolledBack, name)
+
+			return nil
+		}
+	}
+
+	migrator := NewMigrator(migrationTable, 10,
+		Migration{Name: "migration_11"},
+		Migration{Name: "migration_12", RollbackFn: rollbackFn("migration_12")},
+		Migration{Name: "migration_13", RollbackFn: rollbackFn("migration_13")},
+	)
+
+	// When
+	err := migrator.RollbackTo(context.Background(), client, 11)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []string{"migration_13", "migration_12"}, rolledBack)
+	require.Len(t, storeItems, 2)
+
+	require.Equal(t, []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:                &migrationTable,
+				Key:                      map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: metadataPK}},
+				UpdateExpression:         aws.String("SET #lastJobId = :previousJobId"),
+				ConditionExpression:      aws.String("#lastJobId = :lastJobId"),
+				ExpressionAttributeNames: map[string]string{"#lastJobId": "lastJobId"},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":lastJobId":     &types.AttributeValueMemberN{Value: "13"},
+					":previousJobId": &types.AttributeValueMemberN{Value: "12"},
+				},
+			},
+		},
+		{
+			Delete: &types.Delete{
+				TableName: &migrationTable,
+				Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "MIGRATION#13"}},
+			},
+		},
+		{
+			Delete: &types.Delete{
+				TableName: &migrationTable,
+				Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "CHECKPOINT#13"}},
+			},
+		},
+	}, storeItems[0].TransactItems)
+
+	require.Len(t, storeItems[1].TransactItems, 3)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "MIGRATION#12"}, storeItems[1].TransactItems[1].Delete.Key["PK"])
+}
+
+func TestMigrator_RollbackTo_NoRollback(t *testing.T) {
+	// Given
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "2"},
+	}}, nil).Once()
+
+	rolledBack := false
+
+	migrator := NewMigrator("migration_table", 0,
+		Migration{Name: "migration_1"},
+		Migration{Name: "migration_2", RollbackFn: func(ctx context.Context, client DynamodbClient) error {
+			rolledBack = true
+
+			return nil
+		}},
+	)
+
+	// When
+	err := migrator.RollbackTo(context.Background(), client, 0)
+
+	// Then
+	require.ErrorIs(t, err, ErrNoRollback)
+	require.False(t, rolledBack)
+}
+
+func TestMigrator_RollbackTo_NothingToRollBack(t *testing.T) {
+	// Given
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+	}}, nil).Once()
+
+	migrator := NewMigrator("migration_table", 0, Migration{Name: "migration_1"})
+
+	// When
+	err := migrator.RollbackTo(context.Background(), client, 1)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestMigrator_RollbackTo_WithLock_Skip(t *testing.T) {
+	// Given
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().PutItem(mock.Anything, mock.MatchedBy(isLockPut)).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	migrator := NewMigrator("migration_table", 0, Migration{Name: "migration_1"}).WithLock(LockModeSkip)
+
+	// When
+	err := migrator.RollbackTo(context.Background(), client, 0)
+
+	// Then
+	require.ErrorIs(t, err, ErrMigrationLocked)
+}
+
+func TestMigrator_RollbackTo_RemovesKey(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "2"},
+		"migrationKeys": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"create-users": &types.AttributeValueMemberN{Value: "1"},
+			"add-email":    &types.AttributeValueMemberN{Value: "2"},
+		}},
+	}}, nil).Once()
+
+	storeItems := make([]*dynamodb.TransactWriteItemsInput, 0, 1)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migrator := NewMigrator(migrationTable, 0,
+		Migration{Key: "create-users", Name: "migration_1"},
+		Migration{Key: "add-email", Name: "migration_2", RollbackFn: func(ctx context.Context, client DynamodbClient) error { return nil }},
+	)
+
+	// When
+	err := migrator.RollbackTo(context.Background(), client, 1)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, storeItems, 1)
+
+	update := storeItems[0].TransactItems[0].Update
+	require.Equal(t, "SET #lastJobId = :previousJobId REMOVE #migrationKeys.#migrationKey", *update.UpdateExpression)
+	require.Equal(t, map[string]string{"#lastJobId": "lastJobId", "#migrationKeys": "migrationKeys", "#migrationKey": "add-email"}, update.ExpressionAttributeNames)
+}
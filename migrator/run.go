@@ -0,0 +1,107 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type migrationRunContextKey struct{}
+
+// migrationRun identifies the migration that is being executed by the Migrator.
+// It is passed to the migration function in the context, so migrations can store progress in the migration metadata table.
+type migrationRun struct {
+	client    DynamodbClient
+	tableName string
+	id        uint64
+
+	mutex        sync.Mutex
+	checkpointed bool
+	metadata     map[string]interface{}
+
+	// reportsProgress is true if the progress of the migration is reported, so templates should estimate the total number of items
+	reportsProgress bool
+
+	itemsProcessed atomic.Int64
+	itemsUpdated   atomic.Int64
+	itemsSkipped   atomic.Int64
+	itemErrors     atomic.Int64
+	totalItems     atomic.Int64
+}
+
+func withMigrationRun(ctx context.Context, run *migrationRun) context.Context {
+	return context.WithValue(ctx, migrationRunContextKey{}, run)
+}
+
+func migrationRunFromContext(ctx context.Context) *migrationRun {
+	run, _ := ctx.Value(migrationRunContextKey{}).(*migrationRun)
+
+	return run
+}
+
+// AddItemsProcessed adds n to the number of items processed by the migration that is executed by the Migrator.
+// The number of processed items is recorded in the attempt history. Nothing happens if the migration is not executed by a Migrator.
+// It is equivalent to ProgressReporterFromContext(ctx).AddScanned(n).
+func AddItemsProcessed(ctx context.Context, n int64) {
+	ProgressReporterFromContext(ctx).AddScanned(n)
+}
+
+// setRunMetadata records metadata of the migration that is executed by the Migrator, e.g. the outcome of the migration.
+// The metadata is stored in the migration record once the migration succeeded. Nothing happens if the migration is not executed by a Migrator.
+func setRunMetadata(ctx context.Context, key string, value interface{}) {
+	run := migrationRunFromContext(ctx)
+	if run == nil {
+		return
+	}
+
+	run.mutex.Lock()
+	defer run.mutex.Unlock()
+
+	if run.metadata == nil {
+		run.metadata = make(map[string]interface{})
+	}
+
+	run.metadata[key] = value
+}
+
+// recordedMetadata returns the metadata recorded during the run
+func (r *migrationRun) recordedMetadata() map[string]interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.metadata
+}
+
+// processedItems returns the number of items processed so far
+func (r *migrationRun) processedItems() int64 {
+	return r.itemsProcessed.Load()
+}
+
+// failedItems returns the number of items for which an error was skipped or collected so far
+func (r *migrationRun) failedItems() int64 {
+	return r.itemErrors.Load()
+}
+
+func (r *migrationRun) checkpointPK() string {
+	return fmt.Sprintf("%s%d", checkpointPKPrefix, r.id)
+}
+
+// hasCheckpoint returns true if a checkpoint was loaded or stored during the run, so it should be cleared once the migration succeeded
+func (r *migrationRun) hasCheckpoint() bool {
+	if r == nil {
+		return false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.checkpointed
+}
+
+func (r *migrationRun) markCheckpointed() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.checkpointed = true
+}
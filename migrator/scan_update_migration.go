@@ -2,7 +2,11 @@ package migrator
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
@@ -11,23 +15,92 @@ import (
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
 )
 
+const defaultCheckpointInterval = 30 * time.Second
+const maxTransactionBatchSize = 100
+
 type ScanAndUpdateMigrationOptions struct {
-	FilterExpression conditionexpression.ExpressionItem
-	ConsistentRead   *bool
-	Metadata         map[string]interface{}
+	FilterExpression   conditionexpression.ExpressionItem
+	ConsistentRead     *bool
+	Metadata           map[string]interface{}
+	CheckpointInterval *time.Duration
+
+	// TotalSegments number of segments that are scanned in parallel
+	TotalSegments int32
+
+	// Workers maximum number of concurrent write requests
+	Workers int
+
+	// BatchSize maximum number of updates that are combined in a single TransactWriteItems request, or BatchWriteItem requests if BatchWrite is set
+	BatchSize int
+
+	// BatchWrite combines the write operations of a batch in BatchWriteItem requests instead of a TransactWriteItems request.
+	// Only puts and deletes without condition expression are supported.
+	BatchWrite bool
+
+	// PageSize maximum number of items evaluated per scan request
+	PageSize *int32
+
+	// WritesPerSecond maximum number of write capacity units per second, assuming items of at most 1 KB. A write in a transaction consumes 2 units. Zero means unlimited
+	WritesPerSecond float64
+
+	// ErrorPolicy determines how an error returned by the writeFn for a single item, or a failed write of a single item, is handled. Default value is ErrorPolicyAbort.
+	// A failed batch of multiple writes always aborts the migration
+	ErrorPolicy ErrorPolicy
+
+	// OnItemError is called for every failed item if the ErrorPolicy does not abort the migration
+	OnItemError func(item map[string]types.AttributeValue, err error)
 }
 
 type OptionFn func(*ScanAndUpdateMigrationOptions)
 
 // NewScanAndUpdateMigration create a migration that will execute a scan. For each item in the table an updateFn will be executed.
 // If the updateFn return a non nil *dynamodb.UpdateItemInput, the update will be executed.
+// If executed by a Migrator, the progress of the scan is stored in the migration metadata table at regular intervals. A failed migration resumes from the last checkpoint.
+// By default, a single segment is scanned and one update is executed at a time. Use the options to scan segments in parallel, execute updates concurrently, batch updates in transactions and throttle the writes.
+// If multiple segments are scanned in parallel, the updateFn is called concurrently.
+// Use NewScanAndWriteMigration to return builders, puts, deletes, multiple operations or errors for an item.
 func NewScanAndUpdateMigration(name string, description string, table string, updateFn func(ctx context.Context, item map[string]types.AttributeValue) *dynamodb.UpdateItemInput, optFn ...OptionFn) (*Migration, error) {
-	options := ScanAndUpdateMigrationOptions{}
+	return NewScanAndWriteMigration(name, description, table, func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		update := updateFn(ctx, item)
+		if update == nil {
+			return nil, nil
+		}
+
+		return []*WriteOperation{UpdateItemOperation(update)}, nil
+	}, optFn...)
+}
+
+// NewScanAndWriteMigration create a migration that will execute a scan. For each item in the table the writeFn will be executed.
+// All write operations returned by the writeFn will be executed. If the writeFn returns an error, the ErrorPolicy determines whether the migration fails.
+// It supports the same options and checkpoints as NewScanAndUpdateMigration. If updates are batched, every write operation counts as one update.
+func NewScanAndWriteMigration(name string, description string, table string, writeFn func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error), optFn ...OptionFn) (*Migration, error) {
+	options := ScanAndUpdateMigrationOptions{
+		TotalSegments: 1,
+		Workers:       1,
+		BatchSize:     1,
+		ErrorPolicy:   ErrorPolicyAbort,
+	}
 
 	for _, opt := range optFn {
 		opt(&options)
 	}
 
+	switch {
+	case options.TotalSegments < 1:
+		return nil, fmt.Errorf("total segments should be at least 1, got %d", options.TotalSegments)
+	case options.Workers < 1:
+		return nil, fmt.Errorf("workers should be at least 1, got %d", options.Workers)
+	case options.BatchSize < 1 || options.BatchSize > maxTransactionBatchSize:
+		return nil, fmt.Errorf("batch size should be between 1 and %d, got %d", maxTransactionBatchSize, options.BatchSize)
+	case options.WritesPerSecond < 0:
+		return nil, fmt.Errorf("writes per second should not be negative, got %f", options.WritesPerSecond)
+	}
+
+	err := validErrorPolicy(options.ErrorPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	scanBuilder := inputbuilder.NewScanBuilder()
 	scanBuilder.WithTableName(table)
 
@@ -41,45 +114,39 @@ func NewScanAndUpdateMigration(name string, description string, table string, up
 		scanBuilder.WithFilterExpression(options.FilterExpression)
 	}
 
+	if options.PageSize != nil {
+		scanBuilder.WithLimit(*options.PageSize)
+	}
+
 	scanInput := &dynamodb.ScanInput{}
 
-	err := scanBuilder.Build(scanInput)
+	err = scanBuilder.Build(scanInput)
 	if err != nil {
 		return nil, err
 	}
 
 	metadata := map[string]interface{}{"table": table}
 
+	if options.ErrorPolicy != ErrorPolicyAbort {
+		metadata["errorPolicy"] = string(options.ErrorPolicy)
+	}
+
 	if options.Metadata != nil {
 		for key, value := range options.Metadata {
 			metadata[key] = value
 		}
 	}
 
+	scan := &scanAndUpdate{
+		scanInput: scanInput,
+		writeFn:   writeFn,
+		options:   options,
+	}
+
 	return &Migration{
 		Name:        name,
 		Description: description,
-		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
-			exec := executor.New(client)
-			items := exec.Scan(ctx, scanInput)
-
-			for item := range items {
-				switch v := item.(type) {
-				case error:
-					return v
-				case map[string]types.AttributeValue:
-					update := updateFn(ctx, v)
-					if update != nil {
-						_, err := client.UpdateItem(ctx, update)
-						if err != nil {
-							return err
-						}
-					}
-				}
-			}
-
-			return nil
-		},
+		MigratorFn:  scan.migrate,
 		JobMetadata: metadata,
 	}, nil
 }
@@ -104,3 +171,467 @@ func ScanAndUpdateMigrationWithMetadata(metadata map[string]interface{}) OptionF
 		options.Metadata = metadata
 	}
 }
+
+// ScanAndUpdateMigrationWithCheckpointInterval set the minimal time between two stored checkpoints of the scan progress on ScanAndUpdateMigration.
+// Default value is 30 seconds. A zero interval disables checkpoints.
+func ScanAndUpdateMigrationWithCheckpointInterval(interval time.Duration) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.CheckpointInterval = &interval
+	}
+}
+
+// ScanAndUpdateMigrationWithParallelScan scan the table in totalSegments segments in parallel on ScanAndUpdateMigration. Default value is 1.
+func ScanAndUpdateMigrationWithParallelScan(totalSegments int32) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.TotalSegments = totalSegments
+	}
+}
+
+// ScanAndUpdateMigrationWithWorkers set the maximum number of concurrent write requests on ScanAndUpdateMigration. Default value is 1.
+// The workers are shared by all segments.
+func ScanAndUpdateMigrationWithWorkers(workers int) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.Workers = workers
+	}
+}
+
+// ScanAndUpdateMigrationWithBatchSize combine up to batchSize updates of a scanned page in a single TransactWriteItems request on ScanAndUpdateMigration.
+// Default value is 1, which executes every update with UpdateItem. The maximum value is 100.
+// Note that a transactional write consumes twice the write capacity of a regular write.
+// A batch contains at most one operation per item, so a new batch is started if an item is written again. Puts are identified by the key of their item if the client is a SchemaClient, otherwise by the complete item.
+func ScanAndUpdateMigrationWithBatchSize(batchSize int) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.BatchSize = batchSize
+	}
+}
+
+// ScanAndUpdateMigrationWithBatchWrite combine the write operations of a batch in BatchWriteItem requests of at most 25 items instead of a TransactWriteItems request on NewScanAndWriteMigration.
+// Only puts and deletes without condition expression are supported, so updates still require a transaction. Unprocessed items are retried with an exponential backoff.
+// Unlike a transaction, a batch is not atomic and consumes the write capacity of regular writes. Use it for idempotent writes only: a partially written batch is written again when the migration resumes.
+// Use ScanAndUpdateMigrationWithBatchSize to set the number of operations in a batch.
+func ScanAndUpdateMigrationWithBatchWrite() OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.BatchWrite = true
+	}
+}
+
+// ScanAndUpdateMigrationWithPageSize set the maximum number of items evaluated per scan request on ScanAndUpdateMigration
+func ScanAndUpdateMigrationWithPageSize(pageSize int32) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.PageSize = &pageSize
+	}
+}
+
+// ScanAndUpdateMigrationWithWritesPerSecond throttle the updates of ScanAndUpdateMigration to at most writesPerSecond write capacity units per second over all segments and workers.
+// Every write is assumed to consume a single unit, or 2 units if it is part of a transaction, so the limit is exact for items of at most 1 KB.
+// As scanning a segment waits until the updates of the previous page are executed, the scan is throttled as well. Default value is unlimited.
+func ScanAndUpdateMigrationWithWritesPerSecond(writesPerSecond float64) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.WritesPerSecond = writesPerSecond
+	}
+}
+
+// ScanAndUpdateMigrationWithErrorPolicy set how errors returned by the writeFn of NewScanAndWriteMigration for a single item are handled. Default value is ErrorPolicyAbort.
+// The policy applies to failed writes as well, e.g. a failed condition, if the writes are not batched. A failed batch of multiple writes always aborts the migration, as it is not known which item caused the failure.
+// The optional onItemError is called for every failed item if the policy does not abort the migration, e.g. to log the item.
+// The policy is recorded in the job metadata and the number of failed items in the migration metadata table.
+func ScanAndUpdateMigrationWithErrorPolicy(policy ErrorPolicy, onItemError func(item map[string]types.AttributeValue, err error)) OptionFn {
+	return func(options *ScanAndUpdateMigrationOptions) {
+		options.ErrorPolicy = policy
+		options.OnItemError = onItemError
+	}
+}
+
+// scanAndUpdate executes a ScanAndUpdateMigration
+type scanAndUpdate struct {
+	scanInput *dynamodb.ScanInput
+	writeFn   func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error)
+	options   ScanAndUpdateMigrationOptions
+}
+
+// scanAndUpdateRun is the state of a single execution of a ScanAndUpdateMigration
+type scanAndUpdateRun struct {
+	*scanAndUpdate
+
+	client     DynamodbClient
+	checkpoint *scanCheckpoint
+	limiter    *rateLimiter
+	workers    chan struct{}
+	itemErrors *itemErrorCollector
+	keySchemas *keySchemas
+}
+
+func (s *scanAndUpdate) migrate(ctx context.Context, client DynamodbClient) error {
+	checkpointInterval := defaultCheckpointInterval
+	if s.options.CheckpointInterval != nil {
+		checkpointInterval = *s.options.CheckpointInterval
+	}
+
+	checkpoint, err := loadScanCheckpoint(ctx, int(s.options.TotalSegments), checkpointInterval)
+	if err != nil {
+		return err
+	}
+
+	estimateTotalItems(ctx, client, aws.ToString(s.scanInput.TableName), checkpoint.totalCount())
+
+	run := &scanAndUpdateRun{
+		scanAndUpdate: s,
+		client:        client,
+		checkpoint:    checkpoint,
+		limiter:       newRateLimiter(s.options.WritesPerSecond),
+		workers:       make(chan struct{}, s.options.Workers),
+		itemErrors:    &itemErrorCollector{},
+		keySchemas:    newKeySchemas(client),
+	}
+
+	if s.options.TotalSegments == 1 {
+		err = run.scanSegment(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		return run.itemErrors.err()
+	}
+
+	segmentCtx, cancelFn := context.WithCancelCause(ctx)
+	defer cancelFn(nil)
+
+	var wg sync.WaitGroup
+
+	for segment := int32(0); segment < s.options.TotalSegments; segment++ {
+		wg.Add(1)
+
+		go func(segment int32) {
+			defer wg.Done()
+
+			segmentErr := run.scanSegment(segmentCtx, segment)
+			if segmentErr != nil {
+				cancelFn(segmentErr)
+			}
+		}(segment)
+	}
+
+	wg.Wait()
+
+	err = context.Cause(segmentCtx)
+	if err != nil {
+		return err
+	}
+
+	return run.itemErrors.err()
+}
+
+// scanSegment scans a single segment and executes the updates page by page. The checkpoint is updated once all updates of a page are executed.
+func (r *scanAndUpdateRun) scanSegment(ctx context.Context, segment int32) error {
+	startKey, done := r.checkpoint.startKey(int(segment))
+	if done {
+		return nil
+	}
+
+	input := *r.scanInput
+	input.ExclusiveStartKey = startKey
+
+	if r.options.TotalSegments > 1 {
+		input.Segment = aws.Int32(segment)
+		input.TotalSegments = aws.Int32(r.options.TotalSegments)
+	}
+
+	var page []map[string]types.AttributeValue
+
+	// Once errors of items are collected, the checkpoint of the segment is no longer advanced, so the failed items are processed again in the next execution
+	segmentFailed := false
+
+	exec := executor.New(r.client)
+	items := exec.Scan(ctx, &input, executor.WithPageEndMarkers())
+
+	for item := range items {
+		switch v := item.(type) {
+		case error:
+			return v
+		case executor.PageEnd:
+			pageFailed, err := r.processPage(ctx, page)
+			if err != nil {
+				return err
+			}
+
+			AddItemsProcessed(ctx, int64(len(page)))
+
+			segmentFailed = segmentFailed || pageFailed
+
+			if !segmentFailed {
+				err = r.checkpoint.pageEnd(ctx, int(segment), v.LastEvaluatedKey, int64(len(page)))
+				if err != nil {
+					return err
+				}
+			}
+
+			page = page[:0]
+		case map[string]types.AttributeValue:
+			page = append(page, v)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if segmentFailed {
+		return nil
+	}
+
+	return r.checkpoint.segmentDone(ctx, int(segment))
+}
+
+// processPage executes the write operations of the items of a page in batches and returns once all operations are executed.
+// Returns true if errors of items were collected according to the ErrorPolicy.
+func (r *scanAndUpdateRun) processPage(ctx context.Context, items []map[string]types.AttributeValue) (bool, error) {
+	var batches []writeBatch
+
+	var batch writeBatch
+
+	batchKeys := make(map[string]struct{})
+
+	pageFailed := false
+
+	var updated, skipped int64
+
+	for i, item := range items {
+		operations, err := r.writeFn(ctx, item)
+		if err != nil {
+			err = r.handleItemError(ctx, item, err)
+			if err != nil {
+				return false, err
+			}
+
+			pageFailed = pageFailed || r.options.ErrorPolicy == ErrorPolicyCollect
+
+			continue
+		}
+
+		itemUpdated := false
+
+		for _, operation := range operations {
+			if operation == nil {
+				continue
+			}
+
+			itemUpdated = true
+
+			// A batch can only contain a single operation per item, so a new batch is started if the item is already written by the batch
+			if r.options.BatchSize > 1 {
+				itemKey, keyErr := r.keySchemas.itemKey(ctx, operation)
+				if keyErr != nil {
+					return false, keyErr
+				}
+
+				if _, found := batchKeys[itemKey]; found {
+					batches = append(batches, batch)
+					batch = writeBatch{}
+					batchKeys = make(map[string]struct{})
+				}
+
+				batchKeys[itemKey] = struct{}{}
+			}
+
+			batch.operations = append(batch.operations, operation)
+			batch.items = append(batch.items, i)
+
+			if len(batch.operations) == r.options.BatchSize {
+				batches = append(batches, batch)
+				batch = writeBatch{}
+				batchKeys = make(map[string]struct{})
+			}
+		}
+
+		if itemUpdated {
+			updated++
+		} else {
+			skipped++
+		}
+	}
+
+	if len(batch.operations) > 0 {
+		batches = append(batches, batch)
+	}
+
+	failures := &writeFailures{items: items, failed: make(map[int]struct{})}
+
+	err := r.writeBatches(ctx, failures, batches)
+	if err != nil {
+		return false, err
+	}
+
+	failedItems := failures.count()
+	pageFailed = pageFailed || (failedItems > 0 && r.options.ErrorPolicy == ErrorPolicyCollect)
+
+	progress := ProgressReporterFromContext(ctx)
+	progress.AddUpdated(updated - failedItems)
+	progress.AddSkipped(skipped)
+
+	return pageFailed, nil
+}
+
+// writeBatch is a batch of write operations together with the index of the scanned item of every operation
+type writeBatch struct {
+	operations []*WriteOperation
+	items      []int
+}
+
+// writeFailures tracks the scanned items of a page whose writes failed. It is safe for concurrent use.
+type writeFailures struct {
+	items []map[string]types.AttributeValue
+
+	mutex  sync.Mutex
+	failed map[int]struct{}
+}
+
+// add marks the item as failed. Returns false if the item already failed
+func (f *writeFailures) add(item int) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, found := f.failed[item]; found {
+		return false
+	}
+
+	f.failed[item] = struct{}{}
+
+	return true
+}
+
+func (f *writeFailures) count() int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return int64(len(f.failed))
+}
+
+// writeBatches executes the batches. Batches are executed concurrently by the workers if multiple workers or segments are configured.
+func (r *scanAndUpdateRun) writeBatches(ctx context.Context, failures *writeFailures, batches []writeBatch) error {
+	if r.options.Workers == 1 && r.options.TotalSegments == 1 {
+		for _, b := range batches {
+			err := r.writeBatch(ctx, failures, b)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	writeCtx, cancelFn := context.WithCancelCause(ctx)
+	defer cancelFn(nil)
+
+	var wg sync.WaitGroup
+
+	for _, b := range batches {
+		select {
+		case <-writeCtx.Done():
+		case r.workers <- struct{}{}:
+			wg.Add(1)
+
+			go func(b writeBatch) {
+				defer func() {
+					<-r.workers
+					wg.Done()
+				}()
+
+				err := r.writeBatch(writeCtx, failures, b)
+				if err != nil {
+					cancelFn(err)
+				}
+			}(b)
+		}
+	}
+
+	wg.Wait()
+
+	return context.Cause(writeCtx)
+}
+
+// writeBatch executes a batch of write operations. If the batch has a single operation, a failed write belongs to a single item and is handled according to the ErrorPolicy.
+// A failed batch of multiple operations always aborts the migration, as it is not known which item caused the failure.
+func (r *scanAndUpdateRun) writeBatch(ctx context.Context, failures *writeFailures, b writeBatch) error {
+	err := r.write(ctx, b.operations)
+	if err == nil || len(b.operations) > 1 || ctx.Err() != nil {
+		return err
+	}
+
+	// Only the first failed write of an item is handled, so every item is counted once
+	if !failures.add(b.items[0]) {
+		return nil
+	}
+
+	return r.handleItemError(ctx, failures.items[b.items[0]], err)
+}
+
+// write executes a batch of write operations. A single operation is executed on its own, multiple operations in a single transaction or with BatchWriteItem if BatchWrite is set.
+func (r *scanAndUpdateRun) write(ctx context.Context, batch []*WriteOperation) error {
+	if len(batch) == 1 {
+		err := r.limiter.wait(ctx, 1)
+		if err != nil {
+			return err
+		}
+
+		return batch[0].execute(ctx, r.client)
+	}
+
+	if r.options.BatchWrite {
+		return r.batchWrite(ctx, batch)
+	}
+
+	// A transactional write consumes twice the write capacity of a regular write
+	err := r.limiter.wait(ctx, 2*len(batch))
+	if err != nil {
+		return err
+	}
+
+	transactItems := make([]types.TransactWriteItem, 0, len(batch))
+
+	for _, operation := range batch {
+		transactItem, transactErr := operation.transactItem()
+		if transactErr != nil {
+			return transactErr
+		}
+
+		transactItems = append(transactItems, transactItem)
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: transactItems})
+
+	return err
+}
+
+// batchWrite executes a batch of puts and deletes with BatchWriteItem. The requests are grouped per table, keeping the order of the operations of a table.
+func (r *scanAndUpdateRun) batchWrite(ctx context.Context, batch []*WriteOperation) error {
+	var tables []string
+
+	requests := make(map[string][]types.WriteRequest)
+
+	for _, operation := range batch {
+		table, request, err := operation.writeRequest()
+		if err != nil {
+			return err
+		}
+
+		if _, found := requests[table]; !found {
+			tables = append(tables, table)
+		}
+
+		requests[table] = append(requests[table], request)
+	}
+
+	for _, table := range tables {
+		err := batchWrite(ctx, r.client, r.limiter, table, requests[table], r.keySchemas.tableKeyAttributes(ctx, table))
+		if err != nil {
+			return fmt.Errorf("writing to %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// handleItemError handles an error of a single item according to the ErrorPolicy. Returns an error if the migration should be aborted.
+func (r *scanAndUpdateRun) handleItemError(ctx context.Context, item map[string]types.AttributeValue, err error) error {
+	return handleItemError(ctx, r.options.ErrorPolicy, r.options.OnItemError, r.itemErrors, item, err)
+}
@@ -2,7 +2,10 @@ package migrator
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -10,7 +13,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/raito-io/go-dynamo-utils/inputbuilder"
 	"github.com/raito-io/go-dynamo-utils/inputbuilder/conditionexpression"
+	"github.com/raito-io/go-dynamo-utils/inputbuilder/updateexpression"
 	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
 )
 
@@ -120,3 +125,554 @@ func TestNewScanAndUpdateMigration_WithOptions(t *testing.T) {
 	err = m.MigratorFn(context.Background(), client)
 	require.NoError(t, err)
 }
+
+func TestNewScanAndUpdateMigration_ParallelBatchedMigration(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	updateFn := func(ctx context.Context, item map[string]types.AttributeValue) *dynamodb.UpdateItemInput {
+		return &dynamodb.UpdateItemInput{
+			TableName:        aws.String(table),
+			Key:              item,
+			UpdateExpression: aws.String("SET #migrated = :true"),
+		}
+	}
+
+	m, err := NewScanAndUpdateMigration("parallel_migration", "Parallel migration", table, updateFn,
+		ScanAndUpdateMigrationWithParallelScan(2),
+		ScanAndUpdateMigrationWithWorkers(4),
+		ScanAndUpdateMigrationWithBatchSize(2),
+		ScanAndUpdateMigrationWithPageSize(3),
+		ScanAndUpdateMigrationWithWritesPerSecond(1000),
+	)
+	require.NoError(t, err)
+
+	segmentItems := func(segment string, n int) []map[string]types.AttributeValue {
+		items := make([]map[string]types.AttributeValue, 0, n)
+		for i := 0; i < n; i++ {
+			items = append(items, map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: segment + "_" + string(rune('a'+i))}})
+		}
+
+		return items
+	}
+
+	client := mocks.NewDynamodbClient(t)
+
+	for segment, n := range map[int32]int{0: 3, 1: 2} {
+		client.EXPECT().Scan(mock.Anything, &dynamodb.ScanInput{
+			TableName:      &table,
+			ConsistentRead: aws.Bool(true),
+			Limit:          aws.Int32(3),
+			Segment:        aws.Int32(segment),
+			TotalSegments:  aws.Int32(2),
+		}).Return(&dynamodb.ScanOutput{Items: segmentItems(string(rune('0'+segment)), n)}, nil).Once()
+	}
+
+	var mutex sync.Mutex
+
+	var transactions []*dynamodb.TransactWriteItemsInput
+
+	var updates []*dynamodb.UpdateItemInput
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		transactions = append(transactions, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Times(2)
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		updates = append(updates, params)
+	}).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	require.Len(t, updates, 1)
+
+	for _, transaction := range transactions {
+		require.Len(t, transaction.TransactItems, 2)
+		require.Equal(t, "SET #migrated = :true", *transaction.TransactItems[0].Update.UpdateExpression)
+	}
+
+	require.Equal(t, &types.AttributeValueMemberS{Value: "0_c"}, updates[0].Key["PK"])
+}
+
+func TestNewScanAndUpdateMigration_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		option OptionFn
+	}{
+		{name: "no segments", option: ScanAndUpdateMigrationWithParallelScan(0)},
+		{name: "no workers", option: ScanAndUpdateMigrationWithWorkers(0)},
+		{name: "batch too large", option: ScanAndUpdateMigrationWithBatchSize(101)},
+		{name: "negative rate", option: ScanAndUpdateMigrationWithWritesPerSecond(-1)},
+		{name: "unknown error policy", option: ScanAndUpdateMigrationWithErrorPolicy("retry", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			_, err := NewScanAndUpdateMigration("migration", "description", "table", func(ctx context.Context, item map[string]types.AttributeValue) *dynamodb.UpdateItemInput {
+				return nil
+			}, tt.option)
+
+			// Then
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNewScanAndWriteMigration_WriteOperations(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		pk := item["PK"].(*types.AttributeValueMemberS).Value
+
+		update := inputbuilder.NewUpdateBuilder()
+		update.WithTableName(table)
+		update.WithKey("PK", pk+"_v2")
+		update.AppendSet(updateexpression.Set("migrated", true))
+
+		return []*WriteOperation{
+			UpdateOperation(update),
+			DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: item}),
+		}, nil
+	}
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithBatchSize(2))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"table": table}, m.JobMetadata)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}},
+	}, nil).Once()
+
+	var transactions []*dynamodb.TransactWriteItemsInput
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		transactions = append(transactions, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.Len(t, transactions[0].TransactItems, 2)
+	require.Equal(t, map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1_v2"}}, transactions[0].TransactItems[0].Update.Key)
+	require.Equal(t, &types.Delete{
+		TableName: &table,
+		Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+	}, transactions[0].TransactItems[1].Delete)
+}
+
+func TestNewScanAndWriteMigration_BatchWrite(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+	archive := "archive"
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{
+			PutOperation(&dynamodb.PutItemInput{TableName: &archive, Item: item}),
+			DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: item}),
+		}, nil
+	}
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithBatchSize(4), ScanAndUpdateMigrationWithBatchWrite())
+	require.NoError(t, err)
+
+	user1 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}
+	user2 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{user1, user2}}, nil).Once()
+
+	client.EXPECT().BatchWriteItem(mock.Anything, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{archive: {
+		{PutRequest: &types.PutRequest{Item: user1}},
+		{PutRequest: &types.PutRequest{Item: user2}},
+	}}}).Return(&dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{archive: {
+		{PutRequest: &types.PutRequest{Item: user2}},
+	}}}, nil).Once()
+
+	client.EXPECT().BatchWriteItem(mock.Anything, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{archive: {
+		{PutRequest: &types.PutRequest{Item: user2}},
+	}}}).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	client.EXPECT().BatchWriteItem(mock.Anything, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{table: {
+		{DeleteRequest: &types.DeleteRequest{Key: user1}},
+		{DeleteRequest: &types.DeleteRequest{Key: user2}},
+	}}}).Return(&dynamodb.BatchWriteItemOutput{}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestNewScanAndWriteMigration_BatchWrite_Update(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+	archive := "archive"
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{
+			UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item, UpdateExpression: aws.String("SET #migrated = :true")}),
+			DeleteOperation(&dynamodb.DeleteItemInput{TableName: &archive, Key: item}),
+		}, nil
+	}
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithBatchSize(2), ScanAndUpdateMigrationWithBatchWrite())
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}},
+	}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.ErrorIs(t, err, errNoBatchWriteOperation)
+}
+
+func TestNewScanAndWriteMigration_BatchSplitOnDuplicateKey(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+	archive := "archive"
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{
+			UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item, UpdateExpression: aws.String("SET #a = :a")}),
+			UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item, UpdateExpression: aws.String("SET #b = :b")}),
+			DeleteOperation(&dynamodb.DeleteItemInput{TableName: &archive, Key: item}),
+		}, nil
+	}
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithBatchSize(4))
+	require.NoError(t, err)
+
+	user1 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{user1}}, nil).Once()
+
+	// The second update of the item starts a new batch
+	client.EXPECT().UpdateItem(mock.Anything, &dynamodb.UpdateItemInput{TableName: &table, Key: user1, UpdateExpression: aws.String("SET #a = :a")}).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	client.EXPECT().TransactWriteItems(mock.Anything, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Update: &types.Update{TableName: &table, Key: user1, UpdateExpression: aws.String("SET #b = :b")}},
+		{Delete: &types.Delete{TableName: &archive, Key: user1}},
+	}}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestNewScanAndWriteMigration_BatchSplitOnDuplicatePutKey(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+	archive := "archive"
+
+	// Both items are archived with the same key
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{
+			PutOperation(&dynamodb.PutItemInput{TableName: &archive, Item: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "ARCHIVE"}, "item": item["PK"]}}),
+		}, nil
+	}
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithBatchSize(4))
+	require.NoError(t, err)
+
+	client := mocks.NewSchemaClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{
+		{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+		{"PK": &types.AttributeValueMemberS{Value: "USER#2"}},
+	}}, nil).Once()
+	client.EXPECT().DescribeTable(mock.Anything, &dynamodb.DescribeTableInput{TableName: &archive}).Return(&dynamodb.DescribeTableOutput{Table: &types.TableDescription{
+		KeySchema: []types.KeySchemaElement{{AttributeName: aws.String("PK"), KeyType: types.KeyTypeHash}},
+	}}, nil).Once()
+	client.EXPECT().PutItem(mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Twice()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestScanAndUpdateRun_Write_TransactionConsumesDoubleCapacity(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	run := &scanAndUpdateRun{
+		scanAndUpdate: &scanAndUpdate{options: ScanAndUpdateMigrationOptions{BatchSize: 2}},
+		client:        client,
+		limiter:       newRateLimiter(10),
+	}
+
+	batch := []*WriteOperation{
+		DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}}),
+		DeleteOperation(&dynamodb.DeleteItemInput{TableName: &table, Key: map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}}),
+	}
+
+	start := time.Now()
+
+	// When
+	err := run.write(context.Background(), batch)
+
+	// Then
+	require.NoError(t, err)
+	require.WithinDuration(t, start.Add(400*time.Millisecond), run.limiter.next, 50*time.Millisecond)
+}
+
+func TestNewScanAndWriteMigration_ErrorPolicy(t *testing.T) {
+	itemErr := errors.New("invalid item")
+
+	tests := []struct {
+		name             string
+		policy           ErrorPolicy
+		expectedMetadata map[string]interface{}
+		expectedUpdates  int
+		expectedErr      func(t *testing.T, err error)
+	}{
+		{
+			name:             "abort",
+			policy:           ErrorPolicyAbort,
+			expectedMetadata: map[string]interface{}{"table": "table_to_migrate"},
+			expectedUpdates:  0,
+			expectedErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, itemErr)
+			},
+		},
+		{
+			name:             "skip",
+			policy:           ErrorPolicySkip,
+			expectedMetadata: map[string]interface{}{"table": "table_to_migrate", "errorPolicy": "skip"},
+			expectedUpdates:  2,
+			expectedErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:             "collect",
+			policy:           ErrorPolicyCollect,
+			expectedMetadata: map[string]interface{}{"table": "table_to_migrate", "errorPolicy": "collect"},
+			expectedUpdates:  2,
+			expectedErr: func(t *testing.T, err error) {
+				var itemErrors *ItemErrors
+
+				require.ErrorAs(t, err, &itemErrors)
+				require.Equal(t, int64(1), itemErrors.Count)
+				require.ErrorIs(t, err, itemErr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			table := "table_to_migrate"
+
+			writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+				if item["PK"].(*types.AttributeValueMemberS).Value == "USER#1" {
+					return nil, itemErr
+				}
+
+				return []*WriteOperation{UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item})}, nil
+			}
+
+			var failedItems []map[string]types.AttributeValue
+
+			m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithErrorPolicy(tt.policy, func(item map[string]types.AttributeValue, err error) {
+				failedItems = append(failedItems, item)
+			}))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedMetadata, m.JobMetadata)
+
+			client := mocks.NewDynamodbClient(t)
+			client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+				Items: []map[string]types.AttributeValue{
+					{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+					{"PK": &types.AttributeValueMemberS{Value: "USER#2"}},
+					{"PK": &types.AttributeValueMemberS{Value: "USER#3"}},
+				},
+			}, nil).Once()
+
+			if tt.expectedUpdates > 0 {
+				client.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Times(tt.expectedUpdates)
+			}
+
+			// When
+			err = m.MigratorFn(context.Background(), client)
+
+			// Then
+			tt.expectedErr(t, err)
+
+			if tt.policy != ErrorPolicyAbort {
+				require.Equal(t, []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}}, failedItems)
+			}
+		})
+	}
+}
+
+func TestNewScanAndWriteMigration_ErrorPolicy_FailedWrite(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	user1 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}
+	user2 := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item})}, nil
+	}
+
+	var failedItems []map[string]types.AttributeValue
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithErrorPolicy(ErrorPolicyCollect, func(item map[string]types.AttributeValue, err error) {
+		failedItems = append(failedItems, item)
+	}))
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{user1, user2}}, nil).Once()
+	client.EXPECT().UpdateItem(mock.Anything, &dynamodb.UpdateItemInput{TableName: &table, Key: user1}).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+	client.EXPECT().UpdateItem(mock.Anything, &dynamodb.UpdateItemInput{TableName: &table, Key: user2}).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	var itemErrors *ItemErrors
+
+	require.ErrorAs(t, err, &itemErrors)
+	require.Equal(t, int64(1), itemErrors.Count)
+
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+
+	require.ErrorAs(t, err, &conditionalCheckFailed)
+	require.Equal(t, []map[string]types.AttributeValue{user1}, failedItems)
+}
+
+func TestNewScanAndWriteMigration_ErrorPolicy_FailedBatchAborts(t *testing.T) {
+	// Given
+	table := "table_to_migrate"
+
+	writeFn := func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return []*WriteOperation{UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item})}, nil
+	}
+
+	m, err := NewScanAndWriteMigration("write_migration", "Write migration", table, writeFn, ScanAndUpdateMigrationWithBatchSize(2), ScanAndUpdateMigrationWithErrorPolicy(ErrorPolicySkip, nil))
+	require.NoError(t, err)
+
+	client := mocks.NewDynamodbClient(t)
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{
+		{"PK": &types.AttributeValueMemberS{Value: "USER#1"}},
+		{"PK": &types.AttributeValueMemberS{Value: "USER#2"}},
+	}}, nil).Once()
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Return(nil, &types.TransactionCanceledException{}).Once()
+
+	// When
+	err = m.MigratorFn(context.Background(), client)
+
+	// Then
+	var transactionCanceled *types.TransactionCanceledException
+
+	require.ErrorAs(t, err, &transactionCanceled)
+}
+
+func TestMigrator_Execute_ScanMigrationRecordsItemErrors(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "table_to_migrate"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectMigrationRecord(client, migrationTable, "CHECKPOINT#1", nil)
+
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}},
+	}, nil).Once()
+
+	var transactions []*dynamodb.TransactWriteItemsInput
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		transactions = append(transactions, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migration, err := NewScanAndWriteMigration("scan", "scan migration", table, func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		return nil, errors.New("invalid item")
+	}, ScanAndUpdateMigrationWithErrorPolicy(ErrorPolicySkip, nil))
+	require.NoError(t, err)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, &types.AttributeValueMemberN{Value: "1"}, transactions[0].TransactItems[1].Put.Item["itemErrors"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "skip"}, transactions[0].TransactItems[1].Put.Item["errorPolicy"])
+}
+
+func TestMigrator_Execute_ScanMigrationCollectedErrorsKeepCheckpoint(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "table_to_migrate"
+
+	lastEvaluatedKey := map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+	expectMigrationRecord(client, migrationTable, "CHECKPOINT#1", nil)
+
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#1"}}},
+		LastEvaluatedKey: lastEvaluatedKey,
+	}, nil).Once()
+
+	client.EXPECT().Scan(mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{{"PK": &types.AttributeValueMemberS{Value: "USER#2"}}},
+	}, nil).Once()
+
+	client.EXPECT().UpdateItem(mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+	migration, err := NewScanAndWriteMigration("scan", "scan migration", table, func(ctx context.Context, item map[string]types.AttributeValue) ([]*WriteOperation, error) {
+		if item["PK"].(*types.AttributeValueMemberS).Value == "USER#1" {
+			return nil, errors.New("invalid item")
+		}
+
+		return []*WriteOperation{UpdateItemOperation(&dynamodb.UpdateItemInput{TableName: &table, Key: item})}, nil
+	}, ScanAndUpdateMigrationWithErrorPolicy(ErrorPolicyCollect, nil), ScanAndUpdateMigrationWithCheckpointInterval(time.Nanosecond))
+	require.NoError(t, err)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	var itemErrors *ItemErrors
+
+	require.ErrorAs(t, err, &itemErrors)
+	require.EqualError(t, err, "running migration scan: 1 items failed, first error: invalid item")
+	client.AssertNotCalled(t, "PutItem", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,403 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const defaultSchemaPollInterval = 5 * time.Second
+const defaultSchemaTimeout = 30 * time.Minute
+
+// ErrSchemaClientRequired is returned by a schema migration if the Migrator is not executed with a SchemaClient
+var ErrSchemaClientRequired = errors.New("schema migrations require a SchemaClient")
+
+// ErrSchemaChangeTimeout is returned by a schema migration if the change did not complete within the timeout
+var ErrSchemaChangeTimeout = errors.New("schema change did not complete in time")
+
+// Interface validation check
+var _ SchemaClient = (*dynamodb.Client)(nil)
+
+// SchemaClient extends the DynamodbClient with the operations to change the schema of a table.
+// Schema migrations require the Migrator to be executed with a SchemaClient, e.g. a *dynamodb.Client.
+//
+//go:generate go run github.com/vektra/mockery/v2 --name=SchemaClient --with-expecter
+type SchemaClient interface {
+	DynamodbClient
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.UpdateTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(options *dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+type SchemaMigrationOptions struct {
+	Metadata map[string]interface{}
+
+	// PollInterval time between two checks whether the change completed
+	PollInterval time.Duration
+
+	// Timeout maximum time to wait until the change completed
+	Timeout time.Duration
+}
+
+type SchemaOptionFn func(*SchemaMigrationOptions)
+
+// SchemaMigrationWithMetadata add metadata to a schema migration
+func SchemaMigrationWithMetadata(metadata map[string]interface{}) SchemaOptionFn {
+	return func(options *SchemaMigrationOptions) {
+		options.Metadata = metadata
+	}
+}
+
+// SchemaMigrationWithPollInterval set the time between two checks whether the schema change completed. Default value is 5 seconds.
+func SchemaMigrationWithPollInterval(interval time.Duration) SchemaOptionFn {
+	return func(options *SchemaMigrationOptions) {
+		options.PollInterval = interval
+	}
+}
+
+// SchemaMigrationWithTimeout set the maximum time to wait until the schema change completed. Default value is 30 minutes.
+// If the change does not complete in time, the migration fails with ErrSchemaChangeTimeout. The change itself is not reverted, so the next execution waits for it again.
+func SchemaMigrationWithTimeout(timeout time.Duration) SchemaOptionFn {
+	return func(options *SchemaMigrationOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// NewAddGlobalSecondaryIndexMigration create a migration that adds a global secondary index to a table and waits until the index is ACTIVE, including its backfill.
+// The attribute definitions of the key attributes of the index should be provided. Nothing is changed if an index with the same name already exists.
+func NewAddGlobalSecondaryIndexMigration(name string, description string, table string, index types.CreateGlobalSecondaryIndexAction, attributeDefinitions []types.AttributeDefinition, optFn ...SchemaOptionFn) (*Migration, error) {
+	if index.IndexName == nil || *index.IndexName == "" {
+		return nil, errors.New("index name should be set")
+	}
+
+	indexName := *index.IndexName
+
+	change := &schemaChange{
+		table: table,
+		applyFn: func(ctx context.Context, client SchemaClient) (string, error) {
+			tableDescription, err := describeTable(ctx, client, table)
+			if err != nil {
+				return "", err
+			}
+
+			if findGlobalSecondaryIndex(tableDescription, indexName) != nil {
+				return fmt.Sprintf("global secondary index %s already exists", indexName), nil
+			}
+
+			_, err = client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+				TableName:            &table,
+				AttributeDefinitions: attributeDefinitions,
+				GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+					{Create: &index},
+				},
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("created global secondary index %s", indexName), nil
+		},
+		completedFn: func(ctx context.Context, client SchemaClient) (bool, error) {
+			tableDescription, err := describeTable(ctx, client, table)
+			if err != nil {
+				return false, err
+			}
+
+			return findGlobalSecondaryIndex(tableDescription, indexName) != nil && isTableActive(tableDescription), nil
+		},
+	}
+
+	return change.migration(name, description, map[string]interface{}{"schemaChange": "addGlobalSecondaryIndex", "index": indexName}, optFn)
+}
+
+// NewTimeToLiveMigration create a migration that enables time to live on the given attribute of a table and waits until it is enabled.
+// Nothing is changed if time to live is already enabled on the attribute. The migration fails if time to live is enabled on another attribute.
+func NewTimeToLiveMigration(name string, description string, table string, attributeName string, optFn ...SchemaOptionFn) (*Migration, error) {
+	change := &schemaChange{
+		table: table,
+		applyFn: func(ctx context.Context, client SchemaClient) (string, error) {
+			timeToLive, err := describeTimeToLive(ctx, client, table)
+			if err != nil {
+				return "", err
+			}
+
+			if timeToLive.TimeToLiveStatus == types.TimeToLiveStatusEnabled || timeToLive.TimeToLiveStatus == types.TimeToLiveStatusEnabling {
+				if aws.ToString(timeToLive.AttributeName) != attributeName {
+					return "", fmt.Errorf("time to live of table %s is enabled on attribute %q", table, aws.ToString(timeToLive.AttributeName))
+				}
+
+				return fmt.Sprintf("time to live is already enabled on attribute %s", attributeName), nil
+			}
+
+			_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+				TableName: &table,
+				TimeToLiveSpecification: &types.TimeToLiveSpecification{
+					AttributeName: &attributeName,
+					Enabled:       aws.Bool(true),
+				},
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("enabled time to live on attribute %s", attributeName), nil
+		},
+		completedFn: func(ctx context.Context, client SchemaClient) (bool, error) {
+			timeToLive, err := describeTimeToLive(ctx, client, table)
+			if err != nil {
+				return false, err
+			}
+
+			return timeToLive.TimeToLiveStatus == types.TimeToLiveStatusEnabled, nil
+		},
+	}
+
+	return change.migration(name, description, map[string]interface{}{"schemaChange": "enableTimeToLive", "timeToLiveAttribute": attributeName}, optFn)
+}
+
+// NewBillingModeMigration create a migration that switches the billing mode of a table and waits until the table and its indexes are ACTIVE.
+// The provisioned throughput is required when switching to types.BillingModeProvisioned. Nothing is changed if the table already uses the billing mode.
+// Note that DynamoDB allows to switch the billing mode of a table only once per 24 hours.
+func NewBillingModeMigration(name string, description string, table string, billingMode types.BillingMode, provisionedThroughput *types.ProvisionedThroughput, optFn ...SchemaOptionFn) (*Migration, error) {
+	if billingMode == types.BillingModeProvisioned && provisionedThroughput == nil {
+		return nil, errors.New("provisioned throughput should be set for billing mode PROVISIONED")
+	}
+
+	change := &schemaChange{
+		table: table,
+		applyFn: func(ctx context.Context, client SchemaClient) (string, error) {
+			tableDescription, err := describeTable(ctx, client, table)
+			if err != nil {
+				return "", err
+			}
+
+			currentBillingMode := types.BillingModeProvisioned
+			if tableDescription.BillingModeSummary != nil && tableDescription.BillingModeSummary.BillingMode != "" {
+				currentBillingMode = tableDescription.BillingModeSummary.BillingMode
+			}
+
+			if currentBillingMode == billingMode {
+				return fmt.Sprintf("billing mode is already %s", billingMode), nil
+			}
+
+			_, err = client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+				TableName:             &table,
+				BillingMode:           billingMode,
+				ProvisionedThroughput: provisionedThroughput,
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("switched billing mode from %s to %s", currentBillingMode, billingMode), nil
+		},
+		completedFn: func(ctx context.Context, client SchemaClient) (bool, error) {
+			tableDescription, err := describeTable(ctx, client, table)
+			if err != nil {
+				return false, err
+			}
+
+			return isTableActive(tableDescription), nil
+		},
+	}
+
+	return change.migration(name, description, map[string]interface{}{"schemaChange": "billingMode", "billingMode": string(billingMode)}, optFn)
+}
+
+// NewStreamMigration create a migration that enables the stream of a table with the given view type and waits until the table is ACTIVE.
+// Nothing is changed if the stream is already enabled with the view type. The migration fails if the stream is enabled with another view type, as DynamoDB requires to disable it first.
+func NewStreamMigration(name string, description string, table string, viewType types.StreamViewType, optFn ...SchemaOptionFn) (*Migration, error) {
+	change := &schemaChange{
+		table: table,
+		applyFn: func(ctx context.Context, client SchemaClient) (string, error) {
+			tableDescription, err := describeTable(ctx, client, table)
+			if err != nil {
+				return "", err
+			}
+
+			if current := tableDescription.StreamSpecification; current != nil && aws.ToBool(current.StreamEnabled) {
+				if current.StreamViewType != viewType {
+					return "", fmt.Errorf("stream of table %s is enabled with view type %s", table, current.StreamViewType)
+				}
+
+				return fmt.Sprintf("stream is already enabled with view type %s", viewType), nil
+			}
+
+			_, err = client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+				TableName: &table,
+				StreamSpecification: &types.StreamSpecification{
+					StreamEnabled:  aws.Bool(true),
+					StreamViewType: viewType,
+				},
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("enabled stream with view type %s", viewType), nil
+		},
+		completedFn: func(ctx context.Context, client SchemaClient) (bool, error) {
+			tableDescription, err := describeTable(ctx, client, table)
+			if err != nil {
+				return false, err
+			}
+
+			return isTableActive(tableDescription), nil
+		},
+	}
+
+	return change.migration(name, description, map[string]interface{}{"schemaChange": "enableStream", "streamViewType": string(viewType)}, optFn)
+}
+
+// schemaChange executes a schema migration
+type schemaChange struct {
+	table   string
+	options SchemaMigrationOptions
+
+	// applyFn applies the change if it does not exist yet and returns a description of the outcome
+	applyFn func(ctx context.Context, client SchemaClient) (string, error)
+
+	// completedFn returns true once the change completed
+	completedFn func(ctx context.Context, client SchemaClient) (bool, error)
+}
+
+func (s *schemaChange) migration(name string, description string, changeMetadata map[string]interface{}, optFn []SchemaOptionFn) (*Migration, error) {
+	s.options = SchemaMigrationOptions{
+		PollInterval: defaultSchemaPollInterval,
+		Timeout:      defaultSchemaTimeout,
+	}
+
+	for _, opt := range optFn {
+		opt(&s.options)
+	}
+
+	if s.options.PollInterval <= 0 || s.options.Timeout <= 0 {
+		return nil, fmt.Errorf("poll interval and timeout should be positive, got %s and %s", s.options.PollInterval, s.options.Timeout)
+	}
+
+	metadata := map[string]interface{}{"table": s.table}
+
+	for key, value := range changeMetadata {
+		metadata[key] = value
+	}
+
+	if s.options.Metadata != nil {
+		for key, value := range s.options.Metadata {
+			metadata[key] = value
+		}
+	}
+
+	return &Migration{
+		Name:        name,
+		Description: description,
+		MigratorFn:  s.migrate,
+		JobMetadata: metadata,
+	}, nil
+}
+
+func (s *schemaChange) migrate(ctx context.Context, client DynamodbClient) error {
+	schemaClient, ok := client.(SchemaClient)
+	if !ok {
+		return ErrSchemaClientRequired
+	}
+
+	result, err := s.applyFn(ctx, schemaClient)
+	if err != nil {
+		return err
+	}
+
+	// A dry run only records the change, so it would never complete
+	if !IsDryRun(ctx) {
+		err = s.waitUntilCompleted(ctx, schemaClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	setRunMetadata(ctx, "schemaChangeResult", result)
+
+	return nil
+}
+
+// waitUntilCompleted polls until the change completed or the timeout expired
+func (s *schemaChange) waitUntilCompleted(ctx context.Context, client SchemaClient) error {
+	ctx, cancelFn := context.WithTimeoutCause(ctx, s.options.Timeout, fmt.Errorf("%w: table %s after %s", ErrSchemaChangeTimeout, s.table, s.options.Timeout))
+	defer cancelFn()
+
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		completed, err := s.completedFn(ctx, client)
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		} else if err != nil {
+			return err
+		} else if completed {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+func describeTable(ctx context.Context, client SchemaClient, table string) (*types.TableDescription, error) {
+	result, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &table})
+	if err != nil {
+		return nil, fmt.Errorf("describing table %s: %w", table, err)
+	}
+
+	if result.Table == nil {
+		return nil, fmt.Errorf("describing table %s: no description returned", table)
+	}
+
+	return result.Table, nil
+}
+
+func describeTimeToLive(ctx context.Context, client SchemaClient, table string) (*types.TimeToLiveDescription, error) {
+	result, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: &table})
+	if err != nil {
+		return nil, fmt.Errorf("describing time to live of table %s: %w", table, err)
+	}
+
+	if result.TimeToLiveDescription == nil {
+		return &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}, nil
+	}
+
+	return result.TimeToLiveDescription, nil
+}
+
+func findGlobalSecondaryIndex(table *types.TableDescription, indexName string) *types.GlobalSecondaryIndexDescription {
+	for i := range table.GlobalSecondaryIndexes {
+		if aws.ToString(table.GlobalSecondaryIndexes[i].IndexName) == indexName {
+			return &table.GlobalSecondaryIndexes[i]
+		}
+	}
+
+	return nil
+}
+
+// isTableActive returns true if the table and all its global secondary indexes are ACTIVE
+func isTableActive(table *types.TableDescription) bool {
+	if table.TableStatus != types.TableStatusActive {
+		return false
+	}
+
+	for _, index := range table.GlobalSecondaryIndexes {
+		if index.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,267 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func newTestIndex() types.CreateGlobalSecondaryIndexAction {
+	return types.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String("EmailIndex"),
+		KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("Email"), KeyType: types.KeyTypeHash}},
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+}
+
+func expectDescribeTable(client *mocks.SchemaClient, table string, description *types.TableDescription) *mocks.SchemaClient_DescribeTable_Call {
+	return client.EXPECT().DescribeTable(mock.Anything, &dynamodb.DescribeTableInput{TableName: &table}).Return(&dynamodb.DescribeTableOutput{Table: description}, nil)
+}
+
+func TestNewAddGlobalSecondaryIndexMigration(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+	table := "users"
+
+	index := newTestIndex()
+	attributeDefinitions := []types.AttributeDefinition{{AttributeName: aws.String("Email"), AttributeType: types.ScalarAttributeTypeS}}
+
+	client := mocks.NewSchemaClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	expectDescribeTable(client, table, &types.TableDescription{TableStatus: types.TableStatusActive}).Once()
+	client.EXPECT().UpdateTable(mock.Anything, &dynamodb.UpdateTableInput{
+		TableName:                   &table,
+		AttributeDefinitions:        attributeDefinitions,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{Create: &index}},
+	}).Return(&dynamodb.UpdateTableOutput{}, nil).Once()
+	expectDescribeTable(client, table, &types.TableDescription{
+		TableStatus:            types.TableStatusUpdating,
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{IndexName: aws.String("EmailIndex"), IndexStatus: types.IndexStatusCreating}},
+	}).Once()
+	expectDescribeTable(client, table, &types.TableDescription{
+		TableStatus:            types.TableStatusActive,
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{IndexName: aws.String("EmailIndex"), IndexStatus: types.IndexStatusActive}},
+	}).Once()
+
+	var transactions []*dynamodb.TransactWriteItemsInput
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		transactions = append(transactions, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	migration, err := NewAddGlobalSecondaryIndexMigration("AddEmailIndex", "Add email index", table, index, attributeDefinitions, SchemaMigrationWithPollInterval(time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"table": table, "schemaChange": "addGlobalSecondaryIndex", "index": "EmailIndex"}, migration.JobMetadata)
+
+	migrator := NewMigrator(migrationTable, 0, *migration)
+
+	// When
+	err = migrator.Execute(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "created global secondary index EmailIndex"}, transactions[0].TransactItems[1].Put.Item["schemaChangeResult"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "addGlobalSecondaryIndex"}, transactions[0].TransactItems[1].Put.Item["schemaChange"])
+}
+
+func TestNewAddGlobalSecondaryIndexMigration_IndexExists(t *testing.T) {
+	// Given
+	table := "users"
+
+	client := mocks.NewSchemaClient(t)
+	expectDescribeTable(client, table, &types.TableDescription{
+		TableStatus:            types.TableStatusActive,
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{IndexName: aws.String("EmailIndex"), IndexStatus: types.IndexStatusActive}},
+	}).Twice()
+
+	migration, err := NewAddGlobalSecondaryIndexMigration("AddEmailIndex", "Add email index", table, newTestIndex(), nil)
+	require.NoError(t, err)
+
+	// When
+	err = migration.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestNewTimeToLiveMigration(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       *types.TimeToLiveDescription
+		expectUpdate  bool
+		expectedError string
+	}{
+		{
+			name:         "disabled",
+			current:      &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
+			expectUpdate: true,
+		},
+		{
+			name:    "already enabled",
+			current: &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusEnabled, AttributeName: aws.String("ExpiresAt")},
+		},
+		{
+			name:          "enabled on other attribute",
+			current:       &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusEnabled, AttributeName: aws.String("TTL")},
+			expectedError: `time to live of table sessions is enabled on attribute "TTL"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			table := "sessions"
+
+			client := mocks.NewSchemaClient(t)
+			client.EXPECT().DescribeTimeToLive(mock.Anything, &dynamodb.DescribeTimeToLiveInput{TableName: &table}).Return(&dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: tt.current}, nil).Once()
+
+			if tt.expectUpdate {
+				client.EXPECT().UpdateTimeToLive(mock.Anything, &dynamodb.UpdateTimeToLiveInput{
+					TableName:               &table,
+					TimeToLiveSpecification: &types.TimeToLiveSpecification{AttributeName: aws.String("ExpiresAt"), Enabled: aws.Bool(true)},
+				}).Return(&dynamodb.UpdateTimeToLiveOutput{}, nil).Once()
+			}
+
+			if tt.expectedError == "" {
+				client.EXPECT().DescribeTimeToLive(mock.Anything, mock.Anything).Return(&dynamodb.DescribeTimeToLiveOutput{
+					TimeToLiveDescription: &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusEnabled, AttributeName: aws.String("ExpiresAt")},
+				}, nil).Once()
+			}
+
+			migration, err := NewTimeToLiveMigration("EnableTTL", "Enable TTL", table, "ExpiresAt")
+			require.NoError(t, err)
+
+			// When
+			err = migration.MigratorFn(context.Background(), client)
+
+			// Then
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewBillingModeMigration(t *testing.T) {
+	// Given
+	table := "users"
+
+	client := mocks.NewSchemaClient(t)
+	expectDescribeTable(client, table, &types.TableDescription{TableStatus: types.TableStatusActive}).Once()
+	client.EXPECT().UpdateTable(mock.Anything, &dynamodb.UpdateTableInput{
+		TableName:   &table,
+		BillingMode: types.BillingModePayPerRequest,
+	}).Return(&dynamodb.UpdateTableOutput{}, nil).Once()
+	expectDescribeTable(client, table, &types.TableDescription{TableStatus: types.TableStatusActive}).Once()
+
+	migration, err := NewBillingModeMigration("OnDemand", "Switch to on-demand", table, types.BillingModePayPerRequest, nil)
+	require.NoError(t, err)
+
+	// When
+	err = migration.MigratorFn(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+}
+
+func TestNewBillingModeMigration_ProvisionedWithoutThroughput(t *testing.T) {
+	// When
+	_, err := NewBillingModeMigration("Provisioned", "Switch to provisioned", "users", types.BillingModeProvisioned, nil)
+
+	// Then
+	require.Error(t, err)
+}
+
+func TestNewStreamMigration_OtherViewType(t *testing.T) {
+	// Given
+	table := "users"
+
+	client := mocks.NewSchemaClient(t)
+	expectDescribeTable(client, table, &types.TableDescription{
+		TableStatus:         types.TableStatusActive,
+		StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: types.StreamViewTypeKeysOnly},
+	}).Once()
+
+	migration, err := NewStreamMigration("EnableStream", "Enable stream", table, types.StreamViewTypeNewAndOldImages)
+	require.NoError(t, err)
+
+	// When
+	err = migration.MigratorFn(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "stream of table users is enabled with view type KEYS_ONLY")
+}
+
+func TestSchemaMigration_Timeout(t *testing.T) {
+	// Given
+	table := "users"
+
+	client := mocks.NewSchemaClient(t)
+	expectDescribeTable(client, table, &types.TableDescription{TableStatus: types.TableStatusActive}).Once()
+	client.EXPECT().UpdateTable(mock.Anything, mock.Anything).Return(&dynamodb.UpdateTableOutput{}, nil).Once()
+	client.EXPECT().DescribeTable(mock.Anything, mock.Anything).Return(&dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableStatus: types.TableStatusUpdating}}, nil)
+
+	migration, err := NewStreamMigration("EnableStream", "Enable stream", table, types.StreamViewTypeNewImage,
+		SchemaMigrationWithPollInterval(time.Millisecond), SchemaMigrationWithTimeout(20*time.Millisecond))
+	require.NoError(t, err)
+
+	// When
+	err = migration.MigratorFn(context.Background(), client)
+
+	// Then
+	require.ErrorIs(t, err, ErrSchemaChangeTimeout)
+}
+
+func TestSchemaMigration_SchemaClientRequired(t *testing.T) {
+	// Given
+	client := mocks.NewDynamodbClient(t)
+
+	migration, err := NewStreamMigration("EnableStream", "Enable stream", "users", types.StreamViewTypeNewImage)
+	require.NoError(t, err)
+
+	// When
+	err = migration.MigratorFn(context.Background(), client)
+
+	// Then
+	require.ErrorIs(t, err, ErrSchemaClientRequired)
+}
+
+func TestSchemaMigration_DryRun(t *testing.T) {
+	// Given
+	table := "users"
+
+	client := mocks.NewSchemaClient(t)
+	client.EXPECT().GetItem(mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	expectDescribeTable(client, table, &types.TableDescription{TableStatus: types.TableStatusActive}).Once()
+
+	migration, err := NewStreamMigration("EnableStream", "Enable stream", table, types.StreamViewTypeNewImage)
+	require.NoError(t, err)
+
+	migrator := NewMigrator("migration_table", 0, *migration)
+
+	// When
+	report, err := migrator.DryRun(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, report.Migrations, 1)
+	require.Equal(t, []RecordedWrite{{
+		Operation: OperationUpdateTable,
+		Input: &dynamodb.UpdateTableInput{
+			TableName:           &table,
+			StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: types.StreamViewTypeNewImage},
+		},
+	}}, report.Migrations[0].Writes)
+}
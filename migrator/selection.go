@@ -0,0 +1,99 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Selector decides whether a migration is executed. If it returns false, the migration is skipped and the returned reason is recorded.
+type Selector func(migration *Migration) (bool, string)
+
+// ExecuteOptions configures a single execution of the migrations
+type ExecuteOptions struct {
+	// Selector selects the migrations to execute. If nil, all migrations are selected
+	Selector Selector
+}
+
+type ExecuteOptionFn func(options *ExecuteOptions)
+
+func newExecuteOptions(optFn []ExecuteOptionFn) ExecuteOptions {
+	options := ExecuteOptions{}
+
+	for _, opt := range optFn {
+		opt(&options)
+	}
+
+	return options
+}
+
+// ExecuteWithSelector only executes the migrations that are selected by the selector. Other pending migrations are skipped.
+func ExecuteWithSelector(selector Selector) ExecuteOptionFn {
+	return func(options *ExecuteOptions) {
+		options.Selector = selector
+	}
+}
+
+// ExecuteWithTags only executes untagged migrations and migrations that have at least one of the given tags. Other pending migrations are skipped.
+func ExecuteWithTags(tags ...string) ExecuteOptionFn {
+	return ExecuteWithSelector(SelectTags(tags...))
+}
+
+// SelectTags returns a Selector that selects untagged migrations and migrations that have at least one of the given tags
+func SelectTags(tags ...string) Selector {
+	selectedTags := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		selectedTags[tag] = struct{}{}
+	}
+
+	return func(migration *Migration) (bool, string) {
+		if len(migration.Tags) == 0 {
+			return true, ""
+		}
+
+		for _, tag := range migration.Tags {
+			if _, found := selectedTags[tag]; found {
+				return true, ""
+			}
+		}
+
+		return false, fmt.Sprintf("tags %v do not match the selected tags %v", migration.Tags, tags)
+	}
+}
+
+// isSelected evaluates the selector of the execution and the condition of the migration. The reason is returned if the migration should be skipped.
+func (m *Migrator) isSelected(ctx context.Context, client DynamodbClient, migration *Migration, options ExecuteOptions) (bool, string, error) {
+	if options.Selector != nil {
+		selected, reason := options.Selector(migration)
+		if !selected {
+			return false, reason, nil
+		}
+	}
+
+	if migration.Condition != nil {
+		return migration.Condition(ctx, client)
+	}
+
+	return true, "", nil
+}
+
+// annotateSkippedRun marks the migration as skipped, so it is never executed afterwards and the IDs of the next migrations stay consistent.
+// This also applies to a migration skipped by its Condition, which is not evaluated again.
+func (m *Migrator) annotateSkippedRun(ctx context.Context, client DynamodbClient, metadata *metadataObject, id uint64, migration *Migration, reason string) error {
+	now := time.Now()
+
+	migrationResult := migrationObject{
+		PK:          fmt.Sprintf("MIGRATION#%d", id),
+		ID:          id,
+		Key:         migration.Key,
+		Name:        migration.Name,
+		Description: migration.Description,
+		Checksum:    migration.Checksum,
+		Skipped:     true,
+		SkipReason:  reason,
+		StartTime:   now,
+		EndTime:     now,
+	}
+
+	return m.storeMigrationResult(ctx, client, metadata, migration, &migrationResult, nil, false)
+}
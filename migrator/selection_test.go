@@ -0,0 +1,162 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func taggedMigration(name string, executed *[]string, tags ...string) Migration {
+	return Migration{
+		Name: name,
+		Tags: tags,
+		MigratorFn: func(ctx context.Context, client DynamodbClient) error {
+			*executed = append(*executed, name)
+
+			return nil
+		},
+	}
+}
+
+func TestSelectTags(t *testing.T) {
+	selector := SelectTags("staging", "tenant-a")
+
+	tests := []struct {
+		name           string
+		tags           []string
+		expected       bool
+		expectedReason string
+	}{
+		{name: "untagged", expected: true},
+		{name: "matching tag", tags: []string{"production", "staging"}, expected: true},
+		{name: "no matching tag", tags: []string{"production"}, expected: false, expectedReason: "tags [production] do not match the selected tags [staging tenant-a]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			selected, reason := selector(&Migration{Name: tt.name, Tags: tt.tags})
+
+			// Then
+			require.Equal(t, tt.expected, selected)
+			require.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
+
+func TestMigrator_Execute_SkipsUnselectedMigrations(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+
+	storeItems := make([]*dynamodb.TransactWriteItemsInput, 0, 4)
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Times(4)
+
+	var executed []string
+
+	conditional := taggedMigration("conditional", &executed)
+	conditional.Condition = func(ctx context.Context, client DynamodbClient) (bool, string, error) {
+		return false, "table does not exist", nil
+	}
+
+	migrator := NewMigrator(migrationTable, 0,
+		taggedMigration("create-users", &executed),
+		taggedMigration("seed-data", &executed, "staging"),
+		conditional,
+		taggedMigration("add-email", &executed, "staging", "production"),
+	)
+
+	// When
+	err := migrator.Execute(context.Background(), client, ExecuteWithTags("production"))
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []string{"create-users", "add-email"}, executed)
+	require.Len(t, storeItems, 4)
+
+	skipped := storeItems[1].TransactItems
+	require.Equal(t, "SET #lastJobId = :lastJobId, #skippedMigrations = :skippedMigrations", *skipped[0].Update.UpdateExpression)
+	require.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"2": &types.AttributeValueMemberS{Value: "tags [staging] do not match the selected tags [production]"},
+	}}, skipped[0].Update.ExpressionAttributeValues[":skippedMigrations"])
+	require.Equal(t, &types.AttributeValueMemberBOOL{Value: true}, skipped[1].Put.Item["skipped"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "tags [staging] do not match the selected tags [production]"}, skipped[1].Put.Item["skipReason"])
+
+	require.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"2": &types.AttributeValueMemberS{Value: "tags [staging] do not match the selected tags [production]"},
+		"3": &types.AttributeValueMemberS{Value: "table does not exist"},
+	}}, storeItems[2].TransactItems[0].Update.ExpressionAttributeValues[":skippedMigrations"])
+
+	require.Equal(t, "SET #lastJobId = :lastJobId", *storeItems[3].TransactItems[0].Update.UpdateExpression)
+	require.NotContains(t, storeItems[3].TransactItems[1].Put.Item, "skipped")
+}
+
+func TestMigrator_Execute_ConditionError(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, nil)
+
+	var executed []string
+
+	migration := taggedMigration("conditional", &executed)
+	migration.Condition = func(ctx context.Context, client DynamodbClient) (bool, string, error) {
+		return false, "", errors.New("boom")
+	}
+
+	migrator := NewMigrator(migrationTable, 0, migration)
+
+	// When
+	err := migrator.Execute(context.Background(), client)
+
+	// Then
+	require.EqualError(t, err, "evaluating condition of migration conditional: boom")
+	require.Empty(t, executed)
+}
+
+func TestMigrator_RollbackTo_SkippedMigration(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "2"},
+		"skippedMigrations": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"2": &types.AttributeValueMemberS{Value: "not selected"},
+		}},
+	})
+
+	var storeItems []*dynamodb.TransactWriteItemsInput
+
+	client.EXPECT().TransactWriteItems(mock.Anything, mock.Anything).Run(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) {
+		storeItems = append(storeItems, params)
+	}).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	var executed []string
+
+	migrator := NewMigrator(migrationTable, 0, taggedMigration("create-users", &executed), taggedMigration("seed-data", &executed, "staging"))
+
+	// When
+	err := migrator.RollbackTo(context.Background(), client, 1)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, storeItems, 1)
+
+	update := storeItems[0].TransactItems[0].Update
+	require.Equal(t, "SET #lastJobId = :previousJobId REMOVE #skippedMigrations.#skippedId", *update.UpdateExpression)
+	require.Equal(t, "2", update.ExpressionAttributeNames["#skippedId"])
+}
@@ -0,0 +1,141 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+type MigrationState string
+
+const (
+	// MigrationStateApplied the migration was executed successfully
+	MigrationStateApplied MigrationState = "applied"
+
+	// MigrationStatePending the migration was not executed yet
+	MigrationStatePending MigrationState = "pending"
+
+	// MigrationStateRunning the last attempt of the migration is still running, or was interrupted before it could be recorded
+	MigrationStateRunning MigrationState = "running"
+
+	// MigrationStateFailed the last attempt of the migration failed
+	MigrationStateFailed MigrationState = "failed"
+
+	// MigrationStateSkipped the migration was skipped because it was not selected or its condition was not met
+	MigrationStateSkipped MigrationState = "skipped"
+)
+
+// MigrationStatus is the status of a single configured migration
+type MigrationStatus struct {
+	ID    uint64
+	Key   string
+	Name  string
+	State MigrationState
+
+	// SkipReason is the reason a skipped migration was skipped
+	SkipReason string
+
+	// LastAttempt is the last recorded attempt of a migration that is not applied. Only available if attempt history is enabled
+	LastAttempt *Attempt
+}
+
+// StatusReport lists the status of all configured migrations
+type StatusReport struct {
+	LastJobId  uint64
+	Migrations []MigrationStatus
+}
+
+// Applied returns all applied migrations
+func (r *StatusReport) Applied() []MigrationStatus {
+	return r.filter(MigrationStateApplied)
+}
+
+// Pending returns all migrations that were not executed yet
+func (r *StatusReport) Pending() []MigrationStatus {
+	return r.filter(MigrationStatePending)
+}
+
+// Skipped returns all skipped migrations
+func (r *StatusReport) Skipped() []MigrationStatus {
+	return r.filter(MigrationStateSkipped)
+}
+
+// Failed returns all migrations of which the last attempt failed
+func (r *StatusReport) Failed() []MigrationStatus {
+	return r.filter(MigrationStateFailed)
+}
+
+func (r *StatusReport) filter(state MigrationState) []MigrationStatus {
+	var result []MigrationStatus
+
+	for _, migration := range r.Migrations {
+		if migration.State == state {
+			result = append(result, migration)
+		}
+	}
+
+	return result
+}
+
+// Status lists the applied, skipped, pending, running and failed migrations.
+// Running and failed migrations are only detected if attempt history is enabled while executing the migrations.
+func (m *Migrator) Status(ctx context.Context, client DynamodbClient) (*StatusReport, error) {
+	metadata, err := m.getMetadataObject(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("loading metadata: %w", err)
+	}
+
+	report := &StatusReport{
+		LastJobId:  metadata.LastJobId,
+		Migrations: make([]MigrationStatus, 0, len(m.Migrations)),
+	}
+
+	for i := range m.Migrations {
+		migration := &m.Migrations[i]
+		migrationId := m.MigrationIdOffset + uint64(i) + 1
+
+		status := MigrationStatus{
+			ID:    migrationId,
+			Key:   migration.Key,
+			Name:  migration.Name,
+			State: MigrationStateApplied,
+		}
+
+		if reason, skipped := metadata.SkippedMigrations[strconv.FormatUint(migrationId, 10)]; skipped && migrationId <= metadata.LastJobId {
+			status.State = MigrationStateSkipped
+			status.SkipReason = reason
+		}
+
+		if migrationId > metadata.LastJobId {
+			status.State = MigrationStatePending
+
+			status.LastAttempt, err = m.lastAttempt(ctx, client, migrationId)
+			if err != nil {
+				return nil, fmt.Errorf("loading attempts of migration %d: %w", migrationId, err)
+			}
+
+			if status.LastAttempt != nil {
+				switch status.LastAttempt.Status {
+				case AttemptStatusRunning:
+					status.State = MigrationStateRunning
+				case AttemptStatusFailed:
+					status.State = MigrationStateFailed
+				case AttemptStatusSucceeded:
+				}
+			}
+		}
+
+		report.Migrations = append(report.Migrations, status)
+	}
+
+	return report, nil
+}
+
+func (m *Migrator) lastAttempt(ctx context.Context, client DynamodbClient, id uint64) (*Attempt, error) {
+	attempts, err := m.Attempts(ctx, client, id)
+	if err != nil || len(attempts) == 0 {
+		return nil, err
+	}
+
+	return &attempts[len(attempts)-1], nil
+}
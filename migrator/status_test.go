@@ -0,0 +1,72 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raito-io/go-dynamo-utils/migrator/mocks"
+)
+
+func TestMigrator_Status(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "1"},
+	})
+
+	expectAttempts(t, client, migrationTable, 2, attemptObject{ID: 2, Attempt: 1, Status: AttemptStatusFailed, StartTime: startTime, Error: "boom"})
+	expectAttempts(t, client, migrationTable, 3)
+
+	migrator := NewMigrator(migrationTable, 0,
+		Migration{Key: "create-users", Name: "migration_1"},
+		Migration{Name: "migration_2"},
+		Migration{Name: "migration_3"},
+	)
+
+	// When
+	report, err := migrator.Status(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), report.LastJobId)
+
+	require.Equal(t, []MigrationStatus{{ID: 1, Key: "create-users", Name: "migration_1", State: MigrationStateApplied}}, report.Applied())
+	require.Equal(t, []MigrationStatus{{ID: 3, Name: "migration_3", State: MigrationStatePending}}, report.Pending())
+	require.Equal(t, []MigrationStatus{{
+		ID:          2,
+		Name:        "migration_2",
+		State:       MigrationStateFailed,
+		LastAttempt: &Attempt{ID: 2, Attempt: 1, Status: AttemptStatusFailed, StartTime: startTime, Error: "boom"},
+	}}, report.Failed())
+}
+
+func TestMigrator_Status_Skipped(t *testing.T) {
+	// Given
+	migrationTable := "migration_table"
+
+	client := mocks.NewDynamodbClient(t)
+	expectMetadata(client, migrationTable, map[string]types.AttributeValue{
+		"lastJobId": &types.AttributeValueMemberN{Value: "2"},
+		"skippedMigrations": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"2": &types.AttributeValueMemberS{Value: "not selected"},
+		}},
+	})
+
+	migrator := NewMigrator(migrationTable, 0, Migration{Name: "migration_1"}, Migration{Name: "migration_2", Tags: []string{"staging"}})
+
+	// When
+	report, err := migrator.Status(context.Background(), client)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []MigrationStatus{{ID: 1, Name: "migration_1", State: MigrationStateApplied}}, report.Applied())
+	require.Equal(t, []MigrationStatus{{ID: 2, Name: "migration_2", State: MigrationStateSkipped, SkipReason: "not selected"}}, report.Skipped())
+}
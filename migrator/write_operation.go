@@ -0,0 +1,283 @@
+package migrator
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/raito-io/go-dynamo-utils/inputbuilder"
+)
+
+// WriteOperation is a write that a migration executes for an item. Exactly one of the fields should be set.
+// Use UpdateOperation, UpdateItemOperation, PutOperation or DeleteOperation to create a WriteOperation.
+type WriteOperation struct {
+	Update     *inputbuilder.UpdateBuilder
+	UpdateItem *dynamodb.UpdateItemInput
+	Put        *dynamodb.PutItemInput
+	Delete     *dynamodb.DeleteItemInput
+}
+
+// UpdateOperation creates a WriteOperation that executes the update of the builder
+func UpdateOperation(update *inputbuilder.UpdateBuilder) *WriteOperation {
+	return &WriteOperation{Update: update}
+}
+
+// UpdateItemOperation creates a WriteOperation that executes an update that is already built
+func UpdateItemOperation(update *dynamodb.UpdateItemInput) *WriteOperation {
+	return &WriteOperation{UpdateItem: update}
+}
+
+// PutOperation creates a WriteOperation that puts an item
+func PutOperation(put *dynamodb.PutItemInput) *WriteOperation {
+	return &WriteOperation{Put: put}
+}
+
+// DeleteOperation creates a WriteOperation that deletes an item
+func DeleteOperation(deleteInput *dynamodb.DeleteItemInput) *WriteOperation {
+	return &WriteOperation{Delete: deleteInput}
+}
+
+var errNoWriteOperation = errors.New("write operation has no update, put or delete")
+
+var errNoBatchWriteOperation = errors.New("only puts and deletes without condition expression can be written with BatchWriteItem")
+
+// execute executes the write operation
+func (o *WriteOperation) execute(ctx context.Context, client DynamodbClient) error {
+	switch {
+	case o.Update != nil:
+		input := &dynamodb.UpdateItemInput{}
+
+		err := o.Update.BuildUpdateItemInput(input)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.UpdateItem(ctx, input)
+
+		return err
+	case o.UpdateItem != nil:
+		_, err := client.UpdateItem(ctx, o.UpdateItem)
+
+		return err
+	case o.Put != nil:
+		_, err := client.PutItem(ctx, o.Put)
+
+		return err
+	case o.Delete != nil:
+		_, err := client.DeleteItem(ctx, o.Delete)
+
+		return err
+	default:
+		return errNoWriteOperation
+	}
+}
+
+// transactItem converts the write operation to an item of a TransactWriteItems request
+func (o *WriteOperation) transactItem() (types.TransactWriteItem, error) {
+	switch {
+	case o.Update != nil:
+		update := &types.Update{}
+
+		err := o.Update.BuildUpdateTransactItem(update)
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		return types.TransactWriteItem{Update: update}, nil
+	case o.UpdateItem != nil:
+		return types.TransactWriteItem{Update: &types.Update{
+			TableName:                           o.UpdateItem.TableName,
+			Key:                                 o.UpdateItem.Key,
+			UpdateExpression:                    o.UpdateItem.UpdateExpression,
+			ConditionExpression:                 o.UpdateItem.ConditionExpression,
+			ExpressionAttributeNames:            o.UpdateItem.ExpressionAttributeNames,
+			ExpressionAttributeValues:           o.UpdateItem.ExpressionAttributeValues,
+			ReturnValuesOnConditionCheckFailure: o.UpdateItem.ReturnValuesOnConditionCheckFailure,
+		}}, nil
+	case o.Put != nil:
+		return types.TransactWriteItem{Put: &types.Put{
+			TableName:                           o.Put.TableName,
+			Item:                                o.Put.Item,
+			ConditionExpression:                 o.Put.ConditionExpression,
+			ExpressionAttributeNames:            o.Put.ExpressionAttributeNames,
+			ExpressionAttributeValues:           o.Put.ExpressionAttributeValues,
+			ReturnValuesOnConditionCheckFailure: o.Put.ReturnValuesOnConditionCheckFailure,
+		}}, nil
+	case o.Delete != nil:
+		return types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                           o.Delete.TableName,
+			Key:                                 o.Delete.Key,
+			ConditionExpression:                 o.Delete.ConditionExpression,
+			ExpressionAttributeNames:            o.Delete.ExpressionAttributeNames,
+			ExpressionAttributeValues:           o.Delete.ExpressionAttributeValues,
+			ReturnValuesOnConditionCheckFailure: o.Delete.ReturnValuesOnConditionCheckFailure,
+		}}, nil
+	default:
+		return types.TransactWriteItem{}, errNoWriteOperation
+	}
+}
+
+// writeRequest converts the write operation to a request of a BatchWriteItem request and returns the table it writes to.
+// Only puts and deletes without condition expression are supported by BatchWriteItem.
+func (o *WriteOperation) writeRequest() (string, types.WriteRequest, error) {
+	switch {
+	case o.Put != nil && o.Put.ConditionExpression == nil:
+		return aws.ToString(o.Put.TableName), types.WriteRequest{PutRequest: &types.PutRequest{Item: o.Put.Item}}, nil
+	case o.Delete != nil && o.Delete.ConditionExpression == nil:
+		return aws.ToString(o.Delete.TableName), types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: o.Delete.Key}}, nil
+	default:
+		return "", types.WriteRequest{}, errNoBatchWriteOperation
+	}
+}
+
+// tableKey returns the table the write operation writes to and the key of the written item.
+// The key of a put is the item itself, as the key attributes of the table are not known by the operation.
+func (o *WriteOperation) tableKey() (string, map[string]types.AttributeValue, error) {
+	switch {
+	case o.Update != nil:
+		input := &dynamodb.UpdateItemInput{}
+
+		err := o.Update.BuildUpdateItemInput(input)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return aws.ToString(input.TableName), input.Key, nil
+	case o.UpdateItem != nil:
+		return aws.ToString(o.UpdateItem.TableName), o.UpdateItem.Key, nil
+	case o.Put != nil:
+		return aws.ToString(o.Put.TableName), o.Put.Item, nil
+	case o.Delete != nil:
+		return aws.ToString(o.Delete.TableName), o.Delete.Key, nil
+	default:
+		return "", nil, errNoWriteOperation
+	}
+}
+
+// keySchemas resolves the key attributes of the tables a migration writes to, so a put can be identified by the key of its item.
+// The key schema of a table is described once if the client is a SchemaClient. Otherwise, or if the table cannot be described, a put is identified by its complete item.
+type keySchemas struct {
+	client DynamodbClient
+
+	mutex         sync.Mutex
+	keyAttributes map[string][]string
+}
+
+func newKeySchemas(client DynamodbClient) *keySchemas {
+	return &keySchemas{client: client, keyAttributes: make(map[string][]string)}
+}
+
+// itemKey returns an identifier of the item the write operation writes to, consisting of the table and the key of the item
+func (s *keySchemas) itemKey(ctx context.Context, operation *WriteOperation) (string, error) {
+	table, key, err := operation.tableKey()
+	if err != nil {
+		return "", err
+	}
+
+	if operation.Put != nil {
+		key = projectKey(key, s.tableKeyAttributes(ctx, table))
+	}
+
+	return table + "|" + attributeValuesKey(key), nil
+}
+
+// tableKeyAttributes returns the key attributes of the table, or nil if they are unknown
+func (s *keySchemas) tableKeyAttributes(ctx context.Context, table string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if keyAttributes, found := s.keyAttributes[table]; found {
+		return keyAttributes
+	}
+
+	var keyAttributes []string
+
+	if schemaClient, ok := s.client.(SchemaClient); ok {
+		result, err := schemaClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &table})
+		if err == nil && result.Table != nil {
+			for _, element := range result.Table.KeySchema {
+				keyAttributes = append(keyAttributes, aws.ToString(element.AttributeName))
+			}
+		}
+	}
+
+	s.keyAttributes[table] = keyAttributes
+
+	return keyAttributes
+}
+
+// projectKey returns the key attributes of the item. The complete item is returned if no key attributes are given.
+func projectKey(item map[string]types.AttributeValue, keyAttributes []string) map[string]types.AttributeValue {
+	if len(keyAttributes) == 0 {
+		return item
+	}
+
+	key := make(map[string]types.AttributeValue, len(keyAttributes))
+	for _, keyAttribute := range keyAttributes {
+		key[keyAttribute] = item[keyAttribute]
+	}
+
+	return key
+}
+
+// attributeValuesKey returns a string that is equal for equal attribute values, independent of the order of the attributes
+func attributeValuesKey(values map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var sb strings.Builder
+
+	sb.WriteString("{")
+
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("%q:%s,", name, attributeValueKey(values[name])))
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func attributeValueKey(value types.AttributeValue) string {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return "S" + fmt.Sprintf("%q", v.Value)
+	case *types.AttributeValueMemberN:
+		return "N" + v.Value
+	case *types.AttributeValueMemberB:
+		return "B" + base64.StdEncoding.EncodeToString(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return fmt.Sprintf("BOOL%t", v.Value)
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberSS:
+		return fmt.Sprintf("SS%q", v.Value)
+	case *types.AttributeValueMemberNS:
+		return fmt.Sprintf("NS%q", v.Value)
+	case *types.AttributeValueMemberBS:
+		return fmt.Sprintf("BS%q", v.Value)
+	case *types.AttributeValueMemberM:
+		return "M" + attributeValuesKey(v.Value)
+	case *types.AttributeValueMemberL:
+		elements := make([]string, 0, len(v.Value))
+		for _, element := range v.Value {
+			elements = append(elements, attributeValueKey(element))
+		}
+
+		return "L[" + strings.Join(elements, ",") + "]"
+	default:
+		return "?"
+	}
+}